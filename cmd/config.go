@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+)
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configLintCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "inspect gitleaks configuration",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "show the effective config, after extensions have been applied",
+	Run:   runConfigShow,
+}
+
+var configLintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "check the config for duplicate and overlapping rules and broken allowlists",
+	Run:   runConfigLint,
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) {
+	initConfig()
+	cfg := Config(cmd)
+
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		path = "default config"
+	}
+	fmt.Printf("%-12s %s\n", "Config:", path)
+	fmt.Printf("%-12s %s\n", "Hash:", cfg.Hash())
+	fmt.Printf("%-12s %d\n", "Rules:", len(cfg.Rules))
+	fmt.Printf("%-12s %d paths, %d regexes, %d commits, %d stopwords\n", "Allowlist:",
+		len(cfg.Allowlist.Paths), len(cfg.Allowlist.Regexes), len(cfg.Allowlist.Commits), len(cfg.Allowlist.StopWords))
+	fmt.Println("")
+	for _, rule := range cfg.GetOrderedRules() {
+		fmt.Printf("%-40s %s\n", rule.RuleID, rule.Description)
+	}
+}
+
+func runConfigLint(cmd *cobra.Command, args []string) {
+	initConfig()
+	// lint the config as written, Translate panics on regexes that do not
+	// compile and keeps only the last rule with an ID
+	var vc config.ViperConfig
+	if err := viper.Unmarshal(&vc); err != nil {
+		log.Fatal().Err(err).Msg("Failed to load config")
+	}
+
+	errors := 0
+	issues := vc.Lint()
+	for _, issue := range issues {
+		fmt.Println(issue)
+		if issue.Level == config.LintError {
+			errors++
+		}
+	}
+	fmt.Printf("%d issues, %d errors\n", len(issues), errors)
+	if errors > 0 {
+		os.Exit(1)
+	}
+}
@@ -1,12 +1,16 @@
 package cmd
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 
+	"github.com/zricethezav/gitleaks/v8/detect"
 	"github.com/zricethezav/gitleaks/v8/report"
 	"github.com/zricethezav/gitleaks/v8/sources"
 )
@@ -15,6 +19,22 @@ func init() {
 	rootCmd.AddCommand(detectCmd)
 	detectCmd.Flags().Bool("no-git", false, "treat git repo as a regular directory and scan those files, --log-opts has no effect on the scan when --no-git is set")
 	detectCmd.Flags().Bool("pipe", false, "scan input from stdin, ex: `cat some_file | gitleaks detect --pipe`")
+	detectCmd.Flags().String("diff", "", "scan the lines a unified diff in this file adds, - reads it from stdin, ex: `git format-patch --stdout origin/main | gitleaks detect --diff=-`")
+	detectCmd.Flags().Bool("respect-gitignore", false, "skip files ignored by .gitignore files when --no-git is set")
+	detectCmd.Flags().Bool("include-vendored", false, "scan vendored and generated directories (node_modules, vendor, dist, ...) when --no-git is set")
+	detectCmd.Flags().StringSlice("path", []string{}, "only scan the history of these paths or globs, ex: `--path=infra/ --path='charts/**/values.yaml'`")
+	detectCmd.Flags().Int("max-commit-files", 0, "scan at most this many files in each commit, the rest are skipped and logged")
+	detectCmd.Flags().Bool("check-public", false, "flag findings in commits on a branch of a remote anyone can read as publicly exposed and critical")
+	detectCmd.Flags().Bool("verify-signatures", false, "verify the signature of the commit that introduced each secret and report whether it was signed by a trusted key")
+	detectCmd.Flags().Bool("blame", false, "attribute findings still present at HEAD to the author of the line holding the secret there")
+	detectCmd.Flags().Bool("analyze-history", false, "report the commit that introduced each secret, whether it is still present at HEAD, the commit that removed it and how long it was exposed")
+	detectCmd.Flags().String("from-ref", "", "only scan the commits reachable from --to-ref but not from this ref, ex: the target branch of a merge request")
+	detectCmd.Flags().String("to-ref", "HEAD", "the ref the commits scanned with --from-ref end at")
+	detectCmd.Flags().Bool("final-diff", false, "with --from-ref, scan the final diff of --to-ref since it diverged from --from-ref instead of each commit")
+	detectCmd.Flags().Bool("list-repos", false, "print the repositories that would be scanned with their size and last push, without cloning or scanning them")
+	addRepositoryFlags(detectCmd)
+	addStoreFlag(detectCmd)
+	addIssueFlags(detectCmd)
 }
 
 var detectCmd = &cobra.Command{
@@ -25,8 +45,16 @@ var detectCmd = &cobra.Command{
 
 func runDetect(cmd *cobra.Command, args []string) {
 	initConfig()
+	if listRepos, _ := cmd.Flags().GetBool("list-repos"); listRepos {
+		if err := printRepositories(os.Stdout, listRepositories(cmd, nil)); err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+		return
+	}
 	var (
 		findings []report.Finding
+		scanned  []report.Target
+		failures []report.ScanFailure
 		err      error
 	)
 
@@ -60,10 +88,49 @@ func runDetect(cmd *cobra.Command, args []string) {
 	if err != nil {
 		log.Fatal().Err(err)
 	}
+	if fromRef, _ := cmd.Flags().GetString("from-ref"); fromRef != "" && (noGit || fromPipe) {
+		log.Fatal().Msg("--from-ref can't be used with --no-git or --pipe")
+	}
+	diffPath, _ := cmd.Flags().GetString("diff")
+	if fromRef, _ := cmd.Flags().GetString("from-ref"); diffPath != "" && (noGit || fromPipe || fromRef != "") {
+		log.Fatal().Msg("--diff can't be used with --no-git, --pipe or --from-ref")
+	}
 
 	// start the detector scan
-	if noGit {
-		paths, err := sources.DirectoryTargets(source, detector.Sema, detector.FollowSymlinks)
+	if repos := listRepositories(cmd, nil); len(repos) > 0 {
+		if noGit || fromPipe {
+			log.Fatal().Msg("--repo and --repos-file can't be used with --no-git or --pipe")
+		}
+		if fromRef, _ := cmd.Flags().GetString("from-ref"); fromRef != "" {
+			log.Fatal().Msg("--from-ref can't be used with --repo or --repos-file")
+		}
+		if diffPath != "" {
+			log.Fatal().Msg("--diff can't be used with --repo or --repos-file")
+		}
+		// errors are logged for each repository that couldn't be scanned
+		findings, scanned, failures = detectRepositories(cmd, detector, repos)
+		if len(failures) > 0 {
+			err = fmt.Errorf("%d of %d repositories could not be scanned", len(failures), len(repos))
+		}
+	} else if diffPath != "" {
+		scanned = []report.Target{{Name: diffPath}}
+		findings, err = detectDiff(detector, diffPath)
+		if err != nil {
+			log.Fatal().Err(err).Msgf("could not scan --diff %s", diffPath)
+		}
+	} else if noGit {
+		if abs, err := filepath.Abs(source); err == nil {
+			scanned = []report.Target{{Name: abs}}
+		}
+		respectGitignore, _ := cmd.Flags().GetBool("respect-gitignore")
+		includeVendored, _ := cmd.Flags().GetBool("include-vendored")
+		paths, err := sources.DirectoryTargetsWithOptions(source, detector.Sema, sources.DirectoryOptions{
+			FollowSymlinks:   detector.FollowSymlinks,
+			RespectGitignore: respectGitignore,
+			SkipVendored:     !includeVendored,
+			MaxFileSize:      int64(detector.MaxTargetMegaBytes) * 1_000_000,
+			GitleaksIgnore:   detector.AddNestedGitleaksIgnore,
+		})
 		if err != nil {
 			log.Fatal().Err(err)
 		}
@@ -73,6 +140,7 @@ func runDetect(cmd *cobra.Command, args []string) {
 			log.Error().Err(err).Msg("")
 		}
 	} else if fromPipe {
+		scanned = []report.Target{{Name: "stdin"}}
 		findings, err = detector.DetectReader(os.Stdin, 10)
 		if err != nil {
 			// log fatal to exit, no need to continue since a report
@@ -85,16 +153,111 @@ func runDetect(cmd *cobra.Command, args []string) {
 		if err != nil {
 			log.Fatal().Err(err).Msg("")
 		}
-		gitCmd, err := sources.NewGitLogCmd(source, logOpts)
+		detector.Repository = sources.RepositoryName(source)
+		detector.RemoteURL = sources.RemoteURL(source)
+		paths, err := cmd.Flags().GetStringSlice("path")
+		if err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+		if detector.MaxCommitFiles, err = cmd.Flags().GetInt("max-commit-files"); err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+		gitCmd, commit, err := detectGitCmd(cmd, source, logOpts, paths)
 		if err != nil {
 			log.Fatal().Err(err).Msg("")
 		}
+		scanned = []report.Target{{Name: storeRepositoryName(detector.Repository, detector.RemoteURL), Commit: commit}}
 		findings, err = detector.DetectGit(gitCmd)
 		if err != nil {
 			// don't exit on error, just log it
 			log.Error().Err(err).Msg("")
 		}
+		if skipped := detector.Skipped(); len(skipped) > 0 {
+			log.Info().Msgf("%d files were not scanned, see --log-level=debug for the reasons", len(skipped))
+		}
+		if historyAnalysis(cmd) {
+			findings = detector.AnalyzeHistory(source, findings)
+		}
+		if blame, _ := cmd.Flags().GetBool("blame"); blame {
+			findings = detector.Blame(source, findings)
+		}
+		if verifySignatures, _ := cmd.Flags().GetBool("verify-signatures"); verifySignatures {
+			findings = detect.VerifySignatures(source, findings)
+		}
+		if checkPublic, _ := cmd.Flags().GetBool("check-public"); checkPublic {
+			findings = detect.CheckPublicExposure(source, findings)
+		}
 	}
 
-	findingSummaryAndExit(findings, cmd, cfg, exitCode, start, err)
+	closeDetector(detector)
+	writeSuppressions(cmd, detector)
+	findingSummaryAndExit(findings, cmd, cfg, exitCode, start, err, failures, scanned...)
+}
+
+// detectDiff scans the lines added by the unified diff in the file at path,
+// or stdin if path is -. Findings are attributed to the files and lines the
+// diff adds them to, and to the commit of diffs that name one.
+func detectDiff(detector *detect.Detector, path string) ([]report.Finding, error) {
+	r := io.Reader(os.Stdin)
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	gitCmd, err := sources.NewDiffReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return detector.DetectGit(gitCmd)
+}
+
+// detectGitCmd returns the git command for the history to scan and the
+// commit it ends at. That is all of it, or what --log-opts selects, unless
+// --from-ref is set. Then only the commits between --from-ref and --to-ref
+// are scanned, or with --final-diff only the diff of --to-ref since it
+// diverged from --from-ref, the changes a merge request makes.
+func detectGitCmd(cmd *cobra.Command, source string, logOpts string, paths []string) (*sources.GitCmd, string, error) {
+	fromRef, _ := cmd.Flags().GetString("from-ref")
+	toRef, _ := cmd.Flags().GetString("to-ref")
+	finalDiff, _ := cmd.Flags().GetBool("final-diff")
+	if fromRef == "" {
+		if cmd.Flags().Changed("to-ref") || finalDiff {
+			log.Fatal().Msg("--to-ref and --final-diff require --from-ref")
+		}
+		gitCmd, err := sources.NewGitHistoryCmd(source, logOpts, mergeCommits(cmd), paths...)
+		return gitCmd, sources.HeadCommit(source), err
+	}
+	if logOpts != "" {
+		log.Fatal().Msg("--from-ref can't be used with --log-opts")
+	}
+
+	// resolve the refs first so they can't be taken for git options
+	from, err := sources.ResolveCommit(source, fromRef)
+	if err != nil {
+		return nil, "", err
+	}
+	to, err := sources.ResolveCommit(source, toRef)
+	if err != nil {
+		return nil, "", err
+	}
+	if finalDiff {
+		log.Info().Msgf("scanning the changes of %s since it diverged from %s", toRef, fromRef)
+		gitCmd, err := sources.NewGitDiffRefsCmd(source, from, to, paths...)
+		return gitCmd, to, err
+	}
+	log.Info().Msgf("scanning the commits of %s that aren't in %s", toRef, fromRef)
+	gitCmd, err := sources.NewGitHistoryCmd(source, from+".."+to, mergeCommits(cmd), paths...)
+	return gitCmd, to, err
+}
+
+// historyAnalysis returns true if git findings should be analyzed with
+// AnalyzeHistory. The remediation plan needs it to know which secrets are
+// still present at HEAD and can be patched.
+func historyAnalysis(cmd *cobra.Command) bool {
+	analyzeHistory, _ := cmd.Flags().GetBool("analyze-history")
+	remediationPath, _ := cmd.Flags().GetString("remediation-path")
+	return analyzeHistory || remediationPath != ""
 }
@@ -32,16 +32,26 @@ func main() {
 		rules.AlgoliaApiKey(),
 		rules.AlibabaAccessKey(),
 		rules.AlibabaSecretKey(),
+		rules.ArtifactoryAPIKey(),
+		rules.ArtifactoryReferenceToken(),
 		rules.AsanaClientID(),
 		rules.AsanaClientSecret(),
 		rules.Atlassian(),
 		rules.Authress(),
 		rules.AWS(),
+		rules.AWSSecretAccessKey(),
+		rules.AzureStorageAccountKey(),
+		rules.AzureSASToken(),
+		rules.AzureADClientSecret(),
 		rules.BitBucketClientID(),
 		rules.BitBucketClientSecret(),
 		rules.BittrexAccessKey(),
 		rules.BittrexSecretKey(),
 		rules.Beamer(),
+		rules.BraintreeAccessToken(),
+		rules.BraintreePrivateKey(),
+		rules.CircleCIPersonalToken(),
+		rules.CircleCIAPIToken(),
 		rules.CodecovAccessToken(),
 		rules.CoinbaseAccessToken(),
 		rules.Clojars(),
@@ -57,6 +67,10 @@ func main() {
 		rules.DiscordAPIToken(),
 		rules.DiscordClientID(),
 		rules.DiscordClientSecret(),
+		rules.DockerConfigAuth(),
+		rules.DockerConfigIdentityToken(),
+		rules.DockerLoginPassword(),
+		rules.DockerHubPAT(),
 		rules.Doppler(),
 		rules.DropBoxAPISecret(),
 		rules.DropBoxLongLivedAPIToken(),
@@ -81,9 +95,9 @@ func main() {
 		rules.FrameIO(),
 		rules.FreshbooksAccessToken(),
 		rules.GoCardless(),
-		// TODO figure out what makes sense for GCP
-		// rules.GCPServiceAccount(),
+		rules.GCPServiceAccount(),
 		rules.GCPAPIKey(),
+		rules.GCPOAuthClientSecret(),
 		rules.GitHubPat(),
 		rules.GitHubFineGrainedPat(),
 		rules.GitHubOauth(),
@@ -99,10 +113,12 @@ func main() {
 		rules.Hashicorp(),
 		rules.HashicorpField(),
 		rules.Heroku(),
+		rules.HerokuAPIKeyV2(),
 		rules.HubSpot(),
 		rules.HuggingFaceAccessToken(),
 		rules.HuggingFaceOrganizationApiToken(),
 		rules.Intercom(),
+		rules.JDBCConnectionString(),
 		rules.JFrogAPIKey(),
 		rules.JFrogIdentityToken(),
 		rules.JWT(),
@@ -125,27 +141,37 @@ func main() {
 		rules.MattermostAccessToken(),
 		rules.MessageBirdAPIToken(),
 		rules.MessageBirdClientID(),
+		rules.MongoDBConnectionString(),
+		rules.MySQLConnectionString(),
 		rules.NetlifyAccessToken(),
+		rules.NetrcPassword(),
 		rules.NewRelicUserID(),
 		rules.NewRelicUserKey(),
 		rules.NewRelicBrowserAPIKey(),
 		rules.NPM(),
+		rules.NPMRCAuthToken(),
+		rules.NPMRCAuth(),
+		rules.NPMRCPassword(),
 		rules.NytimesAccessToken(),
 		rules.OktaAccessToken(),
 		rules.OpenAI(),
+		rules.PayPalClientSecret(),
 		rules.PlaidAccessID(),
 		rules.PlaidSecretKey(),
 		rules.PlaidAccessToken(),
 		rules.PlanetScalePassword(),
 		rules.PlanetScaleAPIToken(),
 		rules.PlanetScaleOAuthToken(),
+		rules.PostgresConnectionString(),
 		rules.PostManAPI(),
 		rules.Prefect(),
 		rules.PrivateKey(),
 		rules.PulumiAPIToken(),
 		rules.PyPiUploadToken(),
+		rules.PyPIRCPassword(),
 		rules.RapidAPIAccessToken(),
 		rules.ReadMe(),
+		rules.RedisConnectionString(),
 		rules.RubyGemsAPIToken(),
 		rules.ScalingoAPIToken(),
 		rules.SendbirdAccessID(),
@@ -171,7 +197,9 @@ func main() {
 		rules.SlackWebHookUrl(),
 		rules.Snyk(),
 		rules.StripeAccessToken(),
+		rules.StripeWebhookSecret(),
 		rules.SquareAccessToken(),
+		rules.SquareSecret(),
 		rules.SquareSpaceAccessToken(),
 		rules.SumoLogicAccessID(),
 		rules.SumoLogicAccessToken(),
@@ -179,6 +207,7 @@ func main() {
 		rules.TelegramBotToken(),
 		rules.TravisCIAccessToken(),
 		rules.Twilio(),
+		rules.TwilioAuthToken(),
 		rules.TwitchAPIToken(),
 		rules.TwitterAPIKey(),
 		rules.TwitterAPISecret(),
@@ -186,6 +215,7 @@ func main() {
 		rules.TwitterAccessSecret(),
 		rules.TwitterBearerToken(),
 		rules.Typeform(),
+		rules.URLCredentials(),
 		rules.VaultBatchToken(),
 		rules.VaultServiceToken(),
 		rules.YandexAPIKey(),
@@ -193,6 +223,9 @@ func main() {
 		rules.YandexAccessToken(),
 		rules.ZendeskSecretKey(),
 		rules.GenericCredential(),
+		rules.HighEntropyBase64(),
+		rules.HighEntropyHex(),
+		rules.CreditCard(),
 		rules.InfracostAPIToken(),
 	}
 
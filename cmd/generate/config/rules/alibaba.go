@@ -10,32 +10,40 @@ func AlibabaAccessKey() *config.Rule {
 	r := config.Rule{
 		Description: "Detected an Alibaba Cloud AccessKey ID, posing a risk of unauthorized cloud resource access and potential data compromise.",
 		RuleID:      "alibaba-access-key-id",
-		Regex:       generateUniqueTokenRegex(`(LTAI)(?i)[a-z0-9]{20}`, true),
+		Regex:       generateUniqueTokenRegex(`(LTAI)(?i)[a-z0-9]{12,20}`, true),
 		Keywords:    []string{"LTAI"},
 	}
 
 	// validate
 	tps := []string{
 		"alibabaKey := \"LTAI" + secrets.NewSecret(hex("20")) + "\"",
+		// keys created before 2019 are shorter
+		"ALIBABA_CLOUD_ACCESS_KEY_ID=LTAI" + secrets.NewSecret(alphaNumeric("12")),
 	}
-	return validate(r, tps, nil)
+	fps := []string{
+		"alibabaKey := \"LTAI" + secrets.NewSecret(hex("8")) + "\"",
+	}
+	return validate(r, tps, fps)
 }
 
-// TODO
 func AlibabaSecretKey() *config.Rule {
 	// define rule
 	r := config.Rule{
 		Description: "Discovered a potential Alibaba Cloud Secret Key, potentially allowing unauthorized operations and data access within Alibaba Cloud.",
 		RuleID:      "alibaba-secret-key",
-		Regex: generateSemiGenericRegex([]string{"alibaba"},
+		Regex: generateSemiGenericRegex([]string{"alibaba", "aliyun"},
 			alphaNumeric("30"), true),
 
-		Keywords: []string{"alibaba"},
+		Keywords: []string{"alibaba", "aliyun"},
 	}
 
 	// validate
 	tps := []string{
 		generateSampleSecret("alibaba", secrets.NewSecret(alphaNumeric("30"))),
+		generateSampleSecret("aliyun", secrets.NewSecret(alphaNumeric("30"))),
+	}
+	fps := []string{
+		generateSampleSecret("alibaba", secrets.NewSecret(alphaNumeric("24"))),
 	}
-	return validate(r, tps, nil)
+	return validate(r, tps, fps)
 }
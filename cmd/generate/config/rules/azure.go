@@ -0,0 +1,80 @@
+package rules
+
+import (
+	"regexp"
+
+	"github.com/zricethezav/gitleaks/v8/cmd/generate/secrets"
+	"github.com/zricethezav/gitleaks/v8/config"
+)
+
+// azuriteAccountKey is the well known account key of the Azure Storage
+// emulator, published in its documentation.
+const azuriteAccountKey = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+
+func AzureStorageAccountKey() *config.Rule {
+	// define rule
+	r := config.Rule{
+		Description: "Found an Azure Storage account key, which grants full access to every blob, queue, table and file share of the account.",
+		RuleID:      "azure-storage-account-key",
+		Regex:       regexp.MustCompile(`(?i)AccountKey\s*=\s*([a-z0-9+/]{86}==)`),
+		Validate:    config.ValidateBase64,
+		Keywords:    []string{"AccountKey"},
+		Severity:    "critical",
+		Allowlist: config.Allowlist{
+			Regexes: []*regexp.Regexp{regexp.MustCompile(`^` + azuriteAccountKey + `$`)},
+		},
+	}
+
+	// validate
+	tps := []string{
+		"DefaultEndpointsProtocol=https;AccountName=acmeprod;AccountKey=" + secrets.NewSecret(`[a-zA-Z0-9+/]{86}`) + "==;EndpointSuffix=core.windows.net",
+	}
+	fps := []string{
+		"DefaultEndpointsProtocol=http;AccountName=devstoreaccount1;AccountKey=" + azuriteAccountKey + ";BlobEndpoint=http://127.0.0.1:10000/devstoreaccount1;",
+		`AccountKey=${AZURE_STORAGE_KEY}`,
+	}
+	return validate(r, tps, fps)
+}
+
+func AzureSASToken() *config.Rule {
+	// define rule
+	r := config.Rule{
+		Description: "Found an Azure shared access signature, a URL granting the access it was signed with to storage resources until it expires.",
+		RuleID:      "azure-sas-token",
+		Regex:       regexp.MustCompile(`(?i)[?&]sv=\d{4}-\d{2}-\d{2}[^\s"'<>\x60]*?&sig=([a-z0-9%+/]{43,100})`),
+		Keywords:    []string{"sig="},
+		Severity:    "high",
+	}
+
+	// validate
+	tps := []string{
+		"https://acmeprod.blob.core.windows.net/backups?sv=2022-11-02&ss=b&srt=sco&sp=rl&se=2025-01-01T00:00:00Z&st=2024-01-01T00:00:00Z&spr=https&sig=" + secrets.NewSecret(alphaNumeric("43")) + "%3D",
+		`BlobSasUrl: "https://acmeprod.blob.core.windows.net/logs/app.log?sv=2021-08-06&sr=b&sp=r&sig=` + secrets.NewSecret(alphaNumeric("43")) + `%3D"`,
+	}
+	fps := []string{
+		`https://acmeprod.blob.core.windows.net/backups?sv=2022-11-02&sp=rl&sig=<signature>`,
+		`https://acmeprod.blob.core.windows.net/backups/archive.tar.gz`,
+	}
+	return validate(r, tps, fps)
+}
+
+func AzureADClientSecret() *config.Rule {
+	// define rule
+	r := config.Rule{
+		Description: "Found an Azure AD (Entra ID) application client secret, which authenticates as the application with the permissions granted to it.",
+		RuleID:      "azure-ad-client-secret",
+		Regex:       regexp.MustCompile(`(?:^|[\\'"\x60\s>=:(,)])([a-zA-Z0-9_~.]{3}\dQ~[a-zA-Z0-9_~.-]{31,34})(?:$|[\\'"\x60\s<),])`),
+		Keywords:    []string{"Q~"},
+		Severity:    "high",
+	}
+
+	// validate
+	tps := []string{
+		`client_secret = "` + secrets.NewSecret(`[a-zA-Z0-9_.]{3}[0-9]Q~[a-zA-Z0-9_.-]{34}`) + `"`,
+		`AZURE_CLIENT_SECRET=` + secrets.NewSecret(`[a-zA-Z0-9]{3}[0-9]Q~[a-zA-Z0-9]{31}`),
+	}
+	fps := []string{
+		`AZURE_CLIENT_SECRET=` + secrets.NewSecret(`[a-zA-Z0-9]{3}[0-9]Q~[a-zA-Z0-9]{20}`),
+	}
+	return validate(r, tps, fps)
+}
@@ -0,0 +1,71 @@
+package rules
+
+import (
+	"github.com/zricethezav/gitleaks/v8/cmd/generate/secrets"
+	"github.com/zricethezav/gitleaks/v8/config"
+)
+
+func BraintreeAccessToken() *config.Rule {
+	// define rule
+	r := config.Rule{
+		Description: "Found a PayPal Braintree access token, which processes payments and refunds as the merchant it was issued for.",
+		RuleID:      "braintree-access-token",
+		Regex:       generateUniqueTokenRegex(`access_token\$(?:production|sandbox)\$[0-9a-z]{16}\$[0-9a-f]{32}`, false),
+		Keywords:    []string{"access_token$production", "access_token$sandbox"},
+		Severity:    "critical",
+	}
+
+	// validate
+	tps := []string{
+		generateSampleSecret("braintree", "access_token$production$"+secrets.NewSecret(alphaNumeric("16"))+"$"+secrets.NewSecret(hex("32"))),
+		"BRAINTREE_ACCESS_TOKEN=access_token$sandbox$" + secrets.NewSecret(alphaNumeric("16")) + "$" + secrets.NewSecret(hex("32")),
+	}
+	fps := []string{
+		"BRAINTREE_ACCESS_TOKEN=access_token$production$" + secrets.NewSecret(alphaNumeric("16")) + "$",
+	}
+	return validate(r, tps, fps)
+}
+
+func BraintreePrivateKey() *config.Rule {
+	// define rule
+	r := config.Rule{
+		Description: "Found a PayPal Braintree private key, which together with its public key and merchant id processes payments as the merchant.",
+		RuleID:      "braintree-private-key",
+		Regex:       generateSemiGenericRegex([]string{"braintree"}, hex("32"), true),
+		Keywords:    []string{"braintree"},
+		Severity:    "high",
+	}
+
+	// validate
+	tps := []string{
+		generateSampleSecret("braintree_private", secrets.NewSecret(hex("32"))),
+		`BRAINTREE_PRIVATE_KEY="` + secrets.NewSecret(hex("32")) + `"`,
+	}
+	fps := []string{
+		// merchant ids and public keys are shorter
+		`BRAINTREE_MERCHANT_ID="` + secrets.NewSecret(alphaNumeric("16")) + `"`,
+	}
+	return validate(r, tps, fps)
+}
+
+func PayPalClientSecret() *config.Rule {
+	// define rule
+	r := config.Rule{
+		Description: "Found a PayPal REST API client secret, which with its client id obtains access tokens for the PayPal account of the application.",
+		RuleID:      "paypal-client-secret",
+		Regex:       generateSemiGenericRegex([]string{"paypal"}, `E[a-z0-9_-]{79}`, true),
+		Keywords:    []string{"paypal"},
+		Severity:    "high",
+	}
+
+	// validate
+	tps := []string{
+		generateSampleSecret("paypal_secret", "E"+secrets.NewSecret(alphaNumericExtendedShort("79"))),
+		`PAYPAL_SECRET=E` + secrets.NewSecret(`[a-zA-Z0-9_-]{79}`),
+	}
+	fps := []string{
+		// client ids start with A and are meant for the browser
+		`PAYPAL_CLIENT_ID=A` + secrets.NewSecret(`[a-zA-Z0-9_-]{79}`),
+	}
+	return validate(r, tps, fps)
+}
@@ -0,0 +1,59 @@
+package rules
+
+import (
+	"regexp"
+
+	"github.com/zricethezav/gitleaks/v8/cmd/generate/secrets"
+	"github.com/zricethezav/gitleaks/v8/config"
+)
+
+func CircleCIPersonalToken() *config.Rule {
+	// define rule
+	r := config.Rule{
+		RuleID:      "circleci-personal-token",
+		Description: "Found a CircleCI personal or project API token, which reads the environment variables and triggers the pipelines of the projects it can access.",
+		Regex:       generateUniqueTokenRegex(`CCI(?:PAT|PRJ)_[a-zA-Z0-9]{22}_[a-f0-9]{40}`, false),
+		Keywords:    []string{"CCIPAT_", "CCIPRJ_"},
+		Severity:    "high",
+	}
+
+	// validate
+	tps := []string{
+		generateSampleSecret("circleci", "CCIPAT_"+secrets.NewSecret(`[a-zA-Z0-9]{22}`)+"_"+secrets.NewSecret(hex("40"))),
+		"curl -H \"Circle-Token: CCIPRJ_" + secrets.NewSecret(`[a-zA-Z0-9]{22}`) + "_" + secrets.NewSecret(hex("40")) + "\" https://circleci.com/api/v2/me",
+	}
+	fps := []string{
+		`CIRCLE_TOKEN=CCIPAT_...`,
+		"CCIPAT_" + secrets.NewSecret(`[a-zA-Z0-9]{22}`) + "_" + secrets.NewSecret(`[A-Z]{40}`),
+	}
+	return validate(r, tps, fps)
+}
+
+func CircleCIAPIToken() *config.Rule {
+	// define rule
+	r := config.Rule{
+		RuleID:      "circleci-api-token",
+		Description: "Found a legacy CircleCI API token, which reads the environment variables and triggers the pipelines of the projects it can access.",
+		Regex:       generateSemiGenericRegex([]string{"circle"}, hex("40"), true),
+		Keywords:    []string{"circle"},
+		Allowlist: config.Allowlist{
+			// the commit circleci builds
+			RegexTarget: "match",
+			Regexes: []*regexp.Regexp{
+				regexp.MustCompile(`(?i)circle_sha1`),
+			},
+		},
+	}
+
+	// validate
+	tps := []string{
+		generateSampleSecret("circleci", secrets.NewSecret(hex("40"))),
+		"export CIRCLE_TOKEN=" + secrets.NewSecret(hex("40")),
+		"curl -H \"Circle-Token: " + secrets.NewSecret(hex("40")) + "\" https://circleci.com/api/v2/me",
+	}
+	fps := []string{
+		"CIRCLE_SHA1=" + secrets.NewSecret(hex("40")),
+		"circle_build_url = \"https://app.circleci.com/pipelines/github/acme/api/42\"",
+	}
+	return validate(r, tps, fps)
+}
@@ -0,0 +1,32 @@
+package rules
+
+import (
+	"github.com/zricethezav/gitleaks/v8/config"
+)
+
+func CreditCard() *config.Rule {
+	// define rule
+	r := config.Rule{
+		RuleID:      "credit-card",
+		Description: "Found a payment card number with a valid Luhn checksum, risking exposure of cardholder data.",
+		Regex: generateUniqueTokenRegex(
+			`(?:4[0-9]{12}(?:[0-9]{3})?|(?:5[1-5][0-9]{2}|222[1-9]|22[3-9][0-9]|2[3-6][0-9]{2}|27[01][0-9]|2720)[0-9]{12}|3[47][0-9]{13}|6(?:011|5[0-9]{2})[0-9]{12})`,
+			false),
+		Validate: config.ValidateLuhn,
+		Severity: "high",
+		Tags:     []string{"pci"},
+	}
+
+	// validate
+	tps := []string{
+		`card_number = "4111111111111111"`,
+		`amex: 378282246310005`,
+		`"pan": "5555555555554444"`,
+	}
+	fps := []string{
+		// invalid checksum
+		`card_number = "4111111111111112"`,
+		`order_id = 5555555555554445`,
+	}
+	return validate(r, tps, fps)
+}
@@ -16,7 +16,12 @@ func DigitalOceanPAT() *config.Rule {
 	tps := []string{
 		generateSampleSecret("do", "dop_v1_"+secrets.NewSecret(hex("64"))),
 	}
-	return validate(r, tps, nil)
+	fps := []string{
+		// OAuth tokens have their own rule
+		generateSampleSecret("do", "doo_v1_"+secrets.NewSecret(hex("64"))),
+		generateSampleSecret("do", "dop_v1_"+secrets.NewSecret(hex("32"))),
+	}
+	return validate(r, tps, fps)
 }
 
 func DigitalOceanOAuthToken() *config.Rule {
@@ -31,7 +36,10 @@ func DigitalOceanOAuthToken() *config.Rule {
 	tps := []string{
 		generateSampleSecret("do", "doo_v1_"+secrets.NewSecret(hex("64"))),
 	}
-	return validate(r, tps, nil)
+	fps := []string{
+		generateSampleSecret("do", "doo_v1_"+secrets.NewSecret(hex("32"))),
+	}
+	return validate(r, tps, fps)
 }
 
 func DigitalOceanRefreshToken() *config.Rule {
@@ -46,5 +54,8 @@ func DigitalOceanRefreshToken() *config.Rule {
 	tps := []string{
 		generateSampleSecret("do", "dor_v1_"+secrets.NewSecret(hex("64"))),
 	}
-	return validate(r, tps, nil)
+	fps := []string{
+		generateSampleSecret("do", "dor_v1_"+secrets.NewSecret(hex("32"))),
+	}
+	return validate(r, tps, fps)
 }
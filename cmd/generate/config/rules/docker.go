@@ -0,0 +1,100 @@
+package rules
+
+import (
+	"regexp"
+
+	"github.com/zricethezav/gitleaks/v8/cmd/generate/secrets"
+	"github.com/zricethezav/gitleaks/v8/config"
+)
+
+func DockerConfigAuth() *config.Rule {
+	// define rule
+	r := config.Rule{
+		RuleID:      "docker-config-auth",
+		Description: "Found registry credentials in a Docker config, the base64 encoded user and password docker login stores, which pull and push images as that user.",
+		Regex:       regexp.MustCompile(`"auth"\s*:\s*"([A-Za-z0-9+/]{8,}={0,2})"`),
+		Validate:    config.ValidateBasicAuth,
+		Keywords:    []string{"auth"},
+		Severity:    "high",
+	}
+
+	// validate
+	tps := []string{
+		`{"auths":{"https://index.docker.io/v1/":{"auth":"ZGVwbG95Olp4OHFMMnZSN20="}}}`,                      // gitleaks:allow
+		"{\n  \"auths\": {\n    \"ghcr.io\": {\n      \"auth\": \"YnVpbGQ6OWtMbTJ4VnE3Ug==\"\n    }\n  }\n}", // gitleaks:allow
+	}
+	fps := []string{
+		// not a user and password
+		`{"auths":{"registry.acme.com":{"auth":"c2VjcmV0IHZhbHVl"}}}`,
+		`{"auths":{"registry.acme.com":{}},"credsStore":"desktop"}`,
+	}
+	return validate(r, tps, fps)
+}
+
+func DockerConfigIdentityToken() *config.Rule {
+	// define rule
+	r := config.Rule{
+		RuleID:      "docker-config-identity-token",
+		Description: "Found a registry identity token in a Docker config, which docker login exchanges for access to the registry.",
+		Regex:       regexp.MustCompile(`"identitytoken"\s*:\s*"([^"\s]{16,})"`),
+		Keywords:    []string{"identitytoken"},
+		Severity:    "high",
+	}
+
+	// validate
+	tps := []string{
+		`{"auths":{"acme.azurecr.io":{"identitytoken":"` + secrets.NewSecret(alphaNumericExtendedLong("64")) + `"}}}`,
+	}
+	fps := []string{
+		`{"auths":{"acme.azurecr.io":{"identitytoken":""}}}`,
+	}
+	return validate(r, tps, fps)
+}
+
+func DockerLoginPassword() *config.Rule {
+	// define rule
+	r := config.Rule{
+		RuleID:      "docker-login-password",
+		Description: "Found a password passed to docker login, often in CI configuration, which pulls and pushes images as that registry user.",
+		Regex:       regexp.MustCompile(`docker[ \t]+login\b[^\n]*?[ \t](?:-p|--password)(?:[ \t]+|=)["']?([^\s"']{4,128})`),
+		Keywords:    []string{"docker"},
+		Severity:    "high",
+		Allowlist: config.Allowlist{
+			Regexes: []*regexp.Regexp{placeholderPassword, variableReference},
+		},
+	}
+
+	// validate
+	tps := []string{
+		`docker login -u deploy -p Zx8qL2vR7m9s registry.acme.com`,         // gitleaks:allow
+		`    - docker login --username=ci --password="Tq93kLm2Vx" ghcr.io`, // gitleaks:allow
+	}
+	fps := []string{
+		`docker login -u "$CI_REGISTRY_USER" -p "$CI_REGISTRY_PASSWORD" $CI_REGISTRY`,
+		`echo "$TOKEN" | docker login -u ci --password-stdin ghcr.io`,
+		`docker login -u me -p ${{ secrets.DOCKER_PASSWORD }}`,
+	}
+	return validate(r, tps, fps)
+}
+
+func DockerHubPAT() *config.Rule {
+	// define rule
+	r := config.Rule{
+		RuleID:      "dockerhub-pat",
+		Description: "Found a Docker Hub personal access token, which pulls and pushes the images of its owner's repositories on Docker Hub.",
+		Regex:       generateUniqueTokenRegex(`dckr_pat_[a-zA-Z0-9_-]{27}`, false),
+		Keywords:    []string{"dckr_pat_"},
+		Severity:    "high",
+	}
+
+	// validate
+	tps := []string{
+		generateSampleSecret("dockerhub", "dckr_pat_"+secrets.NewSecret(`[a-zA-Z0-9_-]{27}`)),
+		"echo dckr_pat_" + secrets.NewSecret(`[a-zA-Z0-9_-]{27}`) + " | docker login -u acme --password-stdin",
+	}
+	fps := []string{
+		`DOCKERHUB_TOKEN=dckr_pat_xxxxxxxx`,
+		`docker login -u acme -p dckr_pat_...`,
+	}
+	return validate(r, tps, fps)
+}
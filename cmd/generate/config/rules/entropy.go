@@ -0,0 +1,58 @@
+package rules
+
+import (
+	"regexp"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+)
+
+func HighEntropyBase64() *config.Rule {
+	// define rule
+	r := config.Rule{
+		RuleID:      "generic-high-entropy-base64",
+		Description: "Discovered a long, high entropy base64 string, which may be an encoded key or credential.",
+		Regex:       generateUniqueTokenRegex(`[A-Za-z0-9+/]{40,}={0,2}`, false),
+		Entropy:     4.5,
+		Severity:    "low",
+	}
+
+	// validate
+	tps := []string{
+		`blob: "uR3dfCVl8nm6aLqKN4pOJ5tZ0V+9w/ZxQeHbGkYsT1Fjx2oA"`,
+	}
+	fps := []string{
+		// low entropy
+		`padding = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"`,
+		// identifiers don't contain digits
+		`ThisIsAVeryLongJavaClassNameForTestingPurposesOnly`,
+	}
+	return validate(r, tps, fps)
+}
+
+func HighEntropyHex() *config.Rule {
+	// define rule
+	r := config.Rule{
+		RuleID:      "generic-high-entropy-hex",
+		Description: "Discovered a 256 or 512 bit hex encoded value with high entropy, which may be a private or signing key.",
+		Regex:       generateUniqueTokenRegex(`(?:[a-f0-9]{128}|[a-f0-9]{64}|[A-F0-9]{128}|[A-F0-9]{64})`, false),
+		Entropy:     3.7,
+		Severity:    "low",
+		Allowlist: config.Allowlist{
+			// content digests are hex encoded hashes, not secrets
+			RegexTarget: "line",
+			Regexes: []*regexp.Regexp{
+				regexp.MustCompile(`(?i)(?:sha-?256|sha-?512|digest|integrity|checksum|hash)`),
+			},
+		},
+	}
+
+	// validate
+	tps := []string{
+		`ENCRYPTION_MASTER = "69a124df608a79005ef6bd8194b4b5fcc1359ab834fe7272b957930438b3a25e"`,
+	}
+	fps := []string{
+		`image: nginx@sha256:69a124df608a79005ef6bd8194b4b5fcc1359ab834fe7272b957930438b3a25e`,
+		`checksum = "69a124df608a79005ef6bd8194b4b5fcc1359ab834fe7272b957930438b3a25e"`,
+	}
+	return validate(r, tps, fps)
+}
@@ -28,15 +28,21 @@ func GitHubFineGrainedPat() *config.Rule {
 	r := config.Rule{
 		Description: "Found a GitHub Fine-Grained Personal Access Token, risking unauthorized repository access and code manipulation.",
 		RuleID:      "github-fine-grained-pat",
-		Regex:       regexp.MustCompile(`github_pat_[0-9a-zA-Z_]{82}`),
+		Regex:       regexp.MustCompile(`github_pat_[0-9a-zA-Z]{22}_[0-9a-zA-Z]{59}`),
 		Keywords:    []string{"github_pat_"},
 	}
 
 	// validate
 	tps := []string{
-		generateSampleSecret("github", "github_pat_"+secrets.NewSecret(alphaNumeric("82"))),
+		generateSampleSecret("github", "github_pat_"+secrets.NewSecret(`[0-9a-zA-Z]{22}`)+"_"+secrets.NewSecret(`[0-9a-zA-Z]{59}`)),
+		"git clone https://x-access-token:github_pat_" + secrets.NewSecret(`[0-9a-zA-Z]{22}`) + "_" + secrets.NewSecret(`[0-9a-zA-Z]{59}`) + "@github.com/acme/api.git",
 	}
-	return validate(r, tps, nil)
+	fps := []string{
+		// truncated in documentation
+		`GITHUB_TOKEN=github_pat_11ABCDEFG0123456789_...`,
+		"github_pat_" + secrets.NewSecret(alphaNumeric("82")),
+	}
+	return validate(r, tps, fps)
 }
 
 func GitHubOauth() *config.Rule {
@@ -19,8 +19,13 @@ func GitlabPat() *config.Rule {
 	// validate
 	tps := []string{
 		generateSampleSecret("gitlab", "glpat-"+secrets.NewSecret(alphaNumeric("20"))),
+		"git clone https://oauth2:glpat-" + secrets.NewSecret(alphaNumericExtendedShort("20")) + "@gitlab.com/acme/api.git",
 	}
-	return validate(r, tps, nil)
+	fps := []string{
+		`GITLAB_TOKEN=glpat-<your token>`,
+		`export GITLAB_TOKEN=glpat-xxxx`,
+	}
+	return validate(r, tps, fps)
 }
 
 func GitlabPipelineTriggerToken() *config.Rule {
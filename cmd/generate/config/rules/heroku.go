@@ -1,6 +1,7 @@
 package rules
 
 import (
+	"github.com/zricethezav/gitleaks/v8/cmd/generate/secrets"
 	"github.com/zricethezav/gitleaks/v8/config"
 )
 
@@ -18,5 +19,29 @@ func Heroku() *config.Rule {
 	tps := []string{
 		`const HEROKU_KEY = "12345678-ABCD-ABCD-ABCD-1234567890AB"`, // gitleaks:allow
 	}
-	return validate(r, tps, nil)
+	fps := []string{
+		`const HEROKU_APP = "acme-api-staging"`,
+	}
+	return validate(r, tps, fps)
+}
+
+func HerokuAPIKeyV2() *config.Rule {
+	// define rule
+	r := config.Rule{
+		Description: "Detected a Heroku API key in the prefixed format issued since 2024, potentially compromising cloud application deployments and operational security.",
+		RuleID:      "heroku-api-key-v2",
+		Regex:       generateUniqueTokenRegex(`HRKU-AA[0-9a-zA-Z_-]{58}`, false),
+		Keywords:    []string{"HRKU-AA"},
+		Severity:    "high",
+	}
+
+	// validate
+	tps := []string{
+		generateSampleSecret("heroku", "HRKU-AA"+secrets.NewSecret(`[0-9a-zA-Z_-]{58}`)),
+		`HEROKU_API_KEY=HRKU-AA` + secrets.NewSecret(`[0-9a-zA-Z]{58}`),
+	}
+	fps := []string{
+		generateSampleSecret("heroku", "HRKU-AA"+secrets.NewSecret(`[0-9a-zA-Z]{30}`)),
+	}
+	return validate(r, tps, fps)
 }
@@ -30,7 +30,11 @@ func JFrogAPIKey() *config.Rule {
 	tps := []string{
 		fmt.Sprintf("--set imagePullSecretJfrog.password=%s", secrets.NewSecret(alphaNumeric("73"))),
 	}
-	return validate(r, tps, nil)
+	fps := []string{
+		`artifactory_url = "https://acme.jfrog.io/artifactory/api/npm/npm-virtual/"`,
+		fmt.Sprintf("jfrog_password = %s", secrets.NewSecret(alphaNumeric("72"))),
+	}
+	return validate(r, tps, fps)
 }
 
 func JFrogIdentityToken() *config.Rule {
@@ -62,3 +66,47 @@ func JFrogIdentityToken() *config.Rule {
 	}
 	return validate(r, tps, nil)
 }
+
+func ArtifactoryAPIKey() *config.Rule {
+	// define rule
+	r := config.Rule{
+		RuleID:      "artifactory-api-key",
+		Description: "Found an Artifactory API key, which reads and deploys the artifacts its user can access wherever it's used.",
+		Regex:       generateUniqueTokenRegex(`AKCp[A-Za-z0-9]{69}`, false),
+		Keywords:    []string{"AKCp"},
+		Severity:    "high",
+	}
+
+	// validate
+	tps := []string{
+		generateSampleSecret("artifactory", "AKCp"+secrets.NewSecret(`[A-Za-z0-9]{69}`)),
+		"curl -H \"X-JFrog-Art-Api: AKCp" + secrets.NewSecret(`[A-Za-z0-9]{69}`) + "\" https://acme.jfrog.io/artifactory/api/repositories",
+	}
+	fps := []string{
+		`X-JFrog-Art-Api: AKCp...`,
+		"AKCp" + secrets.NewSecret(`[A-Za-z0-9]{40}`),
+	}
+	return validate(r, tps, fps)
+}
+
+func ArtifactoryReferenceToken() *config.Rule {
+	// define rule
+	r := config.Rule{
+		RuleID:      "artifactory-reference-token",
+		Description: "Found an Artifactory reference token, the short form of an access token, which reads and deploys artifacts as its user.",
+		// base64 of reftkn:
+		Regex:    generateUniqueTokenRegex(`cmVmdGtu[A-Za-z0-9]{56}`, false),
+		Keywords: []string{"cmVmdGtu"},
+		Severity: "high",
+	}
+
+	// validate
+	tps := []string{
+		generateSampleSecret("artifactory", "cmVmdGtuOjAxOj"+secrets.NewSecret(`[A-Za-z0-9]{50}`)),
+		"jf config add acme --url=https://acme.jfrog.io --access-token=cmVmdGtuOjAxOj" + secrets.NewSecret(`[A-Za-z0-9]{50}`),
+	}
+	fps := []string{
+		`ARTIFACTORY_TOKEN=cmVmdGtuOjAxOj...`,
+	}
+	return validate(r, tps, fps)
+}
@@ -26,6 +26,8 @@ func MailChimp() *config.Rule {
 	fps := []string{
 		// False Negative
 		`MailchimpSDK.initialize(token: 3012a5754bbd716926f99c028f7ea428-us18)`, // gitleaks:allow
+		// list ids aren't keys
+		`mailchimp_list_id = "` + secrets.NewSecret(hex("10")) + `"`,
 	}
 	return validate(r, tps, fps)
 }
@@ -21,7 +21,10 @@ func MailGunPrivateAPIToken() *config.Rule {
 	tps := []string{
 		generateSampleSecret("mailgun", "key-"+secrets.NewSecret(hex("32"))),
 	}
-	return validate(r, tps, nil)
+	fps := []string{
+		generateSampleSecret("mailgun", "key-"+secrets.NewSecret(hex("16"))),
+	}
+	return validate(r, tps, fps)
 }
 
 func MailGunPubAPIToken() *config.Rule {
@@ -40,7 +43,10 @@ func MailGunPubAPIToken() *config.Rule {
 	tps := []string{
 		generateSampleSecret("mailgun", "pubkey-"+secrets.NewSecret(hex("32"))),
 	}
-	return validate(r, tps, nil)
+	fps := []string{
+		generateSampleSecret("mailgun", "pubkey-"+secrets.NewSecret(hex("16"))),
+	}
+	return validate(r, tps, fps)
 }
 
 func MailGunSigningKey() *config.Rule {
@@ -59,5 +65,8 @@ func MailGunSigningKey() *config.Rule {
 	tps := []string{
 		generateSampleSecret("mailgun", secrets.NewSecret(hex("32"))+"-00001111-22223333"),
 	}
-	return validate(r, tps, nil)
+	fps := []string{
+		generateSampleSecret("mailgun", secrets.NewSecret(hex("32"))),
+	}
+	return validate(r, tps, fps)
 }
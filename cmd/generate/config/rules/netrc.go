@@ -0,0 +1,34 @@
+package rules
+
+import (
+	"regexp"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+)
+
+func NetrcPassword() *config.Rule {
+	// define rule
+	r := config.Rule{
+		RuleID:      "netrc-password",
+		Description: "Found a password in a .netrc file, which curl, git and ftp clients log in to the machines it lists with.",
+		Regex:       regexp.MustCompile(`(?:^|\s)password[ \t]+([^\s]{3,128})`),
+		Path:        regexp.MustCompile(`(?:^|/)[._]?netrc$`),
+		Keywords:    []string{"password"},
+		Severity:    "high",
+		Allowlist: config.Allowlist{
+			Regexes: []*regexp.Regexp{placeholderPassword, variableReference},
+		},
+	}
+
+	// validate
+	tps := []string{
+		"machine api.heroku.com\n  login deploy@acme.com\n  password 5f1c9a2e-7b4d-4e8f-9a3c-2d6b8e0f1a7c\n", // gitleaks:allow
+		`machine ftp.acme.com login ftpuser password Tq93kLm2Vx`,                                             // gitleaks:allow
+	}
+	fps := []string{
+		`machine example.com login me password changeme`,
+		`machine example.com login me password ${NETRC_PASSWORD}`,
+		`default login anonymous`,
+	}
+	return validateInFile(r, "home/ci/.netrc", tps, fps)
+}
@@ -1,6 +1,8 @@
 package rules
 
 import (
+	"regexp"
+
 	"github.com/zricethezav/gitleaks/v8/cmd/generate/secrets"
 	"github.com/zricethezav/gitleaks/v8/config"
 )
@@ -9,7 +11,7 @@ func NPM() *config.Rule {
 	// define rule
 	r := config.Rule{
 		RuleID:      "npm-access-token",
-		Description: "Uncovered an npm access token, potentially compromising package management and code repository access.",
+		Description: "Uncovered an npm access token, including the automation and granular tokens CI publishes with, potentially compromising package management and code repository access.",
 		Regex:       generateUniqueTokenRegex(`npm_[a-z0-9]{36}`, true),
 
 		Keywords: []string{
@@ -20,6 +22,86 @@ func NPM() *config.Rule {
 	// validate
 	tps := []string{
 		generateSampleSecret("npmAccessToken", "npm_"+secrets.NewSecret(alphaNumeric("36"))),
+		"NPM_TOKEN=npm_" + secrets.NewSecret(`[A-Za-z0-9]{36}`),
+		"      - run: npm publish\n        env:\n          NODE_AUTH_TOKEN: npm_" + secrets.NewSecret(`[A-Za-z0-9]{36}`),
+	}
+	fps := []string{
+		// variables npm sets for scripts
+		`npm_config_registry=https://registry.npmjs.org/`,
+		`echo $npm_package_version`,
+		`NPM_TOKEN=npm_XXXXXXXX`,
+	}
+	return validate(r, tps, fps)
+}
+
+func NPMRCAuthToken() *config.Rule {
+	// define rule
+	r := config.Rule{
+		RuleID:      "npmrc-auth-token",
+		Description: "Found a registry auth token in an npm or yarn configuration, which installs and publishes private packages as its owner.",
+		Regex:       regexp.MustCompile(`(?i)(?::_authToken[ \t]*=|npmAuthToken[ \t]*:)[ \t]*["']?([^\s"']{8,512})`),
+		Keywords:    []string{"_authToken", "npmAuthToken"},
+		Severity:    "high",
+		Allowlist: config.Allowlist{
+			Regexes: []*regexp.Regexp{placeholderPassword, variableReference},
+		},
+	}
+
+	// validate
+	tps := []string{
+		"//registry.npmjs.org/:_authToken=npm_" + secrets.NewSecret(alphaNumeric("36")),
+		`echo "//npm.pkg.github.com/:_authToken=` + secrets.NewSecret(alphaNumeric("40")) + `" > ~/.npmrc`,
+		`  npmAuthToken: "` + secrets.NewSecret(alphaNumericExtended("32")) + `"`,
+	}
+	fps := []string{
+		`//registry.npmjs.org/:_authToken=${NPM_TOKEN}`,
+		`  npmAuthToken: "${NPM_AUTH_TOKEN}"`,
+		`always-auth=true`,
+	}
+	return validate(r, tps, fps)
+}
+
+func NPMRCAuth() *config.Rule {
+	// define rule
+	r := config.Rule{
+		RuleID:      "npmrc-auth",
+		Description: "Found base64 encoded registry credentials in an npm configuration, the user and password it logs in to the registry with.",
+		Regex:       regexp.MustCompile(`(?i)(?:^|[\s:])_auth[ \t]*=[ \t]*["']?([a-z0-9+/]{8,}={0,2})`),
+		Validate:    config.ValidateBasicAuth,
+		Keywords:    []string{"_auth"},
+		Severity:    "high",
+	}
+
+	// validate
+	tps := []string{
+		`_auth = ZGVwbG95Olp4OHFMMnZSN20=`,                      // gitleaks:allow
+		`//registry.acme.com/:_auth="YnVpbGQ6OWtMbTJ4VnE3Ug=="`, // gitleaks:allow
+	}
+	fps := []string{
+		// not a user and password
+		`_auth=c2VjcmV0IHZhbHVl`,
+		`_auth=${NPM_AUTH}`,
+	}
+	return validate(r, tps, fps)
+}
+
+func NPMRCPassword() *config.Rule {
+	// define rule
+	r := config.Rule{
+		RuleID:      "npmrc-password",
+		Description: "Found a base64 encoded registry password in an npm configuration, exposing the account it logs in to the registry with.",
+		Regex:       regexp.MustCompile(`(?i)(?:^|[\s:])_password[ \t]*=[ \t]*["']?([a-z0-9+/]{8,}={0,2})`),
+		Validate:    config.ValidateBase64,
+		Keywords:    []string{"_password"},
+		Severity:    "high",
+	}
+
+	// validate
+	tps := []string{
+		`//registry.acme.com/:_password=WnhxOExtMnZSN3E=`, // gitleaks:allow
+	}
+	fps := []string{
+		`//registry.acme.com/:_password=${NPM_PASSWORD}`,
 	}
-	return validate(r, tps, nil)
+	return validate(r, tps, fps)
 }
@@ -24,7 +24,11 @@ func PlaidAccessID() *config.Rule {
 	tps := []string{
 		generateSampleSecret("plaid", secrets.NewSecret(alphaNumeric("24"))),
 	}
-	return validate(r, tps, nil)
+	fps := []string{
+		// low entropy
+		generateSampleSecret("plaid", "aaaaaaaaaaaaaaaaaaaaaaaa"),
+	}
+	return validate(r, tps, fps)
 }
 
 func PlaidSecretKey() *config.Rule {
@@ -44,7 +48,11 @@ func PlaidSecretKey() *config.Rule {
 	tps := []string{
 		generateSampleSecret("plaid", secrets.NewSecret(alphaNumeric("30"))),
 	}
-	return validate(r, tps, nil)
+	fps := []string{
+		// low entropy
+		generateSampleSecret("plaid", "000000000000000000000000000000"),
+	}
+	return validate(r, tps, fps)
 }
 
 func PlaidAccessToken() *config.Rule {
@@ -64,5 +72,9 @@ func PlaidAccessToken() *config.Rule {
 	tps := []string{
 		generateSampleSecret("plaid", secrets.NewSecret(fmt.Sprintf("access-(?:sandbox|development|production)-%s", hex8_4_4_4_12()))),
 	}
-	return validate(r, tps, nil)
+	fps := []string{
+		// public tokens are exchanged for access tokens, they expire after 30 minutes
+		generateSampleSecret("plaid", secrets.NewSecret(fmt.Sprintf("public-sandbox-%s", hex8_4_4_4_12()))),
+	}
+	return validate(r, tps, fps)
 }
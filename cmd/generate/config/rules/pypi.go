@@ -12,15 +12,57 @@ func PyPiUploadToken() *config.Rule {
 	r := config.Rule{
 		Description: "Discovered a PyPI upload token, potentially compromising Python package distribution and repository integrity.",
 		RuleID:      "pypi-upload-token",
+		// tokens are macaroons for pypi.org or test.pypi.org, base64
+		// encoded, their location makes up the prefix
 		Regex: regexp.MustCompile(
-			`pypi-AgEIcHlwaS5vcmc[A-Za-z0-9\-_]{50,1000}`),
+			`pypi-(?:AgEIcHlwaS5vcmc|AgENdGVzdC5weXBpLm9yZw)[A-Za-z0-9\-_]{50,1000}`),
 		Keywords: []string{
 			"pypi-AgEIcHlwaS5vcmc",
+			"pypi-AgENdGVzdC5weXBpLm9yZw",
 		},
 	}
 
 	// validate
-	tps := []string{"pypiToken := \"pypi-AgEIcHlwaS5vcmc" + secrets.NewSecret(hex("32")) +
-		secrets.NewSecret(hex("32")) + "\""}
-	return validate(r, tps, nil)
+	tps := []string{
+		"pypiToken := \"pypi-AgEIcHlwaS5vcmc" + secrets.NewSecret(hex("32")) + secrets.NewSecret(hex("32")) + "\"",
+		"TWINE_PASSWORD=pypi-AgENdGVzdC5weXBpLm9yZw" + secrets.NewSecret(alphaNumericExtendedShort("64")),
+		"UV_PUBLISH_TOKEN: pypi-AgEIcHlwaS5vcmc" + secrets.NewSecret(alphaNumericExtendedShort("120")),
+	}
+	fps := []string{
+		// truncated in documentation
+		`password = pypi-AgEIcHlwaS5vcmc...`,
+		"pypi-AgEIcHlwaS5vcmc" + secrets.NewSecret(hex("16")),
+	}
+	return validate(r, tps, fps)
+}
+
+func PyPIRCPassword() *config.Rule {
+	// define rule
+	r := config.Rule{
+		RuleID:      "pypirc-password",
+		Description: "Found a password in a .pypirc file, which uploads Python packages to the indexes it lists as that account.",
+		Regex:       regexp.MustCompile(`(?im)^[ \t]*password[ \t]*[=:][ \t]*([^\s]{3,256})`),
+		Path:        regexp.MustCompile(`(?:^|/)\.?pypirc$`),
+		Keywords:    []string{"password"},
+		Severity:    "high",
+		Allowlist: config.Allowlist{
+			Regexes: []*regexp.Regexp{
+				placeholderPassword,
+				variableReference,
+				// reported by pypi-upload-token
+				regexp.MustCompile(`^pypi-AgEIcHlwaS5vcmc`),
+			},
+		},
+	}
+
+	// validate
+	tps := []string{
+		"[distutils]\nindex-servers = internal\n\n[internal]\nrepository = https://pypi.acme.com/\nusername = deploy\npassword = Tq93kLm2Vx9s\n", // gitleaks:allow
+	}
+	fps := []string{
+		"[pypi]\nusername = __token__\npassword = pypi-AgEIcHlwaS5vcmc" + secrets.NewSecret(hex("64")),
+		"[internal]\nusername = deploy\npassword = ${PYPI_PASSWORD}",
+		"[internal]\nusername = deploy\npassword: <your password>",
+	}
+	return validateInFile(r, ".pypirc", tps, fps)
 }
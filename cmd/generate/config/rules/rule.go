@@ -30,6 +30,23 @@ const (
 	secretSuffix       = `)(?:['|\"|\n|\r|\s|\x60|;]|$)`
 )
 
+// placeholderPassword matches the passwords of examples and
+// variableReference references to variables holding the password, for rules
+// matching any password in configuration files.
+var (
+	placeholderPassword = regexp.MustCompile(`(?i)^(?:password|passwd|pass|pwd|secret|changeme|change_me|test|example|dummy|redacted|foo|bar|none|null|x+|[*]+)$`)
+	variableReference   = regexp.MustCompile(`^[$][{(]|^[$][A-Z_]+$|^[%<{[]`)
+)
+
+// urlPlaceholderPassword and urlVariableReference are their counterparts for
+// passwords in URLs and JDBC properties, matched against the whole match, and
+// exampleHost matches URLs to local and example hosts.
+var (
+	urlPlaceholderPassword = regexp.MustCompile(`(?i)[:=](?:password|passwd|pass|pwd|secret|changeme|change_me|test|example|dummy|redacted|foo|bar|none|null|x+|[*]+)(?:@|$)`)
+	urlVariableReference   = regexp.MustCompile(`[:=](?:[$][{(][^@&;]*[)}]|[$][A-Z_]+|[%<{[][^@&;]*)(?:@|$)`)
+	exampleHost            = regexp.MustCompile(`(?i)(?:@|^jdbc:[^/]*//)(?:localhost|127[.]0[.]0[.]1|0[.]0[.]0[.]0|(?:[a-z0-9-]+[.])*example[.](?:com|org|net)|(?:[a-z0-9-]+[.])*(?:example|test|invalid|localhost))(?:[:/?;,]|$)`)
+)
+
 func generateSemiGenericRegex(identifiers []string, secretRegex string, isCaseInsensitive bool) *regexp.Regexp {
 	var sb strings.Builder
 	// The identifiers should always be case-insensitive.
@@ -97,6 +114,35 @@ func validate(r config.Rule, truePositives []string, falsePositives []string) *c
 	return &r
 }
 
+// validateInFile validates a rule limited to certain files with Path like
+// validate, scanning the true and false positives as the content of file.
+func validateInFile(r config.Rule, file string, truePositives []string, falsePositives []string) *config.Rule {
+	if !r.Path.MatchString(file) {
+		log.Fatal().Msgf("Failed to validate. For rule ID [%s], file [%s] does not match path [%s]", r.RuleID, file, r.Path)
+	}
+	var keywords []string
+	for _, k := range r.Keywords {
+		keywords = append(keywords, strings.ToLower(k))
+	}
+	r.Keywords = keywords
+
+	d := detect.NewDetector(config.Config{
+		Rules:    map[string]config.Rule{r.RuleID: r},
+		Keywords: keywords,
+	})
+	for _, tp := range truePositives {
+		if len(d.Detect(detect.Fragment{Raw: tp, FilePath: file})) != 1 {
+			log.Fatal().Msgf("Failed to validate. For rule ID [%s], true positive [%s] was not detected in [%s] by regexp [%s]", r.RuleID, tp, file, r.Regex)
+		}
+	}
+	for _, fp := range falsePositives {
+		if len(d.Detect(detect.Fragment{Raw: fp, FilePath: file})) != 0 {
+			log.Fatal().Msgf("Failed to validate. For rule ID [%s], false positive [%s] was detected in [%s] by regexp [%s]", r.RuleID, fp, file, r.Regex)
+		}
+	}
+	return &r
+}
+
 func numeric(size string) string {
 	return fmt.Sprintf(`[0-9]{%s}`, size)
 }
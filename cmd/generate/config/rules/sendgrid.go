@@ -20,6 +20,10 @@ func SendGridAPIToken() *config.Rule {
 	// validate
 	tps := []string{
 		generateSampleSecret("sengridAPIToken", "SG."+secrets.NewSecret(alphaNumericExtended("66"))),
+		"SENDGRID_API_KEY=SG." + secrets.NewSecret(`[a-zA-Z0-9_-]{22}`) + "." + secrets.NewSecret(`[a-zA-Z0-9_-]{43}`),
 	}
-	return validate(r, tps, nil)
+	fps := []string{
+		generateSampleSecret("sengridAPIToken", "SG."+secrets.NewSecret(alphaNumericExtended("30"))),
+	}
+	return validate(r, tps, fps)
 }
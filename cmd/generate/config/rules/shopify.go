@@ -1,8 +1,6 @@
 package rules
 
 import (
-	"regexp"
-
 	"github.com/zricethezav/gitleaks/v8/cmd/generate/secrets"
 	"github.com/zricethezav/gitleaks/v8/config"
 )
@@ -12,13 +10,14 @@ func ShopifySharedSecret() *config.Rule {
 	r := config.Rule{
 		Description: "Found a Shopify shared secret, posing a risk to application authentication and e-commerce platform security.",
 		RuleID:      "shopify-shared-secret",
-		Regex:       regexp.MustCompile(`shpss_[a-fA-F0-9]{32}`),
+		Regex:       generateUniqueTokenRegex(`shpss_[a-fA-F0-9]{32}`, false),
 		Keywords:    []string{"shpss_"},
 	}
 
 	// validate
 	tps := []string{"shopifySecret := \"shpss_" + secrets.NewSecret(hex("32")) + "\""}
-	return validate(r, tps, nil)
+	fps := []string{"shopifySecret := \"shpss_" + secrets.NewSecret(hex("32")) + secrets.NewSecret(hex("8")) + "\""}
+	return validate(r, tps, fps)
 }
 
 func ShopifyAccessToken() *config.Rule {
@@ -26,13 +25,14 @@ func ShopifyAccessToken() *config.Rule {
 	r := config.Rule{
 		Description: "Uncovered a Shopify access token, which could lead to unauthorized e-commerce platform access and data breaches.",
 		RuleID:      "shopify-access-token",
-		Regex:       regexp.MustCompile(`shpat_[a-fA-F0-9]{32}`),
+		Regex:       generateUniqueTokenRegex(`shpat_[a-fA-F0-9]{32}`, false),
 		Keywords:    []string{"shpat_"},
 	}
 
 	// validate
 	tps := []string{"shopifyToken := \"shpat_" + secrets.NewSecret(hex("32")) + "\""}
-	return validate(r, tps, nil)
+	fps := []string{"shopifyToken := \"shpat_" + secrets.NewSecret(hex("32")) + secrets.NewSecret(hex("8")) + "\""}
+	return validate(r, tps, fps)
 }
 
 func ShopifyCustomAccessToken() *config.Rule {
@@ -40,13 +40,14 @@ func ShopifyCustomAccessToken() *config.Rule {
 	r := config.Rule{
 		Description: "Detected a Shopify custom access token, potentially compromising custom app integrations and e-commerce data security.",
 		RuleID:      "shopify-custom-access-token",
-		Regex:       regexp.MustCompile(`shpca_[a-fA-F0-9]{32}`),
+		Regex:       generateUniqueTokenRegex(`shpca_[a-fA-F0-9]{32}`, false),
 		Keywords:    []string{"shpca_"},
 	}
 
 	// validate
 	tps := []string{"shopifyToken := \"shpca_" + secrets.NewSecret(hex("32")) + "\""}
-	return validate(r, tps, nil)
+	fps := []string{"shopifyToken := \"shpca_" + secrets.NewSecret(hex("32")) + secrets.NewSecret(hex("8")) + "\""}
+	return validate(r, tps, fps)
 }
 
 func ShopifyPrivateAppAccessToken() *config.Rule {
@@ -54,11 +55,12 @@ func ShopifyPrivateAppAccessToken() *config.Rule {
 	r := config.Rule{
 		Description: "Identified a Shopify private app access token, risking unauthorized access to private app data and store operations.",
 		RuleID:      "shopify-private-app-access-token",
-		Regex:       regexp.MustCompile(`shppa_[a-fA-F0-9]{32}`),
+		Regex:       generateUniqueTokenRegex(`shppa_[a-fA-F0-9]{32}`, false),
 		Keywords:    []string{"shppa_"},
 	}
 
 	// validate
 	tps := []string{"shopifyToken := \"shppa_" + secrets.NewSecret(hex("32")) + "\""}
-	return validate(r, tps, nil)
+	fps := []string{"shopifyToken := \"shppa_" + secrets.NewSecret(hex("32")) + secrets.NewSecret(hex("8")) + "\""}
+	return validate(r, tps, fps)
 }
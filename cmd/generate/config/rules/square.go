@@ -20,22 +20,30 @@ func SquareAccessToken() *config.Rule {
 		"ARG token=sq0atp-812erere3wewew45678901",                                    // gitleaks:allow
 		"ARG token=EAAAlsBxkkVgvmr7FasTFbM6VUGZ31EJ4jZKTJZySgElBDJ_wyafHuBFquFexY7E", // gitleaks:allow",
 	}
-	return validate(r, tps, nil)
+	fps := []string{
+		// application ids are public
+		"ARG app=sq0idp-" + secrets.NewSecret(`[0-9A-Za-z\-_]{22}`),
+		"ARG token=sq0atp-" + secrets.NewSecret(`[0-9A-Za-z]{10}`),
+	}
+	return validate(r, tps, fps)
 }
 
 func SquareSecret() *config.Rule {
 	// define rule
 	r := config.Rule{
 		RuleID:      "square-secret",
-		Description: "Square Secret",
-		Regex:       generateUniqueTokenRegex(`sq0csp-[0-9A-Za-z\\-_]{43}`, true),
+		Description: "Detected a Square OAuth application secret, which exchanges authorization codes for access to the Square accounts of the application's sellers.",
+		Regex:       generateUniqueTokenRegex(`sq0csp-[0-9A-Za-z\-_]{43}`, true),
 		Keywords:    []string{"sq0csp-"},
 	}
 
 	// validate
 	tps := []string{
-		generateSampleSecret("square", secrets.NewSecret(`sq0csp-[0-9A-Za-z\\-_]{43}`)),
+		generateSampleSecret("square", secrets.NewSecret(`sq0csp-[0-9A-Za-z\-_]{43}`)),
 		`value: "sq0csp-0p9h7g6f4s3s3s3-4a3ardgwa6ADRDJDDKUFYDYDYDY"`, // gitleaks:allow
 	}
-	return validate(r, tps, nil)
+	fps := []string{
+		generateSampleSecret("square", "sq0csp-"+secrets.NewSecret(alphaNumeric("20"))),
+	}
+	return validate(r, tps, fps)
 }
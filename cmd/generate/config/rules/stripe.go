@@ -10,15 +10,49 @@ func StripeAccessToken() *config.Rule {
 	r := config.Rule{
 		Description: "Found a Stripe Access Token, posing a risk to payment processing services and sensitive financial data.",
 		RuleID:      "stripe-access-token",
-		Regex:       generateUniqueTokenRegex(`(sk)_(test|live)_[0-9a-z]{10,32}`, true),
+		Regex:       generateUniqueTokenRegex(`(sk|rk)_(test|live|prod)_[0-9a-z]{10,99}`, true),
 		Keywords: []string{
 			"sk_test",
 			"sk_live",
+			"sk_prod",
+			"rk_test",
+			"rk_live",
+			"rk_prod",
 		},
 	}
 
 	// validate
-	tps := []string{"stripeToken := \"sk_test_" + secrets.NewSecret(alphaNumeric("30")) + "\""}
-	fps := []string{"nonMatchingToken := \"task_test_" + secrets.NewSecret(alphaNumeric("30")) + "\""}
+	tps := []string{
+		"stripeToken := \"sk_test_" + secrets.NewSecret(alphaNumeric("30")) + "\"",
+		// restricted keys
+		"STRIPE_KEY=rk_live_" + secrets.NewSecret(alphaNumeric("99")),
+		generateSampleSecret("stripe", "sk_live_"+secrets.NewSecret(alphaNumeric("24"))),
+	}
+	fps := []string{
+		"nonMatchingToken := \"task_test_" + secrets.NewSecret(alphaNumeric("30")) + "\"",
+		// publishable keys are meant to be public
+		"stripePublishableKey := \"pk_live_" + secrets.NewSecret(alphaNumeric("24")) + "\"",
+	}
+	return validate(r, tps, fps)
+}
+
+func StripeWebhookSecret() *config.Rule {
+	// define rule
+	r := config.Rule{
+		Description: "Found a Stripe webhook signing secret, which lets anyone forge webhook events the application trusts as coming from Stripe.",
+		RuleID:      "stripe-webhook-secret",
+		Regex:       generateUniqueTokenRegex(`whsec_[a-zA-Z0-9]{32,64}`, false),
+		Keywords:    []string{"whsec_"},
+		Severity:    "high",
+	}
+
+	// validate
+	tps := []string{
+		generateSampleSecret("stripe_webhook", "whsec_"+secrets.NewSecret(`[a-zA-Z0-9]{32}`)),
+		"STRIPE_WEBHOOK_SECRET=whsec_" + secrets.NewSecret(`[a-zA-Z0-9]{64}`),
+	}
+	fps := []string{
+		"STRIPE_WEBHOOK_SECRET=whsec_" + secrets.NewSecret(`[a-zA-Z0-9]{16}`),
+	}
 	return validate(r, tps, fps)
 }
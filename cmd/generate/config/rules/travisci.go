@@ -20,6 +20,13 @@ func TravisCIAccessToken() *config.Rule {
 	// validate
 	tps := []string{
 		generateSampleSecret("travis", secrets.NewSecret(alphaNumeric("22"))),
+		"travis login --pro --github-token " + secrets.NewSecret(hex("40")) + "\nTRAVIS_TOKEN=" + secrets.NewSecret(`[A-Za-z0-9]{22}`),
 	}
-	return validate(r, tps, nil)
+	fps := []string{
+		`TRAVIS_BRANCH=feature/login-refactor`,
+		`travis_dist: "focal"`,
+		// a variable reference
+		`TRAVIS_TOKEN=$TRAVIS_API_TOKEN_SECRET`,
+	}
+	return validate(r, tps, fps)
 }
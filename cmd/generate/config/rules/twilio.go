@@ -20,5 +20,30 @@ func Twilio() *config.Rule {
 	tps := []string{
 		"twilioAPIKey := \"SK" + secrets.NewSecret(hex("32")) + "\"",
 	}
-	return validate(r, tps, nil)
+	fps := []string{
+		// account sids identify the account, they aren't secret
+		"twilioAccountSID := \"AC" + secrets.NewSecret(hex("32")) + "\"",
+	}
+	return validate(r, tps, fps)
+}
+
+func TwilioAuthToken() *config.Rule {
+	// define rule
+	r := config.Rule{
+		Description: "Found a Twilio auth token, the primary credential of a Twilio account, which sends messages and places calls billed to it.",
+		RuleID:      "twilio-auth-token",
+		Regex:       generateSemiGenericRegex([]string{"twilio"}, hex("32"), true),
+		Keywords:    []string{"twilio"},
+		Severity:    "high",
+	}
+
+	// validate
+	tps := []string{
+		generateSampleSecret("twilio_auth", secrets.NewSecret(hex("32"))),
+		`TWILIO_AUTH_TOKEN=` + secrets.NewSecret(hex("32")),
+	}
+	fps := []string{
+		`TWILIO_ACCOUNT_SID=AC` + secrets.NewSecret(hex("32")),
+	}
+	return validate(r, tps, fps)
 }
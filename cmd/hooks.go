@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// hookHeader marks hooks written by gitleaks so they can be safely
+// overwritten by later installs.
+const hookHeader = "# installed by gitleaks install-hooks"
+
+const preCommitHook = `#!/bin/sh
+` + hookHeader + `
+# disable with: git config hooks.gitleaks false
+if [ "$(git config --bool hooks.gitleaks)" = "false" ]; then
+	exit 0
+fi
+
+gitleaks protect --staged --verbose --redact
+status=$?
+if [ $status -ne 0 ]; then
+	echo "Warning: gitleaks has detected sensitive information in your changes."
+	echo "To disable the gitleaks hooks run: git config hooks.gitleaks false"
+fi
+exit $status
+`
+
+const prePushHook = `#!/bin/sh
+` + hookHeader + `
+# disable with: git config hooks.gitleaks false
+if [ "$(git config --bool hooks.gitleaks)" = "false" ]; then
+	exit 0
+fi
+
+zero=$(git hash-object --stdin </dev/null | tr '[0-9a-f]' '0')
+while read local_ref local_sha remote_ref remote_sha; do
+	if [ "$local_sha" = "$zero" ]; then
+		# deleting a remote ref, nothing to scan
+		continue
+	fi
+	if [ "$remote_sha" = "$zero" ]; then
+		# new ref, scan every commit not already on a remote
+		range="$local_sha --not --remotes"
+	else
+		range="$remote_sha..$local_sha"
+	fi
+	gitleaks detect --verbose --redact --log-opts="$range" || exit $?
+done
+exit 0
+`
+
+func init() {
+	rootCmd.AddCommand(installHooksCmd)
+	installHooksCmd.Flags().Bool("pre-commit-config", false, "print a .pre-commit-config.yaml stanza instead of installing hooks")
+	installHooksCmd.Flags().Bool("force", false, "overwrite existing hooks that were not installed by gitleaks")
+	installHooksCmd.Flags().Bool("no-pre-push", false, "only install the pre-commit hook")
+	installHooksCmd.Flags().Bool("uninstall", false, "remove the hooks installed by gitleaks")
+}
+
+var installHooksCmd = &cobra.Command{
+	Use:   "install-hooks",
+	Short: "install git hooks that run gitleaks before commits and pushes",
+	Run:   runInstallHooks,
+}
+
+func runInstallHooks(cmd *cobra.Command, args []string) {
+	if preCommitConfig, _ := cmd.Flags().GetBool("pre-commit-config"); preCommitConfig {
+		rev := Version
+		if !strings.HasPrefix(rev, "v") {
+			rev = "<latest gitleaks release>"
+		}
+		fmt.Printf("repos:\n  - repo: https://github.com/gitleaks/gitleaks\n    rev: %s\n    hooks:\n      - id: gitleaks\n", rev)
+		return
+	}
+
+	source, err := cmd.Flags().GetString("source")
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	force, _ := cmd.Flags().GetBool("force")
+	noPrePush, _ := cmd.Flags().GetBool("no-pre-push")
+	uninstall, _ := cmd.Flags().GetBool("uninstall")
+
+	hooksDir, err := gitHooksDir(source)
+	if err != nil {
+		log.Fatal().Err(err).Msgf("%s is not a git repository", source)
+	}
+	if uninstall {
+		if err := uninstallHooks(hooksDir); err != nil {
+			log.Fatal().Err(err).Msg("could not uninstall hooks")
+		}
+		return
+	}
+
+	hooks := map[string]string{"pre-commit": preCommitHook}
+	if !noPrePush {
+		hooks["pre-push"] = prePushHook
+	}
+	if err := installHooks(hooksDir, hooks, force); err != nil {
+		log.Fatal().Err(err).Msg("could not install hooks")
+	}
+}
+
+// gitHooksDir asks git where the hooks of the repository at source live,
+// this respects core.hooksPath and worktrees.
+func gitHooksDir(source string) (string, error) {
+	out, err := exec.Command("git", "-C", filepath.Clean(source), "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", err
+	}
+	hooksDir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(source, hooksDir)
+	}
+	return hooksDir, nil
+}
+
+// installHooks writes hooks, keyed by name, into hooksDir. Every hook is
+// checked before any is written, so a hook that wasn't installed by gitleaks
+// leaves hooksDir unchanged unless force is set.
+func installHooks(hooksDir string, hooks map[string]string, force bool) error {
+	names := make([]string, 0, len(hooks))
+	for name := range hooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if !force {
+		for _, name := range names {
+			path := filepath.Join(hooksDir, name)
+			if existing, err := os.ReadFile(path); err == nil && !strings.Contains(string(existing), hookHeader) {
+				return fmt.Errorf("%s already exists, use --force to overwrite it", path)
+			}
+		}
+	}
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		return fmt.Errorf("could not create hooks directory: %w", err)
+	}
+	for _, name := range names {
+		path := filepath.Join(hooksDir, name)
+		if err := os.WriteFile(path, []byte(hooks[name]), 0o755); err != nil {
+			return fmt.Errorf("could not write %s: %w", path, err)
+		}
+		// WriteFile keeps the mode of a hook that already existed
+		if err := os.Chmod(path, 0o755); err != nil {
+			return err
+		}
+		log.Info().Msgf("installed %s hook: %s", name, path)
+	}
+	return nil
+}
+
+// uninstallHooks removes the hooks in hooksDir that were installed by
+// gitleaks and leaves the others.
+func uninstallHooks(hooksDir string) error {
+	for _, name := range []string{"pre-commit", "pre-push"} {
+		path := filepath.Join(hooksDir, name)
+		existing, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(string(existing), hookHeader) {
+			log.Info().Msgf("leaving %s, it was not installed by gitleaks", path)
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		log.Info().Msgf("uninstalled %s hook: %s", name, path)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zricethezav/gitleaks/v8/sources/gittest"
+)
+
+func testHooks() map[string]string {
+	return map[string]string{"pre-commit": preCommitHook, "pre-push": prePushHook}
+}
+
+func TestGitHooksDir(t *testing.T) {
+	repo := gittest.New(t)
+	hooksDir, err := gitHooksDir(repo.Dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(repo.Dir, ".git", "hooks"), hooksDir)
+
+	hooksPath := t.TempDir()
+	repo.Git("config", "core.hooksPath", hooksPath)
+	hooksDir, err = gitHooksDir(repo.Dir)
+	require.NoError(t, err)
+	assert.Equal(t, hooksPath, hooksDir)
+
+	_, err = gitHooksDir(t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestInstallHooks(t *testing.T) {
+	hooksDir := filepath.Join(t.TempDir(), "hooks")
+	require.NoError(t, installHooks(hooksDir, testHooks(), false))
+	for name, content := range testHooks() {
+		installed, err := os.ReadFile(filepath.Join(hooksDir, name))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(installed))
+	}
+
+	// hooks installed by gitleaks are overwritten and made executable again
+	require.NoError(t, os.Chmod(filepath.Join(hooksDir, "pre-push"), 0o644))
+	require.NoError(t, installHooks(hooksDir, testHooks(), false))
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(hooksDir, "pre-push"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+	}
+}
+
+func TestInstallHooksExisting(t *testing.T) {
+	hooksDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(hooksDir, "pre-push"), []byte("#!/bin/sh\nmake test\n"), 0o644))
+
+	// no hook is written when one would overwrite a hook of someone else
+	err := installHooks(hooksDir, testHooks(), false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pre-push already exists, use --force to overwrite it")
+	assert.NoFileExists(t, filepath.Join(hooksDir, "pre-commit"))
+
+	require.NoError(t, installHooks(hooksDir, testHooks(), true))
+	installed, err := os.ReadFile(filepath.Join(hooksDir, "pre-push"))
+	require.NoError(t, err)
+	assert.Equal(t, prePushHook, string(installed))
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(hooksDir, "pre-push"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+	}
+}
+
+func TestUninstallHooks(t *testing.T) {
+	hooksDir := t.TempDir()
+	require.NoError(t, installHooks(hooksDir, map[string]string{"pre-commit": preCommitHook}, false))
+	require.NoError(t, os.WriteFile(filepath.Join(hooksDir, "pre-push"), []byte("#!/bin/sh\nmake test\n"), 0o755))
+
+	require.NoError(t, uninstallHooks(hooksDir))
+	assert.NoFileExists(t, filepath.Join(hooksDir, "pre-commit"))
+	assert.FileExists(t, filepath.Join(hooksDir, "pre-push"))
+
+	// nothing to uninstall
+	require.NoError(t, uninstallHooks(hooksDir))
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/zricethezav/gitleaks/v8/issues"
+	"github.com/zricethezav/gitleaks/v8/report"
+	"github.com/zricethezav/gitleaks/v8/sources"
+)
+
+// addIssueFlags adds the flags that open tracking issues for leaked secrets.
+func addIssueFlags(cmd *cobra.Command) {
+	cmd.Flags().String("github-issues", "", "open an issue in this GitHub repository (owner/name) for every leaked secret without one, set GITHUB_TOKEN")
+	cmd.Flags().String("jira-url", "", "open an issue in --jira-project on this Jira server for every leaked secret without one, set JIRA_USER and JIRA_TOKEN")
+	cmd.Flags().String("jira-project", "", "key of the Jira project to open issues in, requires --jira-url")
+	cmd.Flags().String("jira-issue-type", "Task", "type of the Jira issues that are opened")
+}
+
+// openIssues opens a tracking issue for every secret in findings that doesn't
+// have one yet in the trackers set with --github-issues and --jira-url.
+func openIssues(cmd *cobra.Command, findings []report.Finding) {
+	if cmd.Flags().Lookup("github-issues") == nil || len(findings) == 0 {
+		return
+	}
+	var trackers []issues.Tracker
+	if repo, _ := cmd.Flags().GetString("github-issues"); repo != "" {
+		apiURL, _ := cmd.Flags().GetString("github-api-url")
+		tracker, err := issues.NewGitHub(apiURL, repo, githubToken(apiURL))
+		if err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+		trackers = append(trackers, tracker)
+	}
+	jiraURL, _ := cmd.Flags().GetString("jira-url")
+	jiraProject, _ := cmd.Flags().GetString("jira-project")
+	if jiraURL != "" || jiraProject != "" {
+		if jiraURL == "" || jiraProject == "" {
+			log.Fatal().Msg("--jira-url and --jira-project must be set together")
+		}
+		issueType, _ := cmd.Flags().GetString("jira-issue-type")
+		trackers = append(trackers, issues.NewJira(jiraURL, jiraProject, issueType, os.Getenv("JIRA_USER"), os.Getenv("JIRA_TOKEN")))
+	}
+	if len(trackers) == 0 {
+		return
+	}
+
+	// issues are deduplicated by a keyed HMAC of the secret, a plain hash in
+	// an issue body could be brute-forced
+	key, err := hmacKey(cmd)
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	if key == nil {
+		log.Fatal().Msg("opening issues requires a key to fingerprint secrets with, set --hmac-key-file or GITLEAKS_HMAC_KEY")
+	}
+	for _, tracker := range trackers {
+		opened, err := issues.Open(tracker, findings, key)
+		if err != nil {
+			log.Error().Err(err).Msg("could not open issues")
+		}
+		if len(opened) > 0 {
+			log.Info().Msgf("opened %d issues", len(opened))
+		}
+	}
+}
+
+// githubToken returns the token for the GitHub API at apiURL from
+// GITHUB_TOKEN or the git credential helpers.
+func githubToken(apiURL string) string {
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		return token
+	}
+	// credentials are stored for the web host, not the API host
+	webURL := "https://github.com"
+	if u, err := url.Parse(apiURL); err == nil && u.Host != "api.github.com" {
+		webURL = u.Scheme + "://" + strings.TrimPrefix(u.Host, "api.")
+	}
+	token, err := sources.CredentialHelperToken(webURL)
+	if err != nil {
+		log.Debug().Err(err).Msgf("no token for %s from git credential helpers", webURL)
+		return ""
+	}
+	return token
+}
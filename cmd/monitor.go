@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+	"github.com/zricethezav/gitleaks/v8/detect"
+	"github.com/zricethezav/gitleaks/v8/report"
+	"github.com/zricethezav/gitleaks/v8/sources"
+	"github.com/zricethezav/gitleaks/v8/store"
+)
+
+func init() {
+	rootCmd.AddCommand(monitorCmd)
+	addRepositoryFlags(monitorCmd)
+	monitorCmd.Flags().String("schedule", "@hourly", "how often to rescan, @hourly, @daily, @weekly, @every <duration> or a duration like 30m")
+	monitorCmd.Flags().String("state-path", "gitleaks-monitor.json", "file the last scanned commits of each repository are kept in, unused with --store")
+	monitorCmd.Flags().String("cache-dir", "", "directory clones of remote repositories are kept in between runs")
+	_ = monitorCmd.Flags().MarkDeprecated("cache-dir", "use --clone-dir instead")
+	monitorCmd.Flags().Bool("once", false, "scan once and exit, for running from cron")
+	addStoreFlag(monitorCmd)
+	addIssueFlags(monitorCmd)
+}
+
+var monitorCmd = &cobra.Command{
+	Use:   "monitor",
+	Short: "rescan repositories on a schedule and report leaks in new commits",
+	Run:   runMonitor,
+}
+
+func runMonitor(cmd *cobra.Command, args []string) {
+	initConfig()
+
+	// setup config (aka, the thing that defines rules)
+	cfg := Config(cmd)
+
+	source, err := cmd.Flags().GetString("source")
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	schedule, _ := cmd.Flags().GetString("schedule")
+	interval, err := parseSchedule(schedule)
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	statePath, _ := cmd.Flags().GetString("state-path")
+	// clones are always kept between runs, by default in the user cache
+	// directory. --cache-dir is the old name of --clone-dir.
+	cacheDir, _ := cmd.Flags().GetString("cache-dir")
+	if cacheDir == "" && !cmd.Flags().Changed("clone-dir") {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not find the user cache directory, set --clone-dir")
+		}
+		cacheDir = filepath.Join(userCacheDir, "gitleaks", "repos")
+	}
+	cacheDir = cloneDirectory(cmd, cacheDir)
+	once, _ := cmd.Flags().GetBool("once")
+	db := openStore(cmd)
+
+	detector := Detector(cmd, cfg, source)
+	defer closeDetector(detector)
+	for {
+		start := time.Now()
+		monitorRun(cmd, cfg, detector, db, statePath, cacheDir)
+		if once {
+			return
+		}
+		next := start.Add(interval)
+		log.Info().Msgf("next scan at %s", next.Format(time.RFC3339))
+		time.Sleep(time.Until(next))
+	}
+}
+
+// parseSchedule converts a cron-like schedule to the interval between scans.
+func parseSchedule(schedule string) (time.Duration, error) {
+	var interval time.Duration
+	switch schedule {
+	case "@hourly":
+		interval = time.Hour
+	case "@daily":
+		interval = 24 * time.Hour
+	case "@weekly":
+		interval = 7 * 24 * time.Hour
+	default:
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(schedule, "@every")))
+		if err != nil {
+			return 0, fmt.Errorf("invalid --schedule %q, must be @hourly, @daily, @weekly, @every <duration> or a duration", schedule)
+		}
+		interval = d
+	}
+	if interval <= 0 {
+		return 0, fmt.Errorf("invalid --schedule %q, the interval must be positive", schedule)
+	}
+	return interval, nil
+}
+
+// monitorRun scans the commits added to every repository since the previous
+// run and reports the leaks in them. The last scanned commits are kept in db
+// if there is one, otherwise in the state file at statePath.
+func monitorRun(cmd *cobra.Command, cfg config.Config, detector *detect.Detector, db store.Store, statePath string, cacheDir string) {
+	start := time.Now()
+	state := &sources.ScanState{Repositories: make(map[string]sources.RepositoryState)}
+	if db == nil {
+		var err error
+		if state, err = sources.LoadScanState(statePath); err != nil {
+			log.Error().Err(err).Msgf("could not load monitor state %s", statePath)
+			return
+		}
+	}
+
+	// repositories are listed on every run so new ones are picked up
+	repos := listRepositories(cmd, func(repo string) time.Time {
+		if db == nil {
+			return state.Repositories[repo].LastScan
+		}
+		stored, _, err := db.Repository(repo)
+		if err != nil {
+			return time.Time{}
+		}
+		return stored.LastScan
+	})
+	if len(repos) == 0 {
+		log.Warn().Msg("no repositories to monitor, set --repo, --repos-file or one of the platform flags")
+	}
+	policy := newClonePolicy(cmd)
+	var (
+		findings []report.Finding
+		failures []report.ScanFailure
+	)
+	for _, repo := range repos {
+		key := sources.RedactURL(repo.CloneURL)
+		previous := state.Repositories[key]
+		if db != nil {
+			stored, _, err := db.Repository(key)
+			if err != nil {
+				log.Error().Err(err).Msgf("unable to scan %s", key)
+				continue
+			}
+			previous = sources.RepositoryState{Heads: stored.Heads, LastScan: stored.LastScan}
+		}
+
+		repoStart := time.Now()
+		repoFindings, heads, err := monitorRepository(detector, repo, previous.Heads, cacheDir, policy.forRepository(repo))
+		if missingWiki(repo, err) {
+			continue
+		}
+		if err != nil {
+			log.Error().Err(err).Msgf("unable to scan %s", key)
+			failures = append(failures, scanFailure(repo.CloneURL, err))
+			continue
+		}
+		if heads == nil {
+			log.Debug().Msgf("%s: no new commits", key)
+			continue
+		}
+		repoFindings = classifyFindings(cmd, cfg, repoFindings)
+
+		// the heads are only recorded once the findings are, so a failed
+		// scan is retried next run. The last scan is when it started so
+		// pushes during the scan aren't skipped next run.
+		if db != nil {
+			scan := &store.Scan{Repository: key, StartTime: repoStart, EndTime: time.Now(), ConfigHash: cfg.Hash()}
+			if repoFindings, err = db.SaveScan(scan, repoFindings); err != nil {
+				log.Error().Err(err).Msgf("could not record the scan of %s", key)
+				continue
+			}
+			if err := db.SaveRepository(store.Repository{Name: key, RemoteURL: key, Heads: heads, LastScan: repoStart}); err != nil {
+				log.Error().Err(err).Msgf("could not record the scan of %s", key)
+			}
+		} else {
+			state.Repositories[key] = sources.RepositoryState{Heads: heads, LastScan: repoStart}
+		}
+		log.Info().Msgf("%s: %d new leaks found", key, len(repoFindings))
+		findings = append(findings, repoFindings...)
+	}
+	if db == nil {
+		if err := state.Save(statePath); err != nil {
+			log.Error().Err(err).Msgf("could not save monitor state %s", statePath)
+		}
+	}
+
+	log.Info().Msgf("scan completed in %s", FormatDuration(time.Since(start)))
+	if policy.retried > 0 {
+		log.Info().Msgf("%d repositories were only fetched after retrying", policy.retried)
+	}
+	if len(failures) > 0 {
+		log.Warn().Msgf("%d of %d repositories could not be scanned", len(failures), len(repos))
+	}
+	if len(findings) == 0 {
+		log.Info().Msg("no new leaks found")
+		return
+	}
+	log.Warn().Msgf("new leaks found: %d", len(findings))
+
+	// every run writes its own report so earlier ones aren't overwritten
+	if outputs := reportOutputs(cmd); len(outputs) > 0 {
+		manifest := report.Manifest{
+			Version:     Version,
+			Commit:      Commit,
+			ConfigHash:  cfg.Hash(),
+			CommandLine: redactedCommandLine(os.Args),
+			StartTime:   start,
+			EndTime:     time.Now(),
+			Failures:    failures,
+		}
+		for _, output := range outputs {
+			path := report.PartitionPath(output.path, start.UTC().Format("20060102T150405Z"))
+			if err := report.Write(findings, cfg, output.format, path, manifest); err != nil {
+				log.Error().Err(err).Msgf("could not write report %s", path)
+			}
+		}
+	}
+	if runActions, _ := cmd.Flags().GetBool("run-actions"); runActions && len(cfg.Actions) > 0 {
+		if err := detect.RunActions(cfg.Actions, findings); err != nil {
+			log.Error().Err(err).Msg("")
+		}
+	}
+	if notify, _ := cmd.Flags().GetBool("notify"); notify && len(cfg.Notifications) > 0 {
+		if err := detect.Notify(cfg.Notifications, findings); err != nil {
+			log.Error().Err(err).Msg("")
+		}
+	}
+	openIssues(cmd, findings)
+}
+
+// monitorRepository scans the commits of repo that aren't reachable from
+// previousHeads, the heads of its last scan, and returns the findings along
+// with its current heads. The heads are nil if nothing changed since the last
+// scan. Remote repositories are fetched as the policy says.
+func monitorRepository(detector *detect.Detector, repo sources.ListedRepository, previousHeads []string, cacheDir string, policy *clonePolicy) ([]report.Finding, []string, error) {
+	// the findings and errors of one repository shouldn't carry over to the
+	// next or accumulate in a long running monitor
+	defer detector.Reset()
+	source, cleanup, err := openRepository(repo, cacheDir, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cleanup()
+
+	heads, err := sources.RepositoryHeads(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	if strings.Join(heads, " ") == strings.Join(previousHeads, " ") {
+		return nil, nil, nil
+	}
+
+	logOpts := sources.NewCommitsLogOpts(source, previousHeads)
+	findings, err := detectRepositoryAt(detector, repo.CloneURL, source, logOpts, sources.MergeCommitsDefault, nil, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return findings, heads, nil
+}
@@ -12,6 +12,7 @@ import (
 
 func init() {
 	protectCmd.Flags().Bool("staged", false, "detect secrets in a --staged state")
+	protectCmd.Flags().Bool("worktree", false, "detect secrets in staged, unstaged and untracked changes compared to HEAD")
 	rootCmd.AddCommand(protectCmd)
 }
 
@@ -30,6 +31,10 @@ func runProtect(cmd *cobra.Command, args []string) {
 
 	exitCode, _ := cmd.Flags().GetInt("exit-code")
 	staged, _ := cmd.Flags().GetBool("staged")
+	worktree, _ := cmd.Flags().GetBool("worktree")
+	if staged && worktree {
+		log.Fatal().Msg("--staged and --worktree cannot be used together")
+	}
 	source, err := cmd.Flags().GetString("source")
 	if err != nil {
 		log.Fatal().Err(err).Msg("")
@@ -39,11 +44,35 @@ func runProtect(cmd *cobra.Command, args []string) {
 
 	// start git scan
 	var findings []report.Finding
-	gitCmd, err := sources.NewGitDiffCmd(source, staged)
+	detector.Repository = sources.RepositoryName(source)
+	detector.RemoteURL = sources.RemoteURL(source)
+	var gitCmd *sources.GitCmd
+	if worktree {
+		gitCmd, err = sources.NewGitDiffHeadCmd(source)
+	} else {
+		gitCmd, err = sources.NewGitDiffCmd(source, staged)
+	}
 	if err != nil {
 		log.Fatal().Err(err).Msg("")
 	}
 	findings, err = detector.DetectGit(gitCmd)
 
-	findingSummaryAndExit(findings, cmd, cfg, exitCode, start, err)
+	// untracked files never show up in a diff so they are scanned as files
+	if worktree && err == nil {
+		var untracked []sources.ScanTarget
+		untracked, err = sources.UntrackedFiles(source)
+		if err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+		paths := make(chan sources.ScanTarget, len(untracked))
+		for _, target := range untracked {
+			paths <- target
+		}
+		close(paths)
+		findings, err = detector.DetectFiles(paths)
+	}
+
+	closeDetector(detector)
+	writeSuppressions(cmd, detector)
+	findingSummaryAndExit(findings, cmd, cfg, exitCode, start, err, nil)
 }
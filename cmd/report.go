@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/zricethezav/gitleaks/v8/detect"
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.AddCommand(reportDiffCmd)
+	reportDiffCmd.Flags().Bool("json", false, "print the new, resolved and persisting findings as JSON")
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "work with gitleaks reports",
+}
+
+var reportDiffCmd = &cobra.Command{
+	Use:   "diff <old> <new>",
+	Short: "compare two JSON reports and exit with --exit-code if the new one has findings the old one doesn't",
+	Args:  cobra.ExactArgs(2),
+	Run:   runReportDiff,
+}
+
+func runReportDiff(cmd *cobra.Command, args []string) {
+	older, err := detect.LoadBaseline(args[0])
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	newer, err := detect.LoadBaseline(args[1])
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	diff := report.Diff(older, newer)
+
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", " ")
+		if err := encoder.Encode(diff); err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+	} else {
+		printDiffFindings("new", diff.New)
+		printDiffFindings("resolved", diff.Resolved)
+		fmt.Printf("%d new, %d resolved, %d persisting\n", len(diff.New), len(diff.Resolved), len(diff.Persisting))
+	}
+
+	if len(diff.New) > 0 {
+		exitCode, _ := cmd.Flags().GetInt("exit-code")
+		os.Exit(exitCode)
+	}
+}
+
+func printDiffFindings(status string, findings []report.Finding) {
+	for _, f := range findings {
+		location := fmt.Sprintf("%s:%d", f.File, f.StartLine)
+		if f.Commit != "" {
+			location += " " + shortCommit(f.Commit)
+		}
+		fmt.Printf("%-9s %-30s %s\n", status, f.RuleID, location)
+	}
+}
+
+// shortCommit abbreviates a commit SHA the way git log --oneline does.
+func shortCommit(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
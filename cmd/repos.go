@@ -0,0 +1,532 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/zricethezav/gitleaks/v8/detect"
+	"github.com/zricethezav/gitleaks/v8/report"
+	"github.com/zricethezav/gitleaks/v8/sources"
+)
+
+// addRepositoryFlags adds the flags that select the repositories to scan.
+func addRepositoryFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("repo", []string{}, "scan the history of this repository instead of --source, a path or a git URL to clone, can be repeated")
+	cmd.Flags().String("repos-file", "", "file with one repository path or git URL per line to scan like --repo")
+	cmd.Flags().String("gitea-url", "", "scan the repositories of --gitea-user or --gitea-org on this Gitea or Gogs server, set GITEA_TOKEN to include private repositories")
+	cmd.Flags().String("gitea-user", "", "scan every repository owned by this Gitea user, requires --gitea-url")
+	cmd.Flags().String("gitea-org", "", "scan every repository owned by this Gitea organization, requires --gitea-url")
+	cmd.Flags().StringArray("github-org", []string{}, "scan every repository of this GitHub organization, set GITHUB_TOKEN, can be repeated")
+	cmd.Flags().String("github-api-url", "https://api.github.com", "GitHub API URL for --github-org and --github-issues, for GitHub Enterprise Server")
+	cmd.Flags().Bool("github-skip-forks", false, "leave out forks when listing --github-org repositories")
+	cmd.Flags().Bool("github-skip-archived", false, "leave out archived repositories when listing --github-org repositories")
+	cmd.Flags().Bool("github-scan-issues", false, "scan the issues and pull requests of --github-org repositories and the comments on them too")
+	cmd.Flags().Bool("github-scan-wikis", false, "scan the wikis of --github-org repositories too")
+	cmd.Flags().Bool("github-ssh", false, "clone --github-org repositories over ssh instead of https with the token")
+	cmd.Flags().String("clone-dir", "", "directory clones of remote repositories are kept in and fetched between runs instead of cloning them again")
+	cmd.Flags().Int("clone-attempts", 3, "times cloning or fetching a remote repository is attempted before it is recorded as failed")
+	cmd.Flags().Duration("clone-backoff", 2*time.Second, "wait before retrying a failed clone or fetch, doubled after every attempt")
+	cmd.Flags().String("work-dir", "", "directory remote repositories are cloned into for the length of their scan when --clone-dir isn't set, instead of the system temporary directory")
+	cmd.Flags().String("clone-filter", "", "make partial clones of remote repositories with this git filter, e.g. blob:limit=1m, so blobs are downloaded as the scan needs them")
+	cmd.Flags().String("token-file", "", "file containing the token for --gitea-url, instead of GITEA_TOKEN or a git credential helper")
+	cmd.Flags().String("codecommit-region", "", "scan every AWS CodeCommit repository in this region, requires the aws CLI and git-remote-codecommit")
+	cmd.Flags().String("gcsr-project", "", "scan every Google Cloud Source Repository in this project, requires the gcloud CLI")
+}
+
+// listRepositories returns the repositories given with --repo and
+// --repos-file and those listed on GitHub, Gitea, CodeCommit and Cloud
+// Source Repositories. If lastScan is set, listed repositories that haven't
+// been pushed to since lastScan returns for them are left out.
+func listRepositories(cmd *cobra.Command, lastScan func(repo string) time.Time) []sources.ListedRepository {
+	repos, err := cmd.Flags().GetStringArray("repo")
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	reposFile, err := cmd.Flags().GetString("repos-file")
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	if reposFile != "" {
+		listed, err := sources.ReadRepositoryList(reposFile)
+		if err != nil {
+			log.Fatal().Err(err).Msgf("could not read --repos-file %s", reposFile)
+		}
+		repos = append(repos, listed...)
+	}
+	listed := unlistedRepositories(repos)
+	listed = append(listed, githubRepositories(cmd, lastScan)...)
+	listed = append(listed, giteaRepositories(cmd)...)
+	return append(listed, unlistedRepositories(cloudRepositories(cmd))...)
+}
+
+// unlistedRepositories wraps paths and URLs of repositories nothing else is
+// known about.
+func unlistedRepositories(repos []string) []sources.ListedRepository {
+	var listed []sources.ListedRepository
+	for _, repo := range repos {
+		name := sources.RepositoryName(repo)
+		if sources.RemoteRepository(repo) {
+			name = sources.RemoteRepositoryName(repo)
+		}
+		listed = append(listed, sources.ListedRepository{Name: name, CloneURL: repo})
+	}
+	return listed
+}
+
+// printRepositories writes a table of the repositories to w for
+// --list-repos.
+func printRepositories(w io.Writer, repos []sources.ListedRepository) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tURL\tSIZE\tLAST PUSH")
+	var total int64
+	for _, repo := range repos {
+		size, pushed := "-", "-"
+		if repo.Size > 0 {
+			size = formatSize(repo.Size)
+			total += repo.Size
+		}
+		if !repo.PushedAt.IsZero() {
+			pushed = repo.PushedAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", repo.Name, sources.RedactURL(repo.CloneURL), size, pushed)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%d repositories, %s\n", len(repos), formatSize(total))
+	return err
+}
+
+// formatSize formats a size in bytes for people.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// platformToken returns the token used to list and clone repositories on the
+// platform at baseURL. It is read from --token-file, the envVar, or the git
+// credential helpers (and the OS keychains behind them), in that order. An
+// empty token is returned if none of them has one.
+func platformToken(cmd *cobra.Command, envVar string, baseURL string) string {
+	if tokenFile, _ := cmd.Flags().GetString("token-file"); tokenFile != "" {
+		token, err := sources.ReadTokenFile(tokenFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not read --token-file")
+		}
+		return token
+	}
+	if token := os.Getenv(envVar); token != "" {
+		return token
+	}
+	token, err := sources.CredentialHelperToken(baseURL)
+	if err != nil {
+		log.Debug().Err(err).Msgf("no token for %s from git credential helpers", baseURL)
+		return ""
+	}
+	log.Debug().Msgf("using token for %s from git credential helpers", baseURL)
+	return token
+}
+
+// cloudRepositories returns the repositories in the --codecommit-region and
+// the --gcsr-project.
+func cloudRepositories(cmd *cobra.Command) []string {
+	var repos []string
+	if region, _ := cmd.Flags().GetString("codecommit-region"); region != "" {
+		codecommitRepos, err := sources.CodeCommitRepositories(region)
+		if err != nil {
+			log.Fatal().Err(err).Msgf("could not list CodeCommit repositories in %s", region)
+		}
+		log.Info().Msgf("found %d CodeCommit repositories in %s", len(codecommitRepos), region)
+		repos = append(repos, codecommitRepos...)
+	}
+	if project, _ := cmd.Flags().GetString("gcsr-project"); project != "" {
+		gcsrRepos, err := sources.GCSRRepositories(project)
+		if err != nil {
+			log.Fatal().Err(err).Msgf("could not list Cloud Source Repositories in %s", project)
+		}
+		log.Info().Msgf("found %d Cloud Source Repositories in %s", len(gcsrRepos), project)
+		repos = append(repos, gcsrRepos...)
+	}
+	return repos
+}
+
+// githubRepositories returns the clone URLs of the repositories of the
+// --github-org organizations, with their wikis and discussions if asked to.
+// Repositories that haven't been pushed to since their lastScan are left
+// out, as are forks and archived repositories if asked to.
+func githubRepositories(cmd *cobra.Command, lastScan func(repo string) time.Time) []sources.ListedRepository {
+	orgs, _ := cmd.Flags().GetStringArray("github-org")
+	if len(orgs) == 0 {
+		return nil
+	}
+	apiURL, _ := cmd.Flags().GetString("github-api-url")
+	skipForks, _ := cmd.Flags().GetBool("github-skip-forks")
+	skipArchived, _ := cmd.Flags().GetBool("github-skip-archived")
+	ssh, _ := cmd.Flags().GetBool("github-ssh")
+	scanIssues, _ := cmd.Flags().GetBool("github-scan-issues")
+	scanWikis, _ := cmd.Flags().GetBool("github-scan-wikis")
+
+	client := sources.NewGitHubClient(apiURL, githubToken(apiURL))
+	var repos []sources.ListedRepository
+	for _, org := range orgs {
+		orgRepos, err := client.OrgRepositories(org)
+		if err != nil {
+			log.Fatal().Err(err).Msgf("could not list repositories of github org %s", org)
+		}
+		unchanged := 0
+		for _, repo := range orgRepos {
+			if (skipForks && repo.IsFork) || (skipArchived && repo.IsArchived) {
+				continue
+			}
+			listed := sources.ListedRepository{
+				Name:          repo.Name,
+				CloneURL:      client.CloneURL(repo, ssh),
+				Size:          repo.DiskUsage * 1024,
+				PushedAt:      repo.PushedAt,
+				Visibility:    strings.ToLower(repo.Visibility),
+				Topics:        repo.Topics(),
+				DefaultBranch: repo.DefaultBranch(),
+				Archived:      repo.IsArchived,
+			}
+			// edits to a wiki don't count as pushes to the repository, it
+			// is listed whether the repository changed or not
+			if scanWikis && repo.HasWikiEnabled {
+				wiki := listed
+				wiki.Name += ".wiki"
+				wiki.CloneURL = client.WikiCloneURL(repo, ssh)
+				wiki.Size, wiki.PushedAt, wiki.Wiki = 0, time.Time{}, true
+				repos = append(repos, wiki)
+			}
+			if lastScan != nil && repo.PushedAt.Before(lastScan(sources.RedactURL(listed.CloneURL))) {
+				unchanged++
+				continue
+			}
+			if scanIssues {
+				name := repo.Name
+				listed.Discussions = func() ([]sources.Discussion, error) {
+					return client.Discussions(name)
+				}
+			}
+			repos = append(repos, listed)
+		}
+		log.Info().Msgf("found %d repositories of github org %s, %d unchanged since the last scan", len(orgRepos), org, unchanged)
+	}
+	return repos
+}
+
+// giteaRepositories returns the repositories owned by the --gitea-user and
+// --gitea-org on the --gitea-url server.
+func giteaRepositories(cmd *cobra.Command) []sources.ListedRepository {
+	giteaURL, _ := cmd.Flags().GetString("gitea-url")
+	user, _ := cmd.Flags().GetString("gitea-user")
+	org, _ := cmd.Flags().GetString("gitea-org")
+	if giteaURL == "" {
+		if user != "" || org != "" {
+			log.Fatal().Msg("--gitea-user and --gitea-org require --gitea-url")
+		}
+		return nil
+	}
+	if user == "" && org == "" {
+		log.Fatal().Msg("--gitea-url requires --gitea-user or --gitea-org")
+	}
+
+	client := sources.NewGiteaClient(giteaURL, platformToken(cmd, "GITEA_TOKEN", giteaURL))
+	var repos []sources.ListedRepository
+	if user != "" {
+		userRepos, err := client.UserRepositories(user)
+		if err != nil {
+			log.Fatal().Err(err).Msgf("could not list repositories of gitea user %s", user)
+		}
+		log.Info().Msgf("found %d repositories of gitea user %s", len(userRepos), user)
+		repos = append(repos, userRepos...)
+	}
+	if org != "" {
+		orgRepos, err := client.OrgRepositories(org)
+		if err != nil {
+			log.Fatal().Err(err).Msgf("could not list repositories of gitea org %s", org)
+		}
+		log.Info().Msgf("found %d repositories of gitea org %s", len(orgRepos), org)
+		repos = append(repos, orgRepos...)
+	}
+	return repos
+}
+
+// detectRepositories scans the history of every repository and returns the
+// findings of all of them along with the store names of the repositories
+// that were scanned. Repositories given as URLs are cloned into --clone-dir,
+// or a temporary directory in --work-dir without it, first. A repository that can't be
+// cloned or scanned is logged, recorded as a failure and skipped so one bad
+// entry doesn't stop a fleet scan.
+func detectRepositories(cmd *cobra.Command, detector *detect.Detector, repos []sources.ListedRepository) ([]report.Finding, []report.Target, []report.ScanFailure) {
+	logOpts, err := cmd.Flags().GetString("log-opts")
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	paths, err := cmd.Flags().GetStringSlice("path")
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	analyzeHistory := historyAnalysis(cmd)
+	merges := mergeCommits(cmd)
+	policy := newClonePolicy(cmd)
+	cloneDir := cloneDirectory(cmd, "")
+
+	var (
+		findings []report.Finding
+		scanned  []report.Target
+		failures []report.ScanFailure
+	)
+	for _, repo := range repos {
+		repoFindings, commit, err := detectRepository(detector, repo, cloneDir, policy.forRepository(repo), logOpts, merges, paths, analyzeHistory)
+		if missingWiki(repo, err) {
+			continue
+		}
+		if err != nil {
+			log.Error().Err(err).Msgf("unable to scan %s", sources.RedactURL(repo.CloneURL))
+			failures = append(failures, scanFailure(repo.CloneURL, err))
+		} else {
+			scanned = append(scanned, report.Target{Name: storeRepositoryName(detector.Repository, detector.RemoteURL), Commit: commit})
+		}
+		log.Info().Msgf("%s: %d leaks found", sources.RedactURL(repo.CloneURL), len(repoFindings))
+		findings = append(findings, repoFindings...)
+		if detector.FindingLimitReached() {
+			break
+		}
+	}
+	if policy.retried > 0 {
+		log.Info().Msgf("%d repositories were only cloned after retrying", policy.retried)
+	}
+	return findings, scanned, failures
+}
+
+// cloneError is an error cloning or fetching a repository, as opposed to
+// scanning it.
+type cloneError struct {
+	error
+	attempts int
+}
+
+func (e cloneError) Unwrap() error {
+	return e.error
+}
+
+// scanFailure records that repo couldn't be cloned or scanned because of err.
+func scanFailure(repo string, err error) report.ScanFailure {
+	failure := report.ScanFailure{Repository: sources.RedactURL(repo), Stage: "scan", Error: err.Error()}
+	var cloneErr cloneError
+	if errors.As(err, &cloneErr) {
+		failure.Stage = "clone"
+		failure.Attempts = cloneErr.attempts
+	}
+	return failure
+}
+
+// cloneDirectory returns the --clone-dir, creating it if needed, or
+// defaultDir if it isn't set.
+func cloneDirectory(cmd *cobra.Command, defaultDir string) string {
+	cloneDir, _ := cmd.Flags().GetString("clone-dir")
+	if cloneDir == "" {
+		cloneDir = defaultDir
+	}
+	if cloneDir == "" {
+		return ""
+	}
+	if err := os.MkdirAll(cloneDir, 0o700); err != nil {
+		log.Fatal().Err(err).Msg("could not create --clone-dir")
+	}
+	return cloneDir
+}
+
+// clonePolicy is how remote repositories are cloned and fetched.
+type clonePolicy struct {
+	// filter makes partial clones, e.g. blob:limit=1m.
+	filter string
+	retry  sources.Retry
+	// workDir is where temporary clones are made, the system temporary
+	// directory if it is empty.
+	workDir string
+
+	// retried counts the repositories that were only cloned after retrying.
+	retried int
+}
+
+// forRepository returns the policy repo is cloned with. Most wikis are
+// enabled without a page ever being written, so there is nothing to clone
+// and no point retrying.
+func (policy *clonePolicy) forRepository(repo sources.ListedRepository) *clonePolicy {
+	if !repo.Wiki {
+		return policy
+	}
+	wikiPolicy := *policy
+	wikiPolicy.retry.Attempts = 1
+	return &wikiPolicy
+}
+
+// missingWiki returns true if repo is a wiki that couldn't be cloned because
+// of err, which isn't a failure: it has no pages yet.
+func missingWiki(repo sources.ListedRepository, err error) bool {
+	var cloneErr cloneError
+	if !repo.Wiki || !errors.As(err, &cloneErr) {
+		return false
+	}
+	log.Debug().Err(err).Msgf("%s has no wiki pages", repo.Name)
+	return true
+}
+
+// newClonePolicy returns the clonePolicy set with the --clone-* flags.
+func newClonePolicy(cmd *cobra.Command) *clonePolicy {
+	policy := &clonePolicy{}
+	policy.filter, _ = cmd.Flags().GetString("clone-filter")
+	policy.retry.Attempts, _ = cmd.Flags().GetInt("clone-attempts")
+	policy.retry.Backoff, _ = cmd.Flags().GetDuration("clone-backoff")
+	policy.workDir, _ = cmd.Flags().GetString("work-dir")
+	if policy.workDir != "" {
+		if err := os.MkdirAll(policy.workDir, 0o700); err != nil {
+			log.Fatal().Err(err).Msg("could not create --work-dir")
+		}
+	}
+	return policy
+}
+
+// openRepository returns the local path of repo. Repositories given as URLs
+// are cloned into cacheDir, or fetched if they were cloned there before. With
+// an empty cacheDir they are cloned into a temporary directory in the
+// policy's workDir that cleanup removes. A repository is only cloned if the
+// disk has room for the size its platform reports. Failed clones and fetches
+// are retried as the policy says. Errors cloning or fetching are
+// cloneErrors.
+func openRepository(repo sources.ListedRepository, cacheDir string, policy *clonePolicy) (source string, cleanup func(), err error) {
+	if !sources.RemoteRepository(repo.CloneURL) {
+		return repo.CloneURL, func() {}, nil
+	}
+	if err := checkDiskSpace(repo, cacheDir, policy.workDir); err != nil {
+		return "", nil, cloneError{err, 0}
+	}
+	attempts, err := policy.retry.Do(repo.CloneURL, func() (err error) {
+		source, cleanup, err = cloneRepository(repo.CloneURL, cacheDir, policy)
+		return err
+	})
+	if err != nil {
+		return "", nil, cloneError{err, attempts}
+	}
+	if attempts > 1 {
+		policy.retried++
+	}
+	return source, cleanup, nil
+}
+
+// checkDiskSpace returns an error if repo is going to be cloned into cacheDir,
+// or workDir without it, and there is less space free there than the size
+// its platform reports. Repositories of unknown size and clones in cacheDir
+// that are only fetched aren't checked.
+func checkDiskSpace(repo sources.ListedRepository, cacheDir string, workDir string) error {
+	if repo.Size <= 0 {
+		return nil
+	}
+	dir := cacheDir
+	if dir != "" {
+		if _, err := os.Stat(filepath.Join(cacheDir, sources.RepositoryCacheName(repo.CloneURL))); err == nil {
+			return nil
+		}
+	} else if dir = workDir; dir == "" {
+		dir = os.TempDir()
+	}
+	free, err := sources.FreeDiskSpace(dir)
+	if err != nil {
+		log.Debug().Err(err).Msgf("could not check the disk space free in %s", dir)
+		return nil
+	}
+	if free < repo.Size {
+		return fmt.Errorf("not enough disk space to clone %s, %s needed and %s free in %s",
+			sources.RedactURL(repo.CloneURL), formatSize(repo.Size), formatSize(free), dir)
+	}
+	return nil
+}
+
+// cloneRepository makes a single attempt at cloning or fetching repo for
+// openRepository.
+func cloneRepository(repo string, cacheDir string, policy *clonePolicy) (source string, cleanup func(), err error) {
+	if cacheDir != "" {
+		source = filepath.Join(cacheDir, sources.RepositoryCacheName(repo))
+		return source, func() {}, sources.FetchRepository(repo, source, policy.filter)
+	}
+	dir, err := os.MkdirTemp(policy.workDir, "gitleaks-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+	source = filepath.Join(dir, "repo.git")
+	if err := sources.CloneRepository(repo, source, policy.filter); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return source, cleanup, nil
+}
+
+// detectRepository scans the history of a single repository and returns its
+// findings and the commit it was scanned at. Remote repositories are kept in
+// cloneDir if it is set, otherwise their clone is removed as soon as the scan
+// is done so a fleet scan only ever holds one clone on disk.
+func detectRepository(detector *detect.Detector, repo sources.ListedRepository, cloneDir string, policy *clonePolicy, logOpts string, merges sources.MergeCommits, paths []string, analyzeHistory bool) ([]report.Finding, string, error) {
+	source, cleanup, err := openRepository(repo, cloneDir, policy)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cleanup()
+	findings, err := detectRepositoryAt(detector, repo.CloneURL, source, logOpts, merges, paths, analyzeHistory)
+	if repo.Discussions != nil && !detector.FindingLimitReached() {
+		discussions, discussionsErr := repo.Discussions()
+		if discussionsErr != nil {
+			log.Error().Err(discussionsErr).Msgf("unable to scan the discussions of %s", repo.Name)
+		}
+		findings = append(findings, detector.DetectDiscussions(discussions)...)
+	}
+	for i := range findings {
+		findings[i].RepositoryVisibility = repo.Visibility
+		findings[i].RepositoryTopics = repo.Topics
+		findings[i].DefaultBranch = repo.DefaultBranch
+		findings[i].RepositoryArchived = repo.Archived
+	}
+	return findings, sources.HeadCommit(source), err
+}
+
+// detectRepositoryAt scans the history of repo, which has been opened at
+// source, and returns its findings.
+func detectRepositoryAt(detector *detect.Detector, repo string, source string, logOpts string, merges sources.MergeCommits, paths []string, analyzeHistory bool) ([]report.Finding, error) {
+	detector.Repository = sources.RepositoryName(source)
+	if sources.RemoteRepository(repo) {
+		detector.Repository = sources.RemoteRepositoryName(repo)
+	}
+	detector.RemoteURL = sources.RemoteURL(source)
+
+	gitCmd, err := sources.NewGitHistoryCmd(source, logOpts, merges, paths...)
+	if err != nil {
+		return nil, err
+	}
+	// the detector accumulates findings across scans, only the ones added by
+	// this scan belong to the repository
+	before := len(detector.Findings())
+	all, err := detector.DetectGit(gitCmd)
+	findings := append([]report.Finding(nil), all[before:]...)
+	if analyzeHistory {
+		findings = detector.AnalyzeHistory(source, findings)
+	}
+	return findings, err
+}
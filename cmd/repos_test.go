@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zricethezav/gitleaks/v8/sources"
+	"github.com/zricethezav/gitleaks/v8/sources/gittest"
+)
+
+// testClonePolicy returns a clonePolicy making attempts that records the
+// waits between them instead of sleeping.
+func testClonePolicy(attempts int, sleeps *[]time.Duration) *clonePolicy {
+	return &clonePolicy{
+		retry: sources.Retry{
+			Attempts: attempts,
+			Backoff:  time.Second,
+			Sleep:    func(d time.Duration) { *sleeps = append(*sleeps, d) },
+		},
+		workDir: os.TempDir(),
+	}
+}
+
+func TestOpenRepositoryAttempts(t *testing.T) {
+	var sleeps []time.Duration
+	policy := testClonePolicy(3, &sleeps)
+	repo := sources.ListedRepository{Name: "missing", CloneURL: "file:///does/not/exist.git"}
+
+	_, _, err := openRepository(repo, "", policy)
+	require.Error(t, err)
+	failure := scanFailure(repo.CloneURL, err)
+	assert.Equal(t, "clone", failure.Stage)
+	assert.Equal(t, 3, failure.Attempts)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, sleeps)
+	assert.Equal(t, 0, policy.retried)
+
+	// wikis without pages can't be cloned, they are only tried once
+	sleeps = nil
+	wiki := sources.ListedRepository{Name: "missing.wiki", CloneURL: "file:///does/not/exist.wiki.git", Wiki: true}
+	_, _, err = openRepository(wiki, "", policy.forRepository(wiki))
+	require.Error(t, err)
+	assert.Equal(t, 1, scanFailure(wiki.CloneURL, err).Attempts)
+	assert.True(t, missingWiki(wiki, err))
+	assert.Empty(t, sleeps)
+	assert.Equal(t, 3, policy.retry.Attempts)
+
+	// repositories on disk are neither cloned nor retried
+	source, cleanup, err := openRepository(sources.ListedRepository{CloneURL: "/does/not/exist"}, "", policy)
+	require.NoError(t, err)
+	cleanup()
+	assert.Equal(t, "/does/not/exist", source)
+	assert.Empty(t, sleeps)
+}
+
+func TestOpenRepositoryCloneDir(t *testing.T) {
+	remote := gittest.New(t)
+	remote.WriteFile("main.go", "package main")
+	remote.Commit("first")
+	repo := sources.ListedRepository{Name: "repo", CloneURL: "file://" + filepath.ToSlash(remote.Dir)}
+
+	var sleeps []time.Duration
+	policy := testClonePolicy(3, &sleeps)
+	cloneDir := t.TempDir()
+	source, cleanup, err := openRepository(repo, cloneDir, policy)
+	require.NoError(t, err)
+	cleanup()
+	assert.Equal(t, filepath.Join(cloneDir, sources.RepositoryCacheName(repo.CloneURL)), source)
+	assert.DirExists(t, source)
+
+	// the clone is kept and fetched by the next scan
+	remote.WriteFile("main.go", "package main\n")
+	second := remote.Commit("second")
+	reused, cleanup, err := openRepository(repo, cloneDir, policy)
+	require.NoError(t, err)
+	cleanup()
+	assert.Equal(t, source, reused)
+	assert.Equal(t, second, sources.HeadCommit(reused))
+	assert.Empty(t, sleeps)
+
+	// without a clone dir the temporary clone is removed by cleanup
+	temporary, cleanup, err := openRepository(repo, "", policy)
+	require.NoError(t, err)
+	assert.Equal(t, second, sources.HeadCommit(temporary))
+	cleanup()
+	assert.NoDirExists(t, temporary)
+}
+
+func TestReportOutputs(t *testing.T) {
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("report-path", "", "")
+		cmd.Flags().String("report-format", "json", "")
+		cmd.Flags().StringArray("report", []string{}, "")
+		return cmd
+	}
+
+	cmd := newCmd()
+	require.NoError(t, cmd.Flags().Set("report", "sarif:findings.sarif"))
+	require.NoError(t, cmd.Flags().Set("report", "csv:findings.csv"))
+	require.NoError(t, cmd.Flags().Set("report-path", "findings.json"))
+	assert.Equal(t, []reportOutput{
+		{format: "json", path: "findings.json"},
+		{format: "sarif", path: "findings.sarif"},
+		{format: "csv", path: "findings.csv"},
+	}, reportOutputs(cmd))
+
+	// --report alone, paths may contain colons
+	cmd = newCmd()
+	require.NoError(t, cmd.Flags().Set("report", `junit:C:\reports\junit.xml`))
+	assert.Equal(t, []reportOutput{{format: "junit", path: `C:\reports\junit.xml`}}, reportOutputs(cmd))
+
+	assert.Empty(t, reportOutputs(newCmd()))
+}
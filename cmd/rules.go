@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lucasjones/reggen"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+	"github.com/zricethezav/gitleaks/v8/detect"
+)
+
+// ruleExamples is the number of example matches rules describe prints.
+const ruleExamples = 3
+
+func init() {
+	rootCmd.AddCommand(rulesCmd)
+	rulesCmd.AddCommand(rulesListCmd)
+	rulesCmd.AddCommand(rulesDescribeCmd)
+}
+
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "document the built-in rules",
+}
+
+var rulesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the built-in rules",
+	Run:   runRulesList,
+}
+
+var rulesDescribeCmd = &cobra.Command{
+	Use:   "describe <id>",
+	Short: "describe a built-in rule with example matches",
+	Args:  cobra.ExactArgs(1),
+	Run:   runRulesDescribe,
+}
+
+func runRulesList(cmd *cobra.Command, args []string) {
+	cfg := defaultConfig()
+	for _, rule := range cfg.GetOrderedRules() {
+		fmt.Printf("%-40s %s\n", rule.RuleID, rule.Description)
+	}
+}
+
+func runRulesDescribe(cmd *cobra.Command, args []string) {
+	cfg := defaultConfig()
+	rule, ok := cfg.Rules[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "no built-in rule %s, see gitleaks rules list\n", args[0])
+		os.Exit(1)
+	}
+
+	fmt.Printf("%-13s %s\n", "ID:", rule.RuleID)
+	fmt.Printf("%-13s %s\n", "Description:", rule.Description)
+	if rule.Regex != nil {
+		fmt.Printf("%-13s %s\n", "Regex:", rule.Regex)
+	}
+	if rule.Path != nil {
+		fmt.Printf("%-13s %s\n", "Path:", rule.Path)
+	}
+	if rule.Entropy > 0 {
+		fmt.Printf("%-13s %g\n", "Entropy:", rule.Entropy)
+	}
+	if rule.Validate != "" {
+		fmt.Printf("%-13s %s\n", "Validate:", rule.Validate)
+	}
+	severity := rule.Severity
+	if severity == "" {
+		severity = "medium"
+	}
+	fmt.Printf("%-13s %s\n", "Severity:", severity)
+	fmt.Printf("%-13s %s\n", "Keywords:", strings.Join(rule.Keywords, ", "))
+	fmt.Printf("%-13s %s\n", "Tags:", strings.Join(rule.Tags, ", "))
+
+	examples := exampleMatches(cfg, rule, ruleExamples)
+	if len(examples) == 0 {
+		fmt.Printf("%-13s %s\n", "Examples:", "none could be generated")
+		return
+	}
+	fmt.Printf("%-13s\n", "Examples:")
+	for _, example := range examples {
+		fmt.Printf("  %q\n", example)
+	}
+}
+
+// defaultConfig loads the built-in rules, whatever config is in use.
+func defaultConfig() config.Config {
+	viper.SetConfigType("toml")
+	if err := viper.ReadConfig(strings.NewReader(config.DefaultConfig)); err != nil {
+		log.Fatal().Msgf("err reading default config toml %s", err.Error())
+	}
+	var vc config.ViperConfig
+	if err := viper.Unmarshal(&vc); err != nil {
+		log.Fatal().Err(err).Msg("Failed to load config")
+	}
+	cfg, err := vc.Translate()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load config")
+	}
+	return cfg
+}
+
+// exampleMatches generates up to n strings that the regex of rule matches
+// and that gitleaks reports, so they pass its entropy, validator and
+// allowlists. Rules that need more context than their regex matches, such as
+// an access key id next to a secret key, have no examples.
+func exampleMatches(cfg config.Config, rule config.Rule, n int) []string {
+	if rule.Regex == nil {
+		return nil
+	}
+	g, err := reggen.NewGenerator(rule.Regex.String())
+	if err != nil {
+		return nil
+	}
+	// the same examples every time
+	g.SetSeed(1)
+
+	cfg.Rules = map[string]config.Rule{rule.RuleID: rule}
+	cfg.OrderedRules = []string{rule.RuleID}
+	detector := detect.NewDetector(cfg)
+	var examples []string
+	for i := 0; i < 100*n && len(examples) < n; i++ {
+		example := g.Generate(10)
+		if len(detector.DetectString(example)) > 0 {
+			examples = append(examples, example)
+		}
+	}
+	return examples
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+	"github.com/zricethezav/gitleaks/v8/detect"
+	"github.com/zricethezav/gitleaks/v8/report"
+	"github.com/zricethezav/gitleaks/v8/store"
+)
+
+// maxScanBodyBytes limits the size of content that can be submitted to
+// the scan endpoint.
+const maxScanBodyBytes = 10 * 1024 * 1024
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().String("addr", "127.0.0.1:8080", "address to listen on")
+	serveCmd.Flags().String("api-token-file", "", "file holding the bearer token required to query the store with --store")
+	addStoreFlag(serveCmd)
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "run gitleaks as an http server that scans submitted content",
+	Run:   runServe,
+}
+
+func runServe(cmd *cobra.Command, args []string) {
+	initConfig()
+
+	// setup config (aka, the thing that defines rules)
+	cfg := Config(cmd)
+
+	source, err := cmd.Flags().GetString("source")
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	addr, err := cmd.Flags().GetString("addr")
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	detector := Detector(cmd, cfg, source)
+
+	// reload the config when it changes so rules can be updated without
+	// restarting the server
+	if cfgPath := viper.ConfigFileUsed(); cfgPath != "" {
+		configWatcher, err := config.Watch(cfgPath, reloadConfig(cmd, detector))
+		if err != nil {
+			log.Warn().Err(err).Msgf("unable to watch config %s for changes", cfgPath)
+		} else {
+			defer configWatcher.Close()
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/scan", scanHandler(detector))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "configHash": detector.ConfigHash()})
+	})
+	// the findings recorded by detect and monitor can be queried when the
+	// server shares their store
+	if db := openStore(cmd); db != nil {
+		api := store.NewHandler(db, apiToken(cmd))
+		mux.Handle("/v1/findings", api)
+		mux.Handle("/v1/scans", api)
+		mux.Handle("/v1/repos", api)
+	}
+
+	log.Info().Msgf("listening on %s", addr)
+	err = http.ListenAndServe(addr, mux)
+	closeDetector(detector)
+	log.Fatal().Err(err).Msg("")
+}
+
+// apiToken returns the token in the --api-token-file that requests to the
+// store API must carry.
+func apiToken(cmd *cobra.Command) string {
+	path, _ := cmd.Flags().GetString("api-token-file")
+	if path == "" {
+		log.Fatal().Msg("serving the store requires a token to authenticate requests with, set --api-token-file")
+	}
+	token, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatal().Err(err).Msg("unable to read api token")
+	}
+	if len(strings.TrimSpace(string(token))) == 0 {
+		log.Fatal().Msgf("api token file %s is empty", path)
+	}
+	return strings.TrimSpace(string(token))
+}
+
+// scanHandler scans the request body for secrets. The optional `path` query
+// parameter is used as the file path of the content so path based rules and
+// allowlists apply.
+func scanHandler(detector *detect.Detector) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		// content is rejected rather than truncated, a secret past the
+		// limit would go unreported
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxScanBodyBytes))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": fmt.Sprintf("content is larger than %d bytes", tooLarge.Limit)})
+			return
+		}
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		findings := detector.Detect(detect.Fragment{
+			Raw:      string(body),
+			FilePath: r.URL.Query().Get("path"),
+		})
+		if findings == nil {
+			findings = []report.Finding{}
+		}
+		log.Debug().Msgf("scanned %d bytes with config %s, leaks found: %d", len(body), detector.ConfigHash(), len(findings))
+		writeJSON(w, http.StatusOK, findings)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error().Err(err).Msg("could not write response")
+	}
+}
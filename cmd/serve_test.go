@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+	"github.com/zricethezav/gitleaks/v8/detect"
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+func TestScanHandler(t *testing.T) {
+	detector := detect.NewDetector(config.Config{Rules: map[string]config.Rule{
+		"test-token": {RuleID: "test-token", Regex: regexp.MustCompile(`tok_[a-z0-9]{16}`)},
+	}})
+	handler := scanHandler(detector)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/scan?path=app.env", strings.NewReader("TOKEN=tok_0123456789abcdef\n")))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var findings []report.Finding
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &findings))
+	require.Len(t, findings, 1)
+	assert.Equal(t, "app.env", findings[0].File)
+
+	// a secret past the limit would be missed if the content was truncated
+	body := strings.Repeat("a", maxScanBodyBytes) + "\nTOKEN=tok_0123456789abcdef\n"
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/scan", strings.NewReader(body)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Contains(t, rec.Body.String(), "larger than")
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/scan", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
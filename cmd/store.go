@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+	"github.com/zricethezav/gitleaks/v8/report"
+	"github.com/zricethezav/gitleaks/v8/store"
+)
+
+// addStoreFlag adds the flag that selects the findings database.
+func addStoreFlag(cmd *cobra.Command) {
+	cmd.Flags().String("store", "", "record scans and findings in this database, a SQLite path, sqlite:path or a postgres:// URL, and report which findings are new")
+}
+
+// openStore opens the findings database given with --store, or returns nil
+// if there is none.
+func openStore(cmd *cobra.Command) store.Store {
+	if cmd.Flags().Lookup("store") == nil {
+		return nil
+	}
+	dsn, _ := cmd.Flags().GetString("store")
+	if dsn == "" {
+		return nil
+	}
+	s, err := store.Open(dsn)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not open --store")
+	}
+	return s
+}
+
+// storeRepositoryName is the name a repository is kept under in the store. It
+// is the remote URL when there is one so repositories with the same name in
+// different organizations don't collide.
+func storeRepositoryName(repository string, remoteURL string) string {
+	if remoteURL != "" {
+		return remoteURL
+	}
+	return repository
+}
+
+// saveScans records a scan of every scanned repository, along with its
+// findings, in the store and returns the findings the store hadn't seen
+// before. Findings that don't name their repository belong to the first
+// scanned one.
+func saveScans(s store.Store, cfg config.Config, scanned []string, findings []report.Finding, start time.Time) []report.Finding {
+	end := time.Now()
+	names := append([]string(nil), scanned...)
+	byRepo := make(map[string][]report.Finding)
+	for _, name := range names {
+		byRepo[name] = nil
+	}
+	for _, f := range findings {
+		name := storeRepositoryName(f.Repository, f.RemoteURL)
+		if name == "" && len(scanned) > 0 {
+			name = scanned[0]
+		}
+		if _, ok := byRepo[name]; !ok {
+			names = append(names, name)
+		}
+		byRepo[name] = append(byRepo[name], f)
+	}
+
+	var newFindings []report.Finding
+	for _, name := range names {
+		scan := &store.Scan{
+			Repository: name,
+			StartTime:  start,
+			EndTime:    end,
+			ConfigHash: cfg.Hash(),
+		}
+		repoNew, err := s.SaveScan(scan, byRepo[name])
+		if err != nil {
+			log.Error().Err(err).Msgf("could not record the scan of %s", name)
+			continue
+		}
+		repo, _, err := s.Repository(name)
+		if err != nil {
+			log.Error().Err(err).Msgf("could not record the scan of %s", name)
+			continue
+		}
+		// the heads are left alone, they are only known to the monitor
+		repo.LastScan = end
+		if len(byRepo[name]) > 0 && byRepo[name][0].RemoteURL != "" {
+			repo.RemoteURL = byRepo[name][0].RemoteURL
+		}
+		if err := s.SaveRepository(repo); err != nil {
+			log.Error().Err(err).Msgf("could not record the scan of %s", name)
+			continue
+		}
+		log.Info().Msgf("%s: %d of %d leaks are new since earlier scans", name, len(repoNew), len(byRepo[name]))
+		newFindings = append(newFindings, repoNew...)
+	}
+	return newFindings
+}
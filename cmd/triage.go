@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/zricethezav/gitleaks/v8/detect"
+	"github.com/zricethezav/gitleaks/v8/report"
+	"github.com/zricethezav/gitleaks/v8/sources"
+)
+
+// triageContextLines is the number of lines shown before and after a finding
+// by the context command of triage.
+const triageContextLines = 5
+
+func init() {
+	rootCmd.AddCommand(triageCmd)
+	triageCmd.Flags().String("gitleaksignore", "", "append the fingerprints of false positives and accepted findings to this file (default: .gitleaksignore in --source)")
+	triageCmd.Flags().String("baseline-out", "", "add false positives and accepted findings to this baseline instead of the .gitleaksignore")
+}
+
+var triageCmd = &cobra.Command{
+	Use:   "triage <report>",
+	Short: "step through the findings of a JSON report and record which are false positives, accepted or need rotation",
+	Args:  cobra.ExactArgs(1),
+	Run:   runTriage,
+}
+
+func runTriage(cmd *cobra.Command, args []string) {
+	findings, err := detect.LoadBaseline(args[0])
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	source, err := cmd.Flags().GetString("source")
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+
+	decisions := report.Triage(findings, os.Stdin, os.Stdout, func(f report.Finding) []string {
+		return triageContext(source, f)
+	})
+	suppressed := report.Decided(findings, decisions, report.DecisionFalsePositive, report.DecisionAccepted)
+	rotate := report.Decided(findings, decisions, report.DecisionNeedsRotation)
+
+	if baselineOut, _ := cmd.Flags().GetString("baseline-out"); baselineOut != "" {
+		if err := report.MergeBaseline(baselineOut, suppressed); err != nil {
+			log.Fatal().Err(err).Msgf("could not write %s", baselineOut)
+		}
+		log.Info().Msgf("added %d findings to the baseline %s", len(suppressed), baselineOut)
+	} else if len(suppressed) > 0 {
+		ignorePath, _ := cmd.Flags().GetString("gitleaksignore")
+		if ignorePath == "" {
+			ignorePath = filepath.Join(source, ".gitleaksignore")
+		}
+		if err := report.AppendGitleaksIgnore(ignorePath, suppressed); err != nil {
+			log.Fatal().Err(err).Msgf("could not write %s", ignorePath)
+		}
+		log.Info().Msgf("added %d fingerprints to %s", len(suppressed), ignorePath)
+	}
+
+	if len(rotate) > 0 {
+		fmt.Printf("\n%d findings need rotation:\n", len(rotate))
+		for _, f := range rotate {
+			fmt.Printf("  %-30s %s\n", f.RuleID, f.Fingerprint)
+		}
+	}
+}
+
+// triageContext returns the numbered lines around a finding, read from the
+// file at the finding's commit in the repository at source or from disk.
+func triageContext(source string, f report.Finding) []string {
+	var content string
+	if f.Commit != "" {
+		var err error
+		if content, err = sources.FileAtRef(source, f.Commit, f.File); err != nil {
+			return nil
+		}
+	} else {
+		b, err := os.ReadFile(f.File)
+		if err != nil {
+			return nil
+		}
+		content = string(b)
+	}
+
+	lines := strings.Split(content, "\n")
+	start := f.StartLine - triageContextLines
+	if start < 1 {
+		start = 1
+	}
+	end := f.EndLine + triageContextLines
+	if end > len(lines) {
+		end = len(lines)
+	}
+	var context []string
+	for n := start; n <= end; n++ {
+		marker := " "
+		if n >= f.StartLine && n <= f.EndLine {
+			marker = ">"
+		}
+		context = append(context, fmt.Sprintf("%s %5d %s", marker, n, lines[n-1]))
+	}
+	return context
+}
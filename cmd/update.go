@@ -0,0 +1,357 @@
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// releaseRepository is the GitHub repository gitleaks is released from.
+const releaseRepository = "gitleaks/gitleaks"
+
+// releaseAPIURL lists the releases, replaced in tests.
+var releaseAPIURL = "https://api.github.com/repos/" + releaseRepository + "/releases?per_page=10"
+
+// releaseDownloadURL is where release files are downloaded from, replaced in
+// tests.
+var releaseDownloadURL = "https://github.com/" + releaseRepository + "/releases/download/"
+
+// maxReleaseSize limits the size of downloaded release files.
+const maxReleaseSize = 100 << 20
+
+// ReleasePublicKey is the base64 encoded ed25519 public key the checksums of
+// releases are signed with, set by the build process. Builds without it
+// can't update themselves.
+var ReleasePublicKey = ""
+
+var releaseClient = &http.Client{Timeout: 5 * time.Minute}
+
+func init() {
+	rootCmd.AddCommand(updateCmd)
+	updateCmd.Flags().Bool("check", false, "only report whether another release is available")
+	updateCmd.Flags().String("release", "", "install this release, ex: v8.18.0, instead of the latest")
+}
+
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "replace gitleaks with the latest release",
+	Run:   runUpdate,
+}
+
+func runUpdate(cmd *cobra.Command, args []string) {
+	check, _ := cmd.Flags().GetBool("check")
+	tag, err := cmd.Flags().GetString("release")
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	if tag == "" {
+		if tag, err = latestRelease(); err != nil {
+			log.Fatal().Err(err).Msg("could not find the latest release")
+		}
+	}
+	tag = "v" + strings.TrimPrefix(tag, "v")
+	if tag == "v"+strings.TrimPrefix(Version, "v") {
+		log.Info().Msgf("gitleaks is already %s", tag)
+		return
+	}
+	if check {
+		fmt.Printf("gitleaks %s is available, this is %s, run gitleaks update to install it\n", tag, Version)
+		return
+	}
+	if ReleasePublicKey == "" {
+		log.Fatal().Msgf("this build of gitleaks can't verify releases, download %s from https://github.com/%s/releases", tag, releaseRepository)
+	}
+
+	executable, err := os.Executable()
+	if err == nil {
+		executable, err = filepath.EvalSymlinks(executable)
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not find the gitleaks executable")
+	}
+	binary, err := downloadRelease(tag, runtime.GOOS, runtime.GOARCH, buildGOARM())
+	if err != nil {
+		log.Fatal().Err(err).Msgf("could not download gitleaks %s", tag)
+	}
+	if err := replaceExecutable(executable, binary); err != nil {
+		log.Fatal().Err(err).Msgf("could not replace %s", executable)
+	}
+	log.Info().Msgf("updated %s from %s to %s", executable, Version, tag)
+}
+
+// latestRelease returns the tag of the most recent release, skipping drafts
+// and prereleases.
+func latestRelease() (string, error) {
+	body, err := downloadReleaseFile(releaseAPIURL)
+	if err != nil {
+		return "", err
+	}
+	var releases []struct {
+		TagName    string `json:"tag_name"`
+		Draft      bool   `json:"draft"`
+		Prerelease bool   `json:"prerelease"`
+	}
+	if err := json.Unmarshal(body, &releases); err != nil {
+		return "", err
+	}
+	for _, r := range releases {
+		if !r.Draft && !r.Prerelease {
+			return r.TagName, nil
+		}
+	}
+	return "", fmt.Errorf("%s has no releases", releaseRepository)
+}
+
+// buildGOARM returns the ARM version gitleaks was built for, 6 if it isn't
+// known since those builds run on every ARM version releases are built for.
+func buildGOARM() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			// newer versions of go append options, ex: 7,softfloat
+			if s.Key == "GOARM" && s.Value != "" {
+				return s.Value[:1]
+			}
+		}
+	}
+	return "6"
+}
+
+// releaseArchive returns the name of the archive the release builds for goos,
+// goarch and, on arm, goarm in. The names follow the archive name_template in
+// .goreleaser.yml.
+func releaseArchive(version string, goos string, goarch string, goarm string) (string, error) {
+	arch := map[string]string{"amd64": "x64", "386": "x32", "arm64": "arm64"}[goarch]
+	if goarch == "arm" {
+		arch = map[string]string{"6": "armv6", "7": "armv7"}[goarm]
+	}
+	supported := goos == "linux" || goos == "windows" || (goos == "darwin" && (goarch == "amd64" || goarch == "arm64"))
+	if arch == "" || !supported {
+		if goarch == "arm" {
+			goarch += "v" + goarm
+		}
+		return "", fmt.Errorf("no release is built for %s/%s", goos, goarch)
+	}
+	ext := ".tar.gz"
+	if goos == "windows" {
+		ext = ".zip"
+	}
+	return fmt.Sprintf("gitleaks_%s_%s_%s%s", version, goos, arch, ext), nil
+}
+
+// downloadRelease returns the gitleaks binary of the release for goos, goarch
+// and goarm. The archive is verified against the checksums of the release, whose
+// signature is verified with ReleasePublicKey.
+func downloadRelease(tag string, goos string, goarch string, goarm string) ([]byte, error) {
+	version := strings.TrimPrefix(tag, "v")
+	archive, err := releaseArchive(version, goos, goarch, goarm)
+	if err != nil {
+		return nil, err
+	}
+	base := releaseDownloadURL + tag + "/"
+	checksumsName := fmt.Sprintf("gitleaks_%s_checksums.txt", version)
+
+	checksums, err := downloadReleaseFile(base + checksumsName)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := downloadReleaseFile(base + checksumsName + ".sig")
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyReleaseSignature(checksums, signature); err != nil {
+		return nil, err
+	}
+	want, err := releaseChecksum(checksums, archive)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := downloadReleaseFile(base + archive)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(content)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return nil, fmt.Errorf("checksum of %s is %s, expected %s", archive, got, want)
+	}
+
+	binaryName := "gitleaks"
+	if goos == "windows" {
+		binaryName += ".exe"
+	}
+	return extractBinary(archive, content, binaryName)
+}
+
+func downloadReleaseFile(url string) ([]byte, error) {
+	resp, err := releaseClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", url, resp.Status)
+	}
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxReleaseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", url, err)
+	}
+	if len(content) > maxReleaseSize {
+		return nil, fmt.Errorf("fetching %s: larger than %d bytes", url, maxReleaseSize)
+	}
+	return content, nil
+}
+
+// verifyReleaseSignature checks the base64 encoded ed25519 signature of the
+// checksums of a release.
+func verifyReleaseSignature(checksums []byte, signature []byte) error {
+	key, err := base64.StdEncoding.DecodeString(ReleasePublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("the release public key of this build is not a base64 encoded ed25519 public key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature)))
+	if err != nil || !ed25519.Verify(key, checksums, sig) {
+		return fmt.Errorf("signature of the release checksums does not verify")
+	}
+	return nil
+}
+
+// releaseChecksum returns the SHA-256 of name in a checksums file, lines of
+// "<hex> <name>" like sha256sum writes.
+func releaseChecksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("the release checksums don't include %s", name)
+}
+
+// extractBinary returns the content of the file named binaryName in a
+// .tar.gz or .zip archive. Archives with entries outside of the directory
+// they would be extracted to are rejected.
+func extractBinary(archive string, content []byte, binaryName string) ([]byte, error) {
+	if strings.HasSuffix(archive, ".zip") {
+		zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zr.File {
+			if unsafeArchivePath(f.Name) {
+				return nil, fmt.Errorf("%s contains an entry outside of the archive: %s", archive, f.Name)
+			}
+		}
+		for _, f := range zr.File {
+			if path.Base(f.Name) != binaryName || f.FileInfo().IsDir() {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return io.ReadAll(io.LimitReader(rc, maxReleaseSize))
+		}
+		return nil, fmt.Errorf("%s does not contain %s", archive, binaryName)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gz)
+	var binary []byte
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if unsafeArchivePath(hdr.Name) {
+			return nil, fmt.Errorf("%s contains an entry outside of the archive: %s", archive, hdr.Name)
+		}
+		if binary == nil && hdr.Typeflag == tar.TypeReg && path.Base(hdr.Name) == binaryName {
+			if binary, err = io.ReadAll(io.LimitReader(tr, maxReleaseSize)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if binary == nil {
+		return nil, fmt.Errorf("%s does not contain %s", archive, binaryName)
+	}
+	return binary, nil
+}
+
+// unsafeArchivePath returns true if an archive entry is absolute or climbs out
+// of the archive with "..".
+func unsafeArchivePath(name string) bool {
+	name = strings.ReplaceAll(name, `\`, "/")
+	if path.IsAbs(name) || filepath.IsAbs(name) {
+		return true
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceExecutable writes binary next to executable and renames it over
+// executable, so a failed update leaves the old one in place.
+func replaceExecutable(executable string, binary []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(executable), ".gitleaks-update-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return err
+	}
+
+	if runtime.GOOS == "windows" {
+		// a running executable can't be replaced on windows, but it can
+		// be renamed out of the way
+		old := executable + ".old"
+		os.Remove(old)
+		if err := os.Rename(executable, old); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp.Name(), executable); err != nil {
+			os.Rename(old, executable)
+			return err
+		}
+		return nil
+	}
+	return os.Rename(tmp.Name(), executable)
+}
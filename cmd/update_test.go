@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRelease serves the files of a release of version 8.18.0 signed with a
+// key that becomes the ReleasePublicKey for the duration of the test.
+type testRelease struct {
+	files      map[string][]byte
+	privateKey ed25519.PrivateKey
+}
+
+func newTestRelease(t *testing.T) *testRelease {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	key, url := ReleasePublicKey, releaseDownloadURL
+	t.Cleanup(func() { ReleasePublicKey, releaseDownloadURL = key, url })
+	ReleasePublicKey = base64.StdEncoding.EncodeToString(publicKey)
+
+	r := &testRelease{files: make(map[string][]byte), privateKey: privateKey}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		content, ok := r.files[filepath.Base(req.URL.Path)]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+	releaseDownloadURL = server.URL + "/"
+	return r
+}
+
+// add adds an archive to the release and returns its checksums line.
+func (r *testRelease) add(name string, content []byte) string {
+	r.files[name] = content
+	sum := sha256.Sum256(content)
+	return fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), name)
+}
+
+// sign adds the checksums and their signature to the release.
+func (r *testRelease) sign(checksums string) {
+	r.files["gitleaks_8.18.0_checksums.txt"] = []byte(checksums)
+	r.files["gitleaks_8.18.0_checksums.txt.sig"] = []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(r.privateKey, []byte(checksums))) + "\n")
+}
+
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestDownloadRelease(t *testing.T) {
+	r := newTestRelease(t)
+	checksums := r.add("gitleaks_8.18.0_linux_x64.tar.gz", tarGz(t, map[string]string{"LICENSE": "MIT", "gitleaks": "linux binary"}))
+	checksums += r.add("gitleaks_8.18.0_windows_x64.zip", zipArchive(t, map[string]string{"README.md": "", "gitleaks.exe": "windows binary"}))
+	r.sign(checksums)
+
+	binary, err := downloadRelease("v8.18.0", "linux", "amd64", "")
+	require.NoError(t, err)
+	assert.Equal(t, "linux binary", string(binary))
+
+	binary, err = downloadRelease("v8.18.0", "windows", "amd64", "")
+	require.NoError(t, err)
+	assert.Equal(t, "windows binary", string(binary))
+}
+
+func TestDownloadReleaseBadSignature(t *testing.T) {
+	r := newTestRelease(t)
+	checksums := r.add("gitleaks_8.18.0_linux_x64.tar.gz", tarGz(t, map[string]string{"gitleaks": "linux binary"}))
+	r.sign(checksums)
+	// checksums changed after they were signed
+	r.files["gitleaks_8.18.0_checksums.txt"] = []byte(checksums + "0000  gitleaks_8.18.0_linux_arm64.tar.gz\n")
+
+	_, err := downloadRelease("v8.18.0", "linux", "amd64", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "signature of the release checksums does not verify")
+
+	// signed with another key
+	_, r.privateKey, _ = ed25519.GenerateKey(nil)
+	r.sign(checksums)
+	_, err = downloadRelease("v8.18.0", "linux", "amd64", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "signature of the release checksums does not verify")
+
+	ReleasePublicKey = "not a key"
+	assert.Error(t, verifyReleaseSignature([]byte(checksums), r.files["gitleaks_8.18.0_checksums.txt.sig"]))
+}
+
+func TestDownloadReleaseMissingChecksum(t *testing.T) {
+	r := newTestRelease(t)
+	r.add("gitleaks_8.18.0_linux_x64.tar.gz", tarGz(t, map[string]string{"gitleaks": "linux binary"}))
+	r.sign(r.add("gitleaks_8.18.0_darwin_x64.tar.gz", tarGz(t, map[string]string{"gitleaks": "darwin binary"})))
+
+	_, err := downloadRelease("v8.18.0", "linux", "amd64", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the release checksums don't include gitleaks_8.18.0_linux_x64.tar.gz")
+}
+
+func TestDownloadReleaseChecksumMismatch(t *testing.T) {
+	r := newTestRelease(t)
+	r.sign(r.add("gitleaks_8.18.0_linux_x64.tar.gz", tarGz(t, map[string]string{"gitleaks": "linux binary"})))
+	// the archive was replaced after the release was signed
+	r.files["gitleaks_8.18.0_linux_x64.tar.gz"] = tarGz(t, map[string]string{"gitleaks": "another binary"})
+
+	_, err := downloadRelease("v8.18.0", "linux", "amd64", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum of gitleaks_8.18.0_linux_x64.tar.gz is")
+}
+
+func TestReleaseChecksum(t *testing.T) {
+	checksums := []byte("ABC123  gitleaks_8.18.0_linux_x64.tar.gz\n" +
+		"def456 *gitleaks_8.18.0_windows_x64.zip\n" +
+		"malformed line\n")
+	sum, err := releaseChecksum(checksums, "gitleaks_8.18.0_linux_x64.tar.gz")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", sum)
+	sum, err = releaseChecksum(checksums, "gitleaks_8.18.0_windows_x64.zip")
+	require.NoError(t, err)
+	assert.Equal(t, "def456", sum)
+	_, err = releaseChecksum(checksums, "gitleaks_8.18.0_linux_x64")
+	assert.Error(t, err)
+}
+
+func TestExtractBinary(t *testing.T) {
+	tests := map[string]struct {
+		archive string
+		content []byte
+		binary  string
+		err     string
+	}{
+		"tar.gz in a directory": {
+			archive: "gitleaks.tar.gz",
+			content: tarGz(t, map[string]string{"gitleaks_8.18.0/gitleaks": "binary"}),
+			binary:  "binary",
+		},
+		"tar.gz with ../": {
+			archive: "gitleaks.tar.gz",
+			content: tarGz(t, map[string]string{"../../usr/local/bin/gitleaks": "binary"}),
+			err:     "contains an entry outside of the archive: ../../usr/local/bin/gitleaks",
+		},
+		"tar.gz with ../ besides the binary": {
+			archive: "gitleaks.tar.gz",
+			content: tarGz(t, map[string]string{"gitleaks": "binary", "docs/../../.bashrc": "evil"}),
+			err:     "contains an entry outside of the archive: docs/../../.bashrc",
+		},
+		"tar.gz with an absolute path": {
+			archive: "gitleaks.tar.gz",
+			content: tarGz(t, map[string]string{"/usr/local/bin/gitleaks": "binary"}),
+			err:     "contains an entry outside of the archive: /usr/local/bin/gitleaks",
+		},
+		"tar.gz without the binary": {
+			archive: "gitleaks.tar.gz",
+			content: tarGz(t, map[string]string{"README.md": ""}),
+			err:     "gitleaks.tar.gz does not contain gitleaks",
+		},
+		"zip": {
+			archive: "gitleaks.zip",
+			content: zipArchive(t, map[string]string{"gitleaks": "binary"}),
+			binary:  "binary",
+		},
+		"zip with ../": {
+			archive: "gitleaks.zip",
+			content: zipArchive(t, map[string]string{`..\gitleaks`: "binary"}),
+			err:     `contains an entry outside of the archive: ..\gitleaks`,
+		},
+	}
+	for name, tt := range tests {
+		binary, err := extractBinary(tt.archive, tt.content, "gitleaks")
+		if tt.err != "" {
+			require.Error(t, err, name)
+			assert.Contains(t, err.Error(), tt.err, name)
+			continue
+		}
+		require.NoError(t, err, name)
+		assert.Equal(t, tt.binary, string(binary), name)
+	}
+}
+
+func TestReleaseArchive(t *testing.T) {
+	// goreleaser replaces amd64 with x64 and 386 with x32 in the archive
+	// names, and appends the ARM version to arm
+	tests := map[[3]string]string{
+		{"linux", "amd64", ""}:   "gitleaks_8.18.0_linux_x64.tar.gz",
+		{"linux", "386", ""}:     "gitleaks_8.18.0_linux_x32.tar.gz",
+		{"linux", "arm64", ""}:   "gitleaks_8.18.0_linux_arm64.tar.gz",
+		{"linux", "arm", "6"}:    "gitleaks_8.18.0_linux_armv6.tar.gz",
+		{"linux", "arm", "7"}:    "gitleaks_8.18.0_linux_armv7.tar.gz",
+		{"darwin", "amd64", ""}:  "gitleaks_8.18.0_darwin_x64.tar.gz",
+		{"darwin", "arm64", ""}:  "gitleaks_8.18.0_darwin_arm64.tar.gz",
+		{"windows", "amd64", ""}: "gitleaks_8.18.0_windows_x64.zip",
+		{"windows", "386", ""}:   "gitleaks_8.18.0_windows_x32.zip",
+		{"windows", "arm64", ""}: "gitleaks_8.18.0_windows_arm64.zip",
+		{"windows", "arm", "7"}:  "gitleaks_8.18.0_windows_armv7.zip",
+	}
+	for platform, expected := range tests {
+		archive, err := releaseArchive("8.18.0", platform[0], platform[1], platform[2])
+		require.NoError(t, err, platform)
+		assert.Equal(t, expected, archive)
+	}
+
+	for _, platform := range [][3]string{{"linux", "arm", "5"}, {"linux", "riscv64", ""}, {"darwin", "386", ""}, {"freebsd", "amd64", ""}} {
+		_, err := releaseArchive("8.18.0", platform[0], platform[1], platform[2])
+		assert.Error(t, err, platform)
+	}
+}
+
+func TestLatestRelease(t *testing.T) {
+	releases := `[
+		{"tag_name": "v8.19.0-rc1", "prerelease": true},
+		{"tag_name": "v8.19.0", "draft": true},
+		{"tag_name": "v8.18.1"},
+		{"tag_name": "v8.18.0"}
+	]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, releases)
+	}))
+	defer server.Close()
+	defer func(url string) { releaseAPIURL = url }(releaseAPIURL)
+	releaseAPIURL = server.URL
+
+	tag, err := latestRelease()
+	require.NoError(t, err)
+	assert.Equal(t, "v8.18.1", tag)
+
+	releases = `[{"tag_name": "v8.19.0-rc1", "prerelease": true}]`
+	_, err = latestRelease()
+	assert.Error(t, err)
+}
+
+func TestReplaceExecutable(t *testing.T) {
+	dir := t.TempDir()
+	executable := filepath.Join(dir, "gitleaks")
+	require.NoError(t, os.WriteFile(executable, []byte("old"), 0o755))
+
+	require.NoError(t, replaceExecutable(executable, []byte("new")))
+	content, err := os.ReadFile(executable)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(content))
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(executable)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+	}
+
+	// the temporary file is renamed or removed
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if runtime.GOOS == "windows" {
+		assert.ElementsMatch(t, []string{"gitleaks", "gitleaks.old"}, names)
+	} else {
+		assert.Equal(t, []string{"gitleaks"}, names)
+	}
+
+	assert.Error(t, replaceExecutable(filepath.Join(dir, "missing", "gitleaks"), []byte("new")))
+}
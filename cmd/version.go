@@ -8,8 +8,12 @@ import (
 
 var Version = "version is set by build process"
 
+// Commit is the git commit gitleaks was built from, set by the build process.
+var Commit = ""
+
 func init() {
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.Version = Version
 }
 
 var versionCmd = &cobra.Command{
@@ -19,5 +23,9 @@ var versionCmd = &cobra.Command{
 }
 
 func runVersion(cmd *cobra.Command, args []string) {
+	if Commit != "" {
+		fmt.Printf("%s (%s)\n", Version, Commit)
+		return
+	}
 	fmt.Println(Version)
 }
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+	"github.com/zricethezav/gitleaks/v8/sources"
+)
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().Duration("debounce", 300*time.Millisecond, "how long to wait for a file to stop changing before scanning it")
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "watch a directory and scan files as they change",
+	Run:   runWatch,
+}
+
+func runWatch(cmd *cobra.Command, args []string) {
+	initConfig()
+
+	// setup config (aka, the thing that defines rules)
+	cfg := Config(cmd)
+
+	source, err := cmd.Flags().GetString("source")
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	debounce, err := cmd.Flags().GetDuration("debounce")
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	detector := Detector(cmd, cfg, source)
+	defer closeDetector(detector)
+	// findings are only useful in watch mode if they are printed
+	detector.Verbose = true
+
+	// reload the config when it changes so rules can be tuned without
+	// restarting the watcher
+	if cfgPath := viper.ConfigFileUsed(); cfgPath != "" {
+		configWatcher, err := config.Watch(cfgPath, reloadConfig(cmd, detector))
+		if err != nil {
+			log.Warn().Err(err).Msgf("unable to watch config %s for changes", cfgPath)
+		} else {
+			defer configWatcher.Close()
+		}
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not create file watcher")
+	}
+	defer watcher.Close()
+	if err := watchDirs(watcher, source); err != nil {
+		log.Fatal().Err(err).Msg("could not watch source")
+	}
+	log.Info().Msgf("watching %s for changes", source)
+
+	var (
+		mu      sync.Mutex
+		pending = make(map[string]*time.Timer)
+	)
+	scan := func(path string) {
+		mu.Lock()
+		delete(pending, path)
+		mu.Unlock()
+		findings, err := detector.DetectFile(sources.ScanTarget{Path: path})
+		if err != nil {
+			log.Debug().Err(err).Msgf("unable to scan %s", path)
+			return
+		}
+		if len(findings) != 0 {
+			log.Warn().Msgf("%s: leaks found: %d", path, len(findings))
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			info, err := os.Stat(event.Name)
+			if err != nil {
+				continue
+			}
+			if info.IsDir() {
+				// start watching newly created directories
+				if err := watchDirs(watcher, event.Name); err != nil {
+					log.Debug().Err(err).Msgf("unable to watch %s", event.Name)
+				}
+				continue
+			}
+			if !info.Mode().IsRegular() || info.Size() == 0 {
+				continue
+			}
+
+			// debounce writes so a file is only scanned once it has
+			// stopped changing
+			path := event.Name
+			mu.Lock()
+			if timer, ok := pending[path]; ok {
+				timer.Stop()
+			}
+			pending[path] = time.AfterFunc(debounce, func() { scan(path) })
+			mu.Unlock()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Error().Err(err).Msg("file watcher")
+		}
+	}
+}
+
+// watchDirs adds root and all of its subdirectories to the watcher, skipping
+// .git directories.
+func watchDirs(watcher *fsnotify.Watcher, root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
@@ -0,0 +1,48 @@
+package config
+
+// Action is a command or webhook that is invoked for every finding matching
+// the action's rules or tags. Actions can be used to trigger credential
+// rotation, e.g. deactivating an AWS key or rotating a Vault secret.
+type Action struct {
+	// Description is a short human readable description of the action.
+	Description string
+
+	// RuleIDs and Tags select which findings trigger the action. An action
+	// without rule ids or tags is triggered by every finding.
+	RuleIDs []string
+
+	Tags []string
+
+	// Command is executed with the finding encoded as JSON on stdin.
+	Command []string
+
+	// Webhook is a URL the finding is POSTed to as JSON.
+	Webhook string
+}
+
+// Matches returns true if a finding with the rule id and tags should
+// trigger the action.
+func (a *Action) Matches(ruleID string, tags []string) bool {
+	return matchesRuleOrTag(a.RuleIDs, a.Tags, ruleID, tags)
+}
+
+// matchesRuleOrTag returns true if ruleID is one of ruleIDs or one of tags is
+// one of wantTags. Empty selectors match everything.
+func matchesRuleOrTag(ruleIDs []string, wantTags []string, ruleID string, tags []string) bool {
+	if len(ruleIDs) == 0 && len(wantTags) == 0 {
+		return true
+	}
+	for _, id := range ruleIDs {
+		if id == ruleID {
+			return true
+		}
+	}
+	for _, t := range wantTags {
+		for _, tag := range tags {
+			if t == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -1,8 +1,13 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"regexp"
 	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
 )
 
 // Allowlist allows a rule to be ignored for specific
@@ -27,6 +32,33 @@ type Allowlist struct {
 	// This targets the _secret_, not the content of the regex match like the
 	// Regexes slice.
 	StopWords []string
+
+	// SecretHashes is a slice of hex encoded SHA-256 hashes of secrets that
+	// are allowed to be ignored. This lets known dummy credentials be
+	// ignored without storing their plaintext in the config.
+	SecretHashes []string
+
+	// Until is the date after which the allowlist no longer applies, so
+	// temporary suppressions don't silently become permanent.
+	Until time.Time
+}
+
+// Expired returns true if the allowlist has an expiration date in the past.
+func (a *Allowlist) Expired() bool {
+	return ExpiredAt(a.Until, now())
+}
+
+// expireAllowlist returns an empty allowlist and logs a warning if the
+// allowlist has expired.
+func expireAllowlist(name string, a Allowlist) Allowlist {
+	if !a.Expired() {
+		return a
+	}
+	log.Warn().Msgf("%s allowlist expired on %s and is no longer applied", name, a.Until.Format(UntilDateFormat))
+	return Allowlist{
+		Description: a.Description,
+		Until:       a.Until,
+	}
 }
 
 // CommitAllowed returns true if the commit is allowed to be ignored.
@@ -42,9 +74,17 @@ func (a *Allowlist) CommitAllowed(c string) bool {
 	return false
 }
 
-// PathAllowed returns true if the path is allowed to be ignored.
+// PathAllowed returns true if the path is allowed to be ignored. Paths are
+// matched using forward slashes so the same allowlist works on Windows.
 func (a *Allowlist) PathAllowed(path string) bool {
-	return anyRegexMatch(path, a.Paths)
+	return anyRegexMatch(SlashPath(path), a.Paths)
+}
+
+// SlashPath replaces the backslashes in a windows path with forward slashes.
+// Unlike filepath.ToSlash it does so on every platform, so paths from reports
+// and .gitleaksignore files written on Windows match anywhere.
+func SlashPath(path string) string {
+	return strings.ReplaceAll(path, `\`, "/")
 }
 
 // RegexAllowed returns true if the regex is allowed to be ignored.
@@ -61,3 +101,19 @@ func (a *Allowlist) ContainsStopWord(s string) bool {
 	}
 	return false
 }
+
+// SecretHashAllowed returns true if the SHA-256 hash of the secret is allowed
+// to be ignored.
+func (a *Allowlist) SecretHashAllowed(secret string) bool {
+	if len(a.SecretHashes) == 0 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(secret))
+	hash := hex.EncodeToString(sum[:])
+	for _, h := range a.SecretHashes {
+		if strings.EqualFold(h, hash) {
+			return true
+		}
+	}
+	return false
+}
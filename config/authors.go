@@ -0,0 +1,34 @@
+package config
+
+import "strings"
+
+// Authors restricts who commits in the scanned repositories may be authored
+// by, ex: no personal email addresses in company repositories.
+type Authors struct {
+	// AllowedDomains are the email domains commits may be authored from,
+	// subdomains included. No domains allows every author.
+	AllowedDomains []string
+}
+
+// IsZero returns true if no restrictions are configured.
+func (a *Authors) IsZero() bool {
+	return len(a.AllowedDomains) == 0
+}
+
+// Allowed returns true if a commit may be authored by email.
+func (a *Authors) Allowed(email string) bool {
+	if a.IsZero() {
+		return true
+	}
+	_, domain, ok := strings.Cut(strings.ToLower(strings.TrimSpace(email)), "@")
+	if !ok {
+		return false
+	}
+	for _, allowed := range a.AllowedDomains {
+		allowed = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(allowed), "@"))
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorsAllowed(t *testing.T) {
+	assert.True(t, (&Authors{}).Allowed("someone@gmail.com"))
+
+	authors := Authors{AllowedDomains: []string{"acme.com", "@users.noreply.github.com"}}
+	tests := map[string]bool{
+		"dev@acme.com":                      true,
+		"Dev@ACME.com":                      true,
+		"dev@eng.acme.com":                  true,
+		"1234+dev@users.noreply.github.com": true,
+		"dev@gmail.com":                     false,
+		"dev@notacme.com":                   false,
+		"dev@acme.com.evil.example":         false,
+		"dev":                               false,
+		"":                                  false,
+	}
+	for email, allowed := range tests {
+		assert.Equal(t, allowed, authors.Allowed(email), email)
+	}
+}
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"bytes"
 	_ "embed"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
@@ -35,32 +38,76 @@ type ViperConfig struct {
 		Keywords    []string
 		Path        string
 		Tags        []string
+		Severity    string
+		Validate    string
+
+		CaseInsensitive bool
+		Multiline       bool
+		DotAll          bool
 
 		Allowlist struct {
-			RegexTarget string
-			Regexes     []string
-			Paths       []string
-			Commits     []string
-			StopWords   []string
+			RegexTarget  string
+			Regexes      []string
+			Paths        []string
+			Commits      []string
+			StopWords    []string
+			SecretHashes []string
+			Until        string
 		}
 	}
 	Allowlist struct {
-		RegexTarget string
-		Regexes     []string
-		Paths       []string
-		Commits     []string
-		StopWords   []string
+		RegexTarget  string
+		Regexes      []string
+		Paths        []string
+		Commits      []string
+		StopWords    []string
+		SecretHashes []string
+		Until        string
+	}
+	Targets Targets
+	Authors Authors
+	Actions []struct {
+		Description string
+		Rules       []string
+		Tags        []string
+		Command     []string
+		Webhook     string
+	}
+	Policies []struct {
+		Description string
+		Severity    string
+		Rules       []string
+		Tags        []string
+		MaxFindings int
+		PerRule     bool
+		Action      string
+	}
+	Notifications []struct {
+		Description string
+		Severity    string
+		Rules       []string
+		Tags        []string
+		Slack       string
+		Email       []string
+		SMTP        string
+		From        string
+		PagerDuty   string
 	}
 }
 
 // Config is a configuration struct that contains rules and an allowlist if present.
 type Config struct {
-	Extend      Extend
-	Path        string
-	Description string
-	Rules       map[string]Rule
-	Allowlist   Allowlist
-	Keywords    []string
+	Extend        Extend
+	Path          string
+	Description   string
+	Rules         map[string]Rule
+	Allowlist     Allowlist
+	Keywords      []string
+	Actions       []Action
+	Policies      []Policy
+	Notifications []Notification
+	Targets       Targets
+	Authors       Authors
 
 	// used to keep sarif results consistent
 	OrderedRules []string
@@ -72,6 +119,12 @@ type Extend struct {
 	Path       string
 	URL        string
 	UseDefault bool
+
+	// Checksum pins the config at URL to a SHA-256 hash, "sha256:<hex>".
+	Checksum string
+	// PublicKey is a base64 encoded ed25519 key the signature at URL+".sig"
+	// of the config at URL must verify with.
+	PublicKey string
 }
 
 func (vc *ViperConfig) Translate() (Config, error) {
@@ -82,13 +135,13 @@ func (vc *ViperConfig) Translate() (Config, error) {
 	rulesMap := make(map[string]Rule)
 
 	for _, r := range vc.Rules {
-		var allowlistRegexes []*regexp.Regexp
-		for _, a := range r.Allowlist.Regexes {
-			allowlistRegexes = append(allowlistRegexes, regexp.MustCompile(a))
+		allowlistRegexes, err := compileAllowlist(r.ID, r.Allowlist.Regexes)
+		if err != nil {
+			return Config{}, err
 		}
-		var allowlistPaths []*regexp.Regexp
-		for _, a := range r.Allowlist.Paths {
-			allowlistPaths = append(allowlistPaths, regexp.MustCompile(a))
+		allowlistPaths, err := compileAllowlist(r.ID, r.Allowlist.Paths)
+		if err != nil {
+			return Config{}, err
 		}
 
 		if r.Keywords == nil {
@@ -103,12 +156,25 @@ func (vc *ViperConfig) Translate() (Config, error) {
 			r.Tags = []string{}
 		}
 
+		allowlistUntil, err := ParseUntil(r.Allowlist.Until)
+		if err != nil {
+			return Config{}, fmt.Errorf("%s invalid allowlist until: %w", r.ID, err)
+		}
+
+		regex, err := ruleRegex(r.Regex, RegexOptions{
+			CaseInsensitive: r.CaseInsensitive,
+			Multiline:       r.Multiline,
+			DotAll:          r.DotAll,
+		})
+		if err != nil {
+			return Config{}, fmt.Errorf("%s %w", r.ID, err)
+		}
 		var configRegex *regexp.Regexp
 		var configPathRegex *regexp.Regexp
-		if r.Regex == "" {
+		if regex == "" {
 			configRegex = nil
 		} else {
-			configRegex = regexp.MustCompile(r.Regex)
+			configRegex = regexp.MustCompile(regex)
 		}
 		if r.Path == "" {
 			configPathRegex = nil
@@ -123,54 +189,144 @@ func (vc *ViperConfig) Translate() (Config, error) {
 			SecretGroup: r.SecretGroup,
 			Entropy:     r.Entropy,
 			Tags:        r.Tags,
+			Severity:    strings.ToLower(r.Severity),
+			Validate:    strings.ToLower(r.Validate),
 			Keywords:    r.Keywords,
 			Allowlist: Allowlist{
-				RegexTarget: r.Allowlist.RegexTarget,
-				Regexes:     allowlistRegexes,
-				Paths:       allowlistPaths,
-				Commits:     r.Allowlist.Commits,
-				StopWords:   r.Allowlist.StopWords,
+				RegexTarget:  r.Allowlist.RegexTarget,
+				Regexes:      allowlistRegexes,
+				Paths:        allowlistPaths,
+				Commits:      r.Allowlist.Commits,
+				StopWords:    r.Allowlist.StopWords,
+				SecretHashes: r.Allowlist.SecretHashes,
+				Until:        allowlistUntil,
 			},
 		}
+		r.Allowlist = expireAllowlist(r.RuleID, r.Allowlist)
 		orderedRules = append(orderedRules, r.RuleID)
 
 		if r.Regex != nil && r.SecretGroup > r.Regex.NumSubexp() {
 			return Config{}, fmt.Errorf("%s invalid regex secret group %d, max regex secret group %d", r.Description, r.SecretGroup, r.Regex.NumSubexp())
 		}
+		if r.Severity != "" && !validSeverity(r.Severity) {
+			return Config{}, fmt.Errorf("%s invalid severity %q, must be one of low, medium, high, critical", r.RuleID, r.Severity)
+		}
+		if r.Validate != "" && !validValidator(r.Validate) {
+			return Config{}, fmt.Errorf("%s invalid validate %q, must be one of jwt, luhn, base64, aws-key-id, aws-secret-key, basic-auth", r.RuleID, r.Validate)
+		}
 		rulesMap[r.RuleID] = r
 	}
-	var allowlistRegexes []*regexp.Regexp
-	for _, a := range vc.Allowlist.Regexes {
-		allowlistRegexes = append(allowlistRegexes, regexp.MustCompile(a))
+	allowlistRegexes, err := compileAllowlist("global", vc.Allowlist.Regexes)
+	if err != nil {
+		return Config{}, err
+	}
+	allowlistPaths, err := compileAllowlist("global", vc.Allowlist.Paths)
+	if err != nil {
+		return Config{}, err
+	}
+	allowlistUntil, err := ParseUntil(vc.Allowlist.Until)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid allowlist until: %w", err)
+	}
+	var actions []Action
+	for _, a := range vc.Actions {
+		if len(a.Command) == 0 && a.Webhook == "" {
+			return Config{}, fmt.Errorf("action %q must set a command or a webhook", a.Description)
+		}
+		actions = append(actions, Action{
+			Description: a.Description,
+			RuleIDs:     a.Rules,
+			Tags:        a.Tags,
+			Command:     a.Command,
+			Webhook:     a.Webhook,
+		})
+	}
+	var policies []Policy
+	for _, p := range vc.Policies {
+		policy := Policy{
+			Description: p.Description,
+			Severity:    strings.ToLower(p.Severity),
+			RuleIDs:     p.Rules,
+			Tags:        p.Tags,
+			MaxFindings: p.MaxFindings,
+			PerRule:     p.PerRule,
+			Action:      strings.ToLower(p.Action),
+		}
+		if policy.Action == "" {
+			policy.Action = PolicyFail
+		}
+		if policy.Action != PolicyFail && policy.Action != PolicyWarn {
+			return Config{}, fmt.Errorf("policy %q invalid action %q, must be fail or warn", p.Description, p.Action)
+		}
+		if policy.Severity != "" && !validSeverity(policy.Severity) {
+			return Config{}, fmt.Errorf("policy %q invalid severity %q, must be one of low, medium, high, critical", p.Description, p.Severity)
+		}
+		if policy.MaxFindings < 0 {
+			return Config{}, fmt.Errorf("policy %q maxFindings must not be negative", p.Description)
+		}
+		policies = append(policies, policy)
 	}
-	var allowlistPaths []*regexp.Regexp
-	for _, a := range vc.Allowlist.Paths {
-		allowlistPaths = append(allowlistPaths, regexp.MustCompile(a))
+	var notifications []Notification
+	for _, n := range vc.Notifications {
+		notification := Notification{
+			Description: n.Description,
+			Severity:    strings.ToLower(n.Severity),
+			RuleIDs:     n.Rules,
+			Tags:        n.Tags,
+			Slack:       n.Slack,
+			Email:       n.Email,
+			SMTP:        n.SMTP,
+			From:        n.From,
+			PagerDuty:   n.PagerDuty,
+		}
+		if notification.Slack == "" && len(notification.Email) == 0 && notification.PagerDuty == "" {
+			return Config{}, fmt.Errorf("notification %q must set slack, email or pagerduty", n.Description)
+		}
+		if len(notification.Email) > 0 && (notification.SMTP == "" || notification.From == "") {
+			return Config{}, fmt.Errorf("notification %q must set smtp and from to send email", n.Description)
+		}
+		if notification.Severity != "" && !validSeverity(notification.Severity) {
+			return Config{}, fmt.Errorf("notification %q invalid severity %q, must be one of low, medium, high, critical", n.Description, n.Severity)
+		}
+		notifications = append(notifications, notification)
 	}
 	c := Config{
 		Description: vc.Description,
 		Extend:      vc.Extend,
 		Rules:       rulesMap,
 		Allowlist: Allowlist{
-			RegexTarget: vc.Allowlist.RegexTarget,
-			Regexes:     allowlistRegexes,
-			Paths:       allowlistPaths,
-			Commits:     vc.Allowlist.Commits,
-			StopWords:   vc.Allowlist.StopWords,
+			RegexTarget:  vc.Allowlist.RegexTarget,
+			Regexes:      allowlistRegexes,
+			Paths:        allowlistPaths,
+			Commits:      vc.Allowlist.Commits,
+			StopWords:    vc.Allowlist.StopWords,
+			SecretHashes: vc.Allowlist.SecretHashes,
+			Until:        allowlistUntil,
 		},
-		Keywords:     keywords,
-		OrderedRules: orderedRules,
+		Keywords:      keywords,
+		Actions:       actions,
+		Policies:      policies,
+		Notifications: notifications,
+		Targets:       vc.Targets,
+		Authors:       vc.Authors,
+		OrderedRules:  orderedRules,
 	}
+	c.Allowlist = expireAllowlist("global", c.Allowlist)
 
 	if maxExtendDepth != extendDepth {
-		// disallow both usedefault and path from being set
+		// disallow more than one of usedefault, path and url from being set
 		if c.Extend.Path != "" && c.Extend.UseDefault {
 			log.Fatal().Msg("unable to load config due to extend.path and extend.useDefault being set")
 		}
+		if c.Extend.URL != "" && (c.Extend.Path != "" || c.Extend.UseDefault) {
+			log.Fatal().Msg("unable to load config due to extend.url and extend.path or extend.useDefault being set")
+		}
 		if c.Extend.UseDefault {
 			c.extendDefault()
 		} else if c.Extend.Path != "" {
 			c.extendPath()
+		} else if c.Extend.URL != "" {
+			c.extendURL()
 		}
 
 	}
@@ -232,7 +388,34 @@ func (c *Config) extendPath() {
 }
 
 func (c *Config) extendURL() {
-	// TODO
+	extendDepth++
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		log.Fatal().Msgf("failed to load extended config, err: %s", err)
+		return
+	}
+	content, err := fetchRuleset(c.Extend, filepath.Join(cacheDir, "gitleaks", "rules"))
+	if err != nil {
+		log.Fatal().Msgf("failed to load extended config, err: %s", err)
+		return
+	}
+	viper.SetConfigType("toml")
+	if err := viper.ReadConfig(bytes.NewReader(content)); err != nil {
+		log.Fatal().Msgf("failed to load extended config, err: %s", err)
+		return
+	}
+	extensionViperConfig := ViperConfig{}
+	if err := viper.Unmarshal(&extensionViperConfig); err != nil {
+		log.Fatal().Msgf("failed to load extended config, err: %s", err)
+		return
+	}
+	cfg, err := extensionViperConfig.Translate()
+	if err != nil {
+		log.Fatal().Msgf("failed to load extended config, err: %s", err)
+		return
+	}
+	log.Debug().Msgf("extending config with %s", c.Extend.URL)
+	c.extend(cfg)
 }
 
 func (c *Config) extend(extensionConfig Config) {
@@ -252,6 +435,20 @@ func (c *Config) extend(extensionConfig Config) {
 		extensionConfig.Allowlist.Paths...)
 	c.Allowlist.Regexes = append(c.Allowlist.Regexes,
 		extensionConfig.Allowlist.Regexes...)
+	c.Allowlist.SecretHashes = append(c.Allowlist.SecretHashes,
+		extensionConfig.Allowlist.SecretHashes...)
+
+	c.Targets.IncludeExtensions = append(c.Targets.IncludeExtensions,
+		extensionConfig.Targets.IncludeExtensions...)
+	c.Targets.ExcludeExtensions = append(c.Targets.ExcludeExtensions,
+		extensionConfig.Targets.ExcludeExtensions...)
+	c.Targets.IncludeMimeTypes = append(c.Targets.IncludeMimeTypes,
+		extensionConfig.Targets.IncludeMimeTypes...)
+	c.Targets.ExcludeMimeTypes = append(c.Targets.ExcludeMimeTypes,
+		extensionConfig.Targets.ExcludeMimeTypes...)
+
+	c.Authors.AllowedDomains = append(c.Authors.AllowedDomains,
+		extensionConfig.Authors.AllowedDomains...)
 
 	// sort to keep extended rules in order
 	sort.Strings(c.OrderedRules)
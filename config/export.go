@@ -0,0 +1,95 @@
+package config
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"time"
+)
+
+// exportedConfig is the effective config in the form of a config file.
+type exportedConfig struct {
+	Description string            `json:"description,omitempty"`
+	Rules       []exportedRule    `json:"rules"`
+	Allowlist   exportedAllowlist `json:"allowlist"`
+	Targets     Targets           `json:"targets"`
+	Authors     Authors           `json:"authors"`
+}
+
+type exportedRule struct {
+	ID          string            `json:"id"`
+	Description string            `json:"description,omitempty"`
+	Regex       string            `json:"regex,omitempty"`
+	SecretGroup int               `json:"secretGroup,omitempty"`
+	Entropy     float64           `json:"entropy,omitempty"`
+	Path        string            `json:"path,omitempty"`
+	Keywords    []string          `json:"keywords,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Severity    string            `json:"severity,omitempty"`
+	Validate    string            `json:"validate,omitempty"`
+	Allowlist   exportedAllowlist `json:"allowlist"`
+}
+
+type exportedAllowlist struct {
+	RegexTarget  string   `json:"regexTarget,omitempty"`
+	Regexes      []string `json:"regexes,omitempty"`
+	Paths        []string `json:"paths,omitempty"`
+	Commits      []string `json:"commits,omitempty"`
+	StopWords    []string `json:"stopWords,omitempty"`
+	SecretHashes []string `json:"secretHashes,omitempty"`
+	Until        string   `json:"until,omitempty"`
+}
+
+// Export writes the rules, allowlists, targets and authors of the effective
+// config, everything its hash covers, as a JSON config file that --config
+// can load. Extended configs are already merged into it.
+func (c *Config) Export(w io.Writer) error {
+	exported := exportedConfig{
+		Description: c.Description,
+		Rules:       []exportedRule{},
+		Allowlist:   exportAllowlist(c.Allowlist),
+		Targets:     c.Targets,
+		Authors:     c.Authors,
+	}
+	for _, r := range c.GetOrderedRules() {
+		exported.Rules = append(exported.Rules, exportedRule{
+			ID:          r.RuleID,
+			Description: r.Description,
+			Regex:       regexString(r.Regex),
+			SecretGroup: r.SecretGroup,
+			Entropy:     r.Entropy,
+			Path:        regexString(r.Path),
+			Keywords:    r.Keywords,
+			Tags:        r.Tags,
+			Severity:    r.Severity,
+			Validate:    r.Validate,
+			Allowlist:   exportAllowlist(r.Allowlist),
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", " ")
+	return encoder.Encode(exported)
+}
+
+func exportAllowlist(a Allowlist) exportedAllowlist {
+	exported := exportedAllowlist{
+		RegexTarget:  a.RegexTarget,
+		Regexes:      regexStrings(a.Regexes),
+		Paths:        regexStrings(a.Paths),
+		Commits:      a.Commits,
+		StopWords:    a.StopWords,
+		SecretHashes: a.SecretHashes,
+	}
+	if !a.Until.IsZero() {
+		exported.Until = a.Until.Format(time.RFC3339Nano)
+	}
+	return exported
+}
+
+func regexStrings(res []*regexp.Regexp) []string {
+	var s []string
+	for _, re := range res {
+		s = append(s, re.String())
+	}
+	return s
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport(t *testing.T) {
+	load := func(configType string, content string) Config {
+		viper.Reset()
+		viper.SetConfigType(configType)
+		require.NoError(t, viper.ReadConfig(strings.NewReader(content)))
+		var vc ViperConfig
+		require.NoError(t, viper.Unmarshal(&vc))
+		cfg, err := vc.Translate()
+		require.NoError(t, err)
+		return cfg
+	}
+
+	cfg := load("toml", DefaultConfig)
+	cfg.Allowlist.StopWords = append(cfg.Allowlist.StopWords, "example")
+	cfg.Targets.ExcludeExtensions = []string{".lock"}
+	cfg.Authors.AllowedDomains = []string{"acme.com"}
+	var buf bytes.Buffer
+	require.NoError(t, cfg.Export(&buf))
+
+	exported := load("json", buf.String())
+	assert.Equal(t, cfg.Hash(), exported.Hash())
+	assert.Equal(t, cfg.OrderedRules, exported.OrderedRules)
+}
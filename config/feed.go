@@ -0,0 +1,149 @@
+package config
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// rulesetCacheTTL is how long a fetched ruleset is used before it is fetched
+// again, so pipelines don't fetch it on every scan.
+const rulesetCacheTTL = time.Hour
+
+// maxRulesetSize limits the size of a fetched ruleset.
+const maxRulesetSize = 10 << 20
+
+var rulesetClient = &http.Client{Timeout: 30 * time.Second}
+
+// fetchRuleset returns the config at extend.URL, verified against the pinned
+// checksum or the signature at extend.URL+".sig". Verified rulesets are cached
+// in cacheDir and used for an hour. When the ruleset can't be fetched, a
+// stale cached copy is used so scans keep working while the feed is down.
+func fetchRuleset(extend Extend, cacheDir string) ([]byte, error) {
+	if extend.Checksum == "" && extend.PublicKey == "" {
+		return nil, fmt.Errorf("extend.url %s requires a checksum or a publicKey to verify it", extend.URL)
+	}
+	sum := sha256.Sum256([]byte(extend.URL))
+	cachePath := filepath.Join(cacheDir, hex.EncodeToString(sum[:])+".toml")
+
+	cached, cacheErr := readCachedRuleset(extend, cachePath)
+	if cacheErr == nil {
+		if info, err := os.Stat(cachePath); err == nil && time.Since(info.ModTime()) < rulesetCacheTTL {
+			return cached, nil
+		}
+	}
+
+	content, signature, err := downloadRuleset(extend)
+	if err == nil {
+		err = verifyRuleset(extend, content, signature)
+	}
+	if err != nil {
+		if cacheErr == nil {
+			log.Warn().Err(err).Msgf("could not fetch %s, using the copy cached at %s", extend.URL, cachePath)
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	if err := os.MkdirAll(cacheDir, 0o700); err != nil {
+		log.Warn().Err(err).Msg("could not cache ruleset")
+		return content, nil
+	}
+	if err := os.WriteFile(cachePath, content, 0o600); err != nil {
+		log.Warn().Err(err).Msg("could not cache ruleset")
+	} else if signature != nil {
+		if err := os.WriteFile(cachePath+".sig", signature, 0o600); err != nil {
+			log.Warn().Err(err).Msg("could not cache ruleset signature")
+		}
+	}
+	return content, nil
+}
+
+// readCachedRuleset returns the cached copy of the ruleset if it still
+// verifies, the checksum or key may have changed since it was cached.
+func readCachedRuleset(extend Extend, cachePath string) ([]byte, error) {
+	content, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil, err
+	}
+	var signature []byte
+	if extend.PublicKey != "" {
+		if signature, err = os.ReadFile(cachePath + ".sig"); err != nil {
+			return nil, err
+		}
+	}
+	if err := verifyRuleset(extend, content, signature); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// downloadRuleset fetches the ruleset at extend.URL and, when a public key
+// is set, its signature.
+func downloadRuleset(extend Extend) ([]byte, []byte, error) {
+	content, err := download(extend.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if extend.PublicKey == "" {
+		return content, nil, nil
+	}
+	signature, err := download(extend.URL + ".sig")
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, signature, nil
+}
+
+func download(url string) ([]byte, error) {
+	resp, err := rulesetClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", url, resp.Status)
+	}
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxRulesetSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", url, err)
+	}
+	if len(content) > maxRulesetSize {
+		return nil, fmt.Errorf("fetching %s: larger than %d bytes", url, maxRulesetSize)
+	}
+	return content, nil
+}
+
+// verifyRuleset checks content against the pinned checksum and the base64
+// encoded ed25519 signature, whichever are set.
+func verifyRuleset(extend Extend, content []byte, signature []byte) error {
+	if extend.Checksum != "" {
+		sum := sha256.Sum256(content)
+		want := strings.ToLower(strings.TrimPrefix(extend.Checksum, "sha256:"))
+		if got := hex.EncodeToString(sum[:]); got != want {
+			return fmt.Errorf("checksum of %s is sha256:%s, expected %s", extend.URL, got, extend.Checksum)
+		}
+	}
+	if extend.PublicKey != "" {
+		key, err := base64.StdEncoding.DecodeString(extend.PublicKey)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return fmt.Errorf("extend.publicKey must be a base64 encoded ed25519 public key")
+		}
+		sig, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature)))
+		if err != nil || !ed25519.Verify(key, content, sig) {
+			return fmt.Errorf("signature of %s does not verify", extend.URL)
+		}
+	}
+	return nil
+}
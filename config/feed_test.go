@@ -0,0 +1,94 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const feedRuleset = `
+[[rules]]
+id = "acme-token"
+regex = '''acme_[a-z0-9]{32}'''
+keywords = ["acme_"]
+`
+
+func TestFetchRuleset(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	ruleset := []byte(feedRuleset)
+	signature := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(private, ruleset)) + "\n")
+	sum := sha256.Sum256(ruleset)
+	checksum := "sha256:" + hex.EncodeToString(sum[:])
+
+	requests := 0
+	down := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch {
+		case down:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/gitleaks.toml":
+			_, _ = w.Write(ruleset)
+		case r.URL.Path == "/gitleaks.toml.sig":
+			_, _ = w.Write(signature)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	url := server.URL + "/gitleaks.toml"
+
+	_, err = fetchRuleset(Extend{URL: url}, t.TempDir())
+	assert.Error(t, err, "a ruleset that can't be verified")
+
+	content, err := fetchRuleset(Extend{URL: url, Checksum: checksum}, t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, ruleset, content)
+
+	_, err = fetchRuleset(Extend{URL: url, Checksum: "sha256:" + hex.EncodeToString(make([]byte, 32))}, t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), checksum)
+
+	other, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	_, err = fetchRuleset(Extend{URL: url, PublicKey: base64.StdEncoding.EncodeToString(other)}, t.TempDir())
+	assert.Error(t, err, "signed with another key")
+
+	// verified rulesets are cached and used while the feed is down
+	cacheDir := t.TempDir()
+	signed := Extend{URL: url, PublicKey: base64.StdEncoding.EncodeToString(public)}
+	content, err = fetchRuleset(signed, cacheDir)
+	require.NoError(t, err)
+	assert.Equal(t, ruleset, content)
+	requests = 0
+	content, err = fetchRuleset(signed, cacheDir)
+	require.NoError(t, err)
+	assert.Equal(t, ruleset, content)
+	assert.Equal(t, 0, requests)
+
+	cached, err := filepath.Glob(filepath.Join(cacheDir, "*.toml"))
+	require.NoError(t, err)
+	require.Len(t, cached, 1)
+	stale := time.Now().Add(-2 * rulesetCacheTTL)
+	require.NoError(t, os.Chtimes(cached[0], stale, stale))
+	down = true
+	content, err = fetchRuleset(signed, cacheDir)
+	require.NoError(t, err)
+	assert.Equal(t, ruleset, content)
+	assert.Equal(t, 1, requests)
+
+	// a cached copy that no longer verifies is not used
+	_, err = fetchRuleset(Extend{URL: url, Checksum: "sha256:" + hex.EncodeToString(make([]byte, 32))}, cacheDir)
+	assert.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Hash returns a SHA-256 hash of the effective configuration, i.e. the rules
+// and allowlists after any extensions have been applied. Two configs with the
+// same hash detect the same secrets, which makes scan results attributable
+// to a specific version of a ruleset.
+func (c *Config) Hash() string {
+	h := sha256.New()
+	var ids []string
+	for id := range c.Rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		r := c.Rules[id]
+		fmt.Fprintf(h, "rule:%s\x00%s\x00%f\x00%d\x00%s\x00%s\x00%s\n",
+			r.RuleID, regexString(r.Regex), r.Entropy, r.SecretGroup, regexString(r.Path),
+			strings.Join(r.Keywords, ","), r.Severity)
+		if r.Validate != "" {
+			fmt.Fprintf(h, "validate:%s\n", r.Validate)
+		}
+		writeAllowlist(h, r.Allowlist)
+	}
+	writeAllowlist(h, c.Allowlist)
+	if !c.Targets.IsZero() {
+		fmt.Fprintf(h, "targets:%s\x00%s\x00%s\x00%s\n",
+			strings.Join(c.Targets.IncludeExtensions, ","), strings.Join(c.Targets.ExcludeExtensions, ","),
+			strings.Join(c.Targets.IncludeMimeTypes, ","), strings.Join(c.Targets.ExcludeMimeTypes, ","))
+	}
+	if !c.Authors.IsZero() {
+		fmt.Fprintf(h, "authors:%s\n", strings.Join(c.Authors.AllowedDomains, ","))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeAllowlist(w io.Writer, a Allowlist) {
+	fmt.Fprintf(w, "allowlist:%s\x00", a.RegexTarget)
+	for _, re := range a.Regexes {
+		fmt.Fprintf(w, "%s\x00", re.String())
+	}
+	for _, re := range a.Paths {
+		fmt.Fprintf(w, "%s\x00", re.String())
+	}
+	fmt.Fprintf(w, "%s\x00%s\x00%s\n",
+		strings.Join(a.Commits, ","), strings.Join(a.StopWords, ","), strings.Join(a.SecretHashes, ","))
+	if !a.Until.IsZero() {
+		fmt.Fprintf(w, "until:%s\n", a.Until.Format(time.RFC3339))
+	}
+}
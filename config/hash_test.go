@@ -0,0 +1,28 @@
+package config
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHash(t *testing.T) {
+	newConfig := func() Config {
+		return Config{
+			Rules: map[string]Rule{
+				"aws-access-key": {
+					RuleID:   "aws-access-key",
+					Regex:    regexp.MustCompile("(?:A3T[A-Z0-9]|AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16}"),
+					Keywords: []string{"akia"},
+				},
+			},
+		}
+	}
+
+	a, b := newConfig(), newConfig()
+	assert.Equal(t, a.Hash(), b.Hash())
+
+	b.Allowlist.StopWords = []string{"example"}
+	assert.NotEqual(t, a.Hash(), b.Hash())
+}
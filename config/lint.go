@@ -0,0 +1,189 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/lucasjones/reggen"
+)
+
+// Lint issue levels. Errors break scans or silence findings, warnings cost
+// performance or produce duplicate findings.
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// lintSamples is the number of matches generated from the regex of a rule to
+// compare it with the regexes of other rules.
+const lintSamples = 20
+
+// LintIssue is a problem found by Lint.
+type LintIssue struct {
+	Level string
+	// RuleID is empty for issues in the global allowlist.
+	RuleID  string
+	Message string
+}
+
+func (i LintIssue) String() string {
+	if i.RuleID == "" {
+		return fmt.Sprintf("%s: global allowlist: %s", i.Level, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", i.Level, i.RuleID, i.Message)
+}
+
+// lintRule is a rule whose regex compiles, with matches generated from it.
+type lintRule struct {
+	id      string
+	regex   *regexp.Regexp
+	samples []string
+}
+
+// Lint checks the config for duplicate rule IDs, regexes that do not compile,
+// invalid or redundant regex options, rules without keywords, rules whose regex matches a subset of the matches
+// of another rule and allowlist regexes that match everything. It works on
+// the config as written, before Translate which panics on invalid regexes and
+// keeps only the last rule with an ID, and does not load extended configs.
+func (vc *ViperConfig) Lint() []LintIssue {
+	var (
+		issues []LintIssue
+		rules  []lintRule
+		ids    []string
+	)
+	definitions := make(map[string]int)
+	for _, r := range vc.Rules {
+		if definitions[r.ID] == 0 {
+			ids = append(ids, r.ID)
+		}
+		definitions[r.ID]++
+
+		options := RegexOptions{CaseInsensitive: r.CaseInsensitive, Multiline: r.Multiline, DotAll: r.DotAll}
+		regex, err := ruleRegex(r.Regex, options)
+		if err != nil {
+			issues = append(issues, LintIssue{LintError, r.ID, err.Error()})
+		}
+		for _, name := range redundantRegexOptions(r.Regex, options) {
+			issues = append(issues, LintIssue{LintWarning, r.ID, fmt.Sprintf("%s is set and the regex already sets it inline", name)})
+		}
+		if err == nil && regex != "" {
+			re, err := regexp.Compile(regex)
+			if err != nil {
+				issues = append(issues, LintIssue{LintError, r.ID, fmt.Sprintf("regex does not compile: %s", err)})
+			} else {
+				if len(r.Keywords) == 0 {
+					issues = append(issues, LintIssue{LintWarning, r.ID, "no keywords, its regex runs on every fragment"})
+				}
+				rules = appendLintRule(rules, lintRule{id: r.ID, regex: re, samples: sampleMatches(re)})
+			}
+		}
+		if r.Path != "" {
+			if _, err := regexp.Compile(r.Path); err != nil {
+				issues = append(issues, LintIssue{LintError, r.ID, fmt.Sprintf("path does not compile: %s", err)})
+			}
+		}
+		issues = append(issues, lintAllowlist(r.ID, r.Allowlist.Regexes, r.Allowlist.Paths)...)
+	}
+	for _, id := range ids {
+		if definitions[id] > 1 {
+			issues = append(issues, LintIssue{LintError, id, fmt.Sprintf("defined %d times, only the last definition is used", definitions[id])})
+		}
+	}
+	issues = append(issues, lintAllowlist("", vc.Allowlist.Regexes, vc.Allowlist.Paths)...)
+	return append(issues, lintOverlaps(rules)...)
+}
+
+// appendLintRule appends rule to rules, replacing an earlier rule with the
+// same ID like Translate does.
+func appendLintRule(rules []lintRule, rule lintRule) []lintRule {
+	for i, r := range rules {
+		if r.id == rule.id {
+			return append(append(rules[:i:i], rules[i+1:]...), rule)
+		}
+	}
+	return append(rules, rule)
+}
+
+// lintAllowlist reports allowlist regexes and paths that do not compile or
+// that match the empty string, and so every secret or path.
+func lintAllowlist(ruleID string, regexes []string, paths []string) []LintIssue {
+	var issues []LintIssue
+	for _, a := range regexes {
+		a, _, err := SplitUntil(a)
+		if err != nil {
+			issues = append(issues, LintIssue{LintError, ruleID, fmt.Sprintf("allowlist regex %q has an invalid until: %s", a, err)})
+			continue
+		}
+		re, err := regexp.Compile(a)
+		if err != nil {
+			issues = append(issues, LintIssue{LintError, ruleID, fmt.Sprintf("allowlist regex %q does not compile: %s", a, err)})
+		} else if re.MatchString("") {
+			issues = append(issues, LintIssue{LintError, ruleID, fmt.Sprintf("allowlist regex %q matches everything", a)})
+		}
+	}
+	for _, a := range paths {
+		a, _, err := SplitUntil(a)
+		if err != nil {
+			issues = append(issues, LintIssue{LintError, ruleID, fmt.Sprintf("allowlist path %q has an invalid until: %s", a, err)})
+			continue
+		}
+		re, err := regexp.Compile(a)
+		if err != nil {
+			issues = append(issues, LintIssue{LintError, ruleID, fmt.Sprintf("allowlist path %q does not compile: %s", a, err)})
+		} else if re.MatchString("") {
+			issues = append(issues, LintIssue{LintError, ruleID, fmt.Sprintf("allowlist path %q matches every path", a)})
+		}
+	}
+	return issues
+}
+
+// lintOverlaps reports rules with the same regex as an earlier rule and
+// rules whose regex matches a strict subset of what another rule matches.
+// Subsets are found from generated matches: every sample of the subset
+// matches the other regex but not the other way around.
+func lintOverlaps(rules []lintRule) []LintIssue {
+	var issues []LintIssue
+	for i, a := range rules {
+		for _, b := range rules[i+1:] {
+			switch {
+			case a.regex.String() == b.regex.String():
+				issues = append(issues, LintIssue{LintWarning, b.id, fmt.Sprintf("same regex as rule %s", a.id)})
+			case matchesAll(a.regex, b.samples) && !matchesAll(b.regex, a.samples):
+				issues = append(issues, LintIssue{LintWarning, b.id, fmt.Sprintf("regex matches a subset of rule %s", a.id)})
+			case matchesAll(b.regex, a.samples) && !matchesAll(a.regex, b.samples):
+				issues = append(issues, LintIssue{LintWarning, a.id, fmt.Sprintf("regex matches a subset of rule %s", b.id)})
+			}
+		}
+	}
+	return issues
+}
+
+// sampleMatches generates matches of re. Generated strings that re does not
+// match, such as those around word boundaries, are dropped. The seed is fixed
+// so lint results are reproducible.
+func sampleMatches(re *regexp.Regexp) []string {
+	g, err := reggen.NewGenerator(re.String())
+	if err != nil {
+		return nil
+	}
+	g.SetSeed(1)
+	var samples []string
+	for i := 0; i < lintSamples; i++ {
+		if s := g.Generate(10); re.MatchString(s) {
+			samples = append(samples, s)
+		}
+	}
+	return samples
+}
+
+func matchesAll(re *regexp.Regexp, samples []string) bool {
+	if len(samples) == 0 {
+		return false
+	}
+	for _, s := range samples {
+		if !re.MatchString(s) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lintConfig = `
+[[rules]]
+id = "token"
+regex = '''tok_[a-z0-9]{16,32}'''
+keywords = ["tok_"]
+
+[[rules]]
+id = "token-v2"
+regex = '''tok_v2[a-z0-9]{16}'''
+keywords = ["tok_v2"]
+
+[[rules]]
+id = "token-copy"
+regex = '''tok_[a-z0-9]{16,32}'''
+keywords = ["tok_"]
+
+[[rules]]
+id = "no-keywords"
+regex = '''xyz[0-9]{8}'''
+
+[[rules]]
+id = "broken"
+regex = '''key_([a-z]+'''
+keywords = ["key_"]
+[rules.allowlist]
+regexes = ['''test''', '''(example''']
+
+[[rules]]
+id = "token"
+regex = '''tok_[a-z0-9]{16,32}'''
+keywords = ["tok_"]
+
+[allowlist]
+regexes = ['''.*''']
+paths = ['''^vendor/''', '''a?''']
+`
+
+func TestLint(t *testing.T) {
+	viper.Reset()
+	viper.SetConfigType("toml")
+	require.NoError(t, viper.ReadConfig(strings.NewReader(lintConfig)))
+	var vc ViperConfig
+	require.NoError(t, viper.Unmarshal(&vc))
+
+	var issues []string
+	for _, issue := range vc.Lint() {
+		issues = append(issues, issue.String())
+	}
+	assert.Equal(t, []string{
+		"warning: no-keywords: no keywords, its regex runs on every fragment",
+		"error: broken: regex does not compile: error parsing regexp: missing closing ): `key_([a-z]+`",
+		"error: broken: allowlist regex \"(example\" does not compile: error parsing regexp: missing closing ): `(example`",
+		"error: token: defined 2 times, only the last definition is used",
+		"error: global allowlist: allowlist regex \".*\" matches everything",
+		"error: global allowlist: allowlist path \"a?\" matches every path",
+		"warning: token-v2: regex matches a subset of rule token-copy",
+		"warning: token-v2: regex matches a subset of rule token",
+		"warning: token: same regex as rule token-copy",
+	}, issues)
+}
@@ -0,0 +1,41 @@
+package config
+
+// Notification sends new findings matching its severity, rules or tags to
+// Slack, email or PagerDuty. Secrets are always redacted from notifications.
+type Notification struct {
+	// Description is a short human readable description of the
+	// notification, used as the subject of messages.
+	Description string
+
+	// Severity selects findings with at least this severity.
+	Severity string
+
+	// RuleIDs and Tags select findings by rule. A notification without rule
+	// ids or tags is sent for every finding.
+	RuleIDs []string
+
+	Tags []string
+
+	// Slack is the URL of a Slack incoming webhook.
+	Slack string
+
+	// Email lists the addresses to mail findings to through the SMTP
+	// server at SMTP, e.g. smtp.example.com:587, from the address From.
+	// Credentials are read from GITLEAKS_SMTP_USERNAME and
+	// GITLEAKS_SMTP_PASSWORD.
+	Email []string
+
+	SMTP string
+
+	From string
+
+	// PagerDuty is the routing key of a PagerDuty Events API v2
+	// integration. An event is triggered for every finding.
+	PagerDuty string
+}
+
+// Matches returns true if a finding with the rule id and tags should be
+// notified. Severity is checked separately.
+func (n *Notification) Matches(ruleID string, tags []string) bool {
+	return matchesRuleOrTag(n.RuleIDs, n.Tags, ruleID, tags)
+}
@@ -0,0 +1,40 @@
+package config
+
+// Policy actions control what happens when a policy is violated.
+const (
+	PolicyFail = "fail"
+	PolicyWarn = "warn"
+)
+
+// Policy is a condition evaluated at the end of a scan. When a config has
+// policies, they decide whether the scan fails instead of any finding
+// failing the scan.
+type Policy struct {
+	// Description is a short human readable description of the policy.
+	Description string
+
+	// Severity selects findings with at least this severity.
+	Severity string
+
+	// RuleIDs and Tags select findings by rule. A policy without rule ids
+	// or tags applies to every finding.
+	RuleIDs []string
+
+	Tags []string
+
+	// MaxFindings is the number of matching findings allowed before the
+	// policy is violated. When PerRule is set the limit applies to each
+	// rule separately.
+	MaxFindings int
+
+	PerRule bool
+
+	// Action is either PolicyFail or PolicyWarn.
+	Action string
+}
+
+// Matches returns true if a finding with the rule id and tags is subject
+// to the policy. Severity is checked separately.
+func (p *Policy) Matches(ruleID string, tags []string) bool {
+	return matchesRuleOrTag(p.RuleIDs, p.Tags, ruleID, tags)
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+// RepoConfigName is the name of the config file a repository can ship at its
+// root to add its own rules and allowlists to a scan.
+const RepoConfigName = ".gitleaks.toml"
+
+// LoadRepoConfig reads the config file a scanned repository ships. The
+// repository doesn't control the environment the scan runs in, so extending
+// other configs is not allowed.
+func LoadRepoConfig(path string) (Config, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("toml")
+	if err := v.ReadInConfig(); err != nil {
+		return Config{}, err
+	}
+	var vc ViperConfig
+	if err := v.Unmarshal(&vc); err != nil {
+		return Config{}, err
+	}
+	if vc.Extend != (Extend{}) {
+		log.Warn().Msgf("ignoring [extend] in repository config %s", path)
+		vc.Extend = Extend{}
+	}
+	cfg, err := vc.Translate()
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Path = path
+	return cfg, nil
+}
+
+// MergeRepoConfig adds the rules and allowlists of a repository config to c.
+// Rules c already defines keep their definition but gain the allowlist the
+// repository has for them.
+func (c *Config) MergeRepoConfig(repo Config) {
+	for ruleID, repoRule := range repo.Rules {
+		rule, ok := c.Rules[ruleID]
+		if !ok {
+			continue
+		}
+		rule.Allowlist.Regexes = append(rule.Allowlist.Regexes, repoRule.Allowlist.Regexes...)
+		rule.Allowlist.Paths = append(rule.Allowlist.Paths, repoRule.Allowlist.Paths...)
+		rule.Allowlist.Commits = append(rule.Allowlist.Commits, repoRule.Allowlist.Commits...)
+		rule.Allowlist.StopWords = append(rule.Allowlist.StopWords, repoRule.Allowlist.StopWords...)
+		rule.Allowlist.SecretHashes = append(rule.Allowlist.SecretHashes, repoRule.Allowlist.SecretHashes...)
+		c.Rules[ruleID] = rule
+	}
+	c.extend(repo)
+}
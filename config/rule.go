@@ -1,9 +1,124 @@
 package config
 
 import (
+	"fmt"
 	"regexp"
+	"strings"
 )
 
+// Validators check the structure of a matched secret, which removes many
+// false positives a regex alone can't rule out.
+const (
+	// ValidateJWT requires a decodable header and a signature section.
+	ValidateJWT = "jwt"
+
+	// ValidateLuhn requires a valid Luhn checksum, used by card numbers.
+	ValidateLuhn = "luhn"
+
+	// ValidateBase64 requires the secret to decode as base64. Almost any long
+	// enough run of the base64 alphabet does, so it's only useful for values
+	// that must be base64 where they are found, like npm passwords.
+	ValidateBase64 = "base64"
+
+	// ValidateAWSKeyID requires a known AWS access key id prefix and a
+	// base32 encoded body.
+	ValidateAWSKeyID = "aws-key-id"
+
+	// ValidateAWSSecretKey requires a valid AWS access key id in the same
+	// fragment as the secret access key.
+	ValidateAWSSecretKey = "aws-secret-key"
+
+	// ValidateBasicAuth requires the secret to decode as base64 to a
+	// user:password pair with a password, like the auth of registries.
+	ValidateBasicAuth = "basic-auth"
+)
+
+func validValidator(v string) bool {
+	switch v {
+	case ValidateJWT, ValidateLuhn, ValidateBase64, ValidateAWSKeyID, ValidateAWSSecretKey, ValidateBasicAuth:
+		return true
+	}
+	return false
+}
+
+// RegexOptions are flags a rule's regex is compiled with, so they don't
+// have to be written inline as (?i), (?m) and (?s).
+type RegexOptions struct {
+	// CaseInsensitive matches letters in either case.
+	CaseInsensitive bool
+	// Multiline makes ^ and $ match at the start and end of every line.
+	Multiline bool
+	// DotAll makes . match newlines too.
+	DotAll bool
+}
+
+// leadingFlagsPattern matches the inline flags a regex starts with, ex:
+// (?i) or (?-s).
+var leadingFlagsPattern = regexp.MustCompile(`^\(\?([imsU]*)(?:-([imsU]*))?\)`)
+
+// flags returns the inline flags the options set, empty if none are.
+func (o RegexOptions) flags() string {
+	var flags string
+	if o.CaseInsensitive {
+		flags += "i"
+	}
+	if o.Multiline {
+		flags += "m"
+	}
+	if o.DotAll {
+		flags += "s"
+	}
+	return flags
+}
+
+// ruleRegex returns regex with the options prepended as inline flags. It is
+// an error to set options without a regex, or options the regex clears
+// again right away, like caseInsensitive for a regex starting with (?-i).
+func ruleRegex(regex string, options RegexOptions) (string, error) {
+	flags := options.flags()
+	if flags == "" {
+		return regex, nil
+	}
+	if regex == "" {
+		return "", fmt.Errorf("regex options require a regex")
+	}
+	if m := leadingFlagsPattern.FindStringSubmatch(regex); m != nil {
+		for _, flag := range flags {
+			if strings.ContainsRune(m[2], flag) {
+				return "", fmt.Errorf("regex option %s contradicts the regex flags %s", regexOptionName(flag), m[0])
+			}
+		}
+	}
+	return "(?" + flags + ")" + regex, nil
+}
+
+// redundantRegexOptions returns the names of the options regex already sets
+// inline at its start.
+func redundantRegexOptions(regex string, options RegexOptions) []string {
+	m := leadingFlagsPattern.FindStringSubmatch(regex)
+	if m == nil {
+		return nil
+	}
+	var names []string
+	for _, flag := range options.flags() {
+		if strings.ContainsRune(m[1], flag) {
+			names = append(names, regexOptionName(flag))
+		}
+	}
+	return names
+}
+
+// regexOptionName returns the name of the option setting an inline flag.
+func regexOptionName(flag rune) string {
+	switch flag {
+	case 'i':
+		return "caseInsensitive"
+	case 'm':
+		return "multiline"
+	}
+	return "dotAll"
+}
+
 // Rules contain information that define details on how to detect secrets
 type Rule struct {
 	// Description is the description of the rule.
@@ -28,6 +143,14 @@ type Rule struct {
 	// filter secrets by path
 	Path *regexp.Regexp
 
+	// Validate is the name of a structural check a secret must pass to be
+	// reported, e.g. decoding a JWT or Luhn checking a card number.
+	Validate string
+
+	// Severity is the impact of a secret matching this rule leaking.
+	// One of low, medium, high, or critical. Defaults to medium.
+	Severity string
+
 	// Tags is an array of strings used for metadata
 	// and reporting purposes.
 	Tags []string
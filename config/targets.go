@@ -0,0 +1,79 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Targets restricts which files are scanned by extension and MIME type.
+// Excludes take precedence over includes. When any include is set, only
+// files matching an include are scanned.
+type Targets struct {
+	IncludeExtensions []string
+	ExcludeExtensions []string
+
+	// MIME types may end in a wildcard subtype, e.g. "image/*".
+	IncludeMimeTypes []string
+	ExcludeMimeTypes []string
+}
+
+// IsZero returns true if no filters are configured.
+func (t *Targets) IsZero() bool {
+	return len(t.IncludeExtensions) == 0 && len(t.ExcludeExtensions) == 0 &&
+		len(t.IncludeMimeTypes) == 0 && len(t.ExcludeMimeTypes) == 0
+}
+
+// Allowed returns true if the file at path with the given MIME type should be
+// scanned. mimeType may be empty if it could not be determined.
+func (t *Targets) Allowed(path string, mimeType string) bool {
+	ext := normalizeExtension(filepath.Ext(path))
+	for _, e := range t.ExcludeExtensions {
+		if normalizeExtension(e) == ext {
+			return false
+		}
+	}
+	for _, m := range t.ExcludeMimeTypes {
+		if mimeMatches(m, mimeType) {
+			return false
+		}
+	}
+	if len(t.IncludeExtensions) == 0 && len(t.IncludeMimeTypes) == 0 {
+		return true
+	}
+	for _, e := range t.IncludeExtensions {
+		if normalizeExtension(e) == ext {
+			return true
+		}
+	}
+	for _, m := range t.IncludeMimeTypes {
+		if mimeMatches(m, mimeType) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// mimeMatches reports whether mimeType matches pattern, ignoring any
+// parameters such as "; charset=utf-8".
+func mimeMatches(pattern, mimeType string) bool {
+	if mimeType == "" {
+		return false
+	}
+	if i := strings.IndexByte(mimeType, ';'); i != -1 {
+		mimeType = mimeType[:i]
+	}
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	if strings.HasSuffix(pattern, "/*") {
+		return strings.HasPrefix(mimeType, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == mimeType
+}
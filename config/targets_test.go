@@ -0,0 +1,33 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTargetsAllowed(t *testing.T) {
+	tests := []struct {
+		targets  Targets
+		path     string
+		mimeType string
+		allowed  bool
+	}{
+		{targets: Targets{}, path: "main.go", allowed: true},
+		{targets: Targets{ExcludeExtensions: []string{".lock"}}, path: "yarn.lock", allowed: false},
+		{targets: Targets{ExcludeExtensions: []string{"LOCK"}}, path: "yarn.lock", allowed: false},
+		{targets: Targets{IncludeExtensions: []string{".env", ".tf"}}, path: "prod.env", allowed: true},
+		{targets: Targets{IncludeExtensions: []string{".env", ".tf"}}, path: ".env", allowed: true},
+		{targets: Targets{IncludeExtensions: []string{".env", ".tf"}}, path: "main.go", allowed: false},
+		{targets: Targets{ExcludeMimeTypes: []string{"image/*"}}, path: "logo.svg", mimeType: "image/svg+xml", allowed: false},
+		{targets: Targets{IncludeMimeTypes: []string{"text/*"}}, path: "a.txt", mimeType: "text/plain; charset=utf-8", allowed: true},
+		{targets: Targets{IncludeMimeTypes: []string{"text/*"}}, path: "a.bin", allowed: false},
+		{
+			targets: Targets{IncludeExtensions: []string{".yaml"}, ExcludeMimeTypes: []string{"application/yaml"}},
+			path:    "a.yaml", mimeType: "application/yaml", allowed: false,
+		},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, tt.targets.Allowed(tt.path, tt.mimeType), "%+v %s", tt.targets, tt.path)
+	}
+}
@@ -1,9 +1,74 @@
 package config
 
 import (
+	"fmt"
 	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
 )
 
+// UntilDateFormat is the format of expiration dates.
+const UntilDateFormat = "2006-01-02"
+
+// untilSuffix separates an entry from its expiration.
+const untilSuffix = " until:"
+
+// now is replaced in tests.
+var now = time.Now
+
+// ParseUntil parses an expiration, either a date or an RFC 3339 timestamp. A
+// date expires at the end of that day in UTC. The zero time, which never
+// expires, is returned for an empty string.
+func ParseUntil(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(UntilDateFormat, s); err == nil {
+		return t.Add(24*time.Hour - time.Nanosecond), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// SplitUntil splits an entry followed by an optional expiration, like
+// `<fingerprint> until:2024-06-01`, into the entry and its expiration.
+func SplitUntil(s string) (string, time.Time, error) {
+	i := strings.LastIndex(s, untilSuffix)
+	if i == -1 {
+		return s, time.Time{}, nil
+	}
+	until, err := ParseUntil(strings.TrimSpace(s[i+len(untilSuffix):]))
+	if err != nil {
+		return s, time.Time{}, err
+	}
+	return strings.TrimSpace(s[:i]), until, nil
+}
+
+// ExpiredAt returns true if until is set and before t.
+func ExpiredAt(until time.Time, t time.Time) bool {
+	return !until.IsZero() && t.After(until)
+}
+
+// compileAllowlist compiles the regexes or paths of an allowlist. Entries
+// can expire on their own, `vendor/ until:2024-06-01`, and are left out once
+// they have.
+func compileAllowlist(name string, entries []string) ([]*regexp.Regexp, error) {
+	var res []*regexp.Regexp
+	for _, entry := range entries {
+		pattern, until, err := SplitUntil(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%s allowlist entry %q has an invalid until: %w", name, entry, err)
+		}
+		if ExpiredAt(until, now()) {
+			log.Warn().Msgf("%s allowlist entry %s expired on %s and is no longer applied", name, pattern, until.Format(UntilDateFormat))
+			continue
+		}
+		res = append(res, regexp.MustCompile(pattern))
+	}
+	return res, nil
+}
+
 func anyRegexMatch(f string, res []*regexp.Regexp) bool {
 	for _, re := range res {
 		if regexMatched(f, re) {
@@ -22,3 +87,18 @@ func regexMatched(f string, re *regexp.Regexp) bool {
 	}
 	return false
 }
+
+func validSeverity(s string) bool {
+	switch s {
+	case "low", "medium", "high", "critical":
+		return true
+	}
+	return false
+}
+
+func regexString(re *regexp.Regexp) string {
+	if re == nil {
+		return ""
+	}
+	return re.String()
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+// reloadDebounce is how long to wait for writes to a config file to settle
+// before reloading it. Editors tend to write files in several steps.
+const reloadDebounce = 250 * time.Millisecond
+
+// Load reads and translates the config file at path.
+func Load(path string) (Config, error) {
+	// extending configs is tracked globally, reset it so every load
+	// extends configs the same way the initial load did
+	extendDepth = 0
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("toml")
+	if err := v.ReadInConfig(); err != nil {
+		return Config{}, err
+	}
+	var vc ViperConfig
+	if err := v.Unmarshal(&vc); err != nil {
+		return Config{}, err
+	}
+	cfg, err := vc.Translate()
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Path = path
+	return cfg, nil
+}
+
+// Watch watches the config file at path and calls reload with the new config
+// every time the file changes. Configs that fail to load are logged and
+// skipped so a typo doesn't interrupt a long running scan. Close the returned
+// watcher to stop watching.
+func Watch(path string, reload func(Config)) (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	// watch the directory rather than the file since many editors replace
+	// files on save, which would drop a watch on the file itself
+	path = filepath.Clean(path)
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	go func() {
+		var timer *time.Timer
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != path ||
+					event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+					continue
+				}
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(reloadDebounce, func() {
+					cfg, err := Load(path)
+					if err != nil {
+						log.Error().Err(err).Msgf("unable to reload config %s, keeping previous config", path)
+						return
+					}
+					log.Info().Msgf("reloaded config %s (hash %s)", path, cfg.Hash())
+					reload(cfg)
+				})
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Error().Err(err).Msg("config watcher")
+			}
+		}
+	}()
+	return watcher, nil
+}
@@ -0,0 +1,82 @@
+package detect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+// actionTimeout limits how long a command or webhook may take, it is
+// replaced in tests.
+var actionTimeout = 30 * time.Second
+
+// RunActions invokes every configured action matching each verified finding,
+// credentials that weren't confirmed to be active are left alone. The finding
+// is passed to commands on stdin and to webhooks as the request body, encoded
+// as JSON. Failing actions are logged and do not stop the remaining actions.
+// The number of failed invocations is returned as an error.
+func RunActions(actions []config.Action, findings []report.Finding) error {
+	failed := 0
+	for _, f := range findings {
+		if f.Verified == nil || !*f.Verified {
+			continue
+		}
+		for _, a := range actions {
+			if !a.Matches(f.RuleID, f.Tags) {
+				continue
+			}
+			if err := runAction(a, f); err != nil {
+				log.Error().Err(err).Msgf("action %q failed for finding %s", a.Description, f.Fingerprint)
+				failed++
+				continue
+			}
+			log.Debug().Msgf("action %q ran for finding %s", a.Description, f.Fingerprint)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d actions failed", failed)
+	}
+	return nil
+}
+
+func runAction(a config.Action, f report.Finding) error {
+	payload, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+
+	if len(a.Command) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, a.Command[0], a.Command[1:]...)
+		cmd.Stdin = bytes.NewReader(payload)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("%s: timed out after %s", cmd.String(), actionTimeout)
+			}
+			return fmt.Errorf("%s: %w: %s", cmd.String(), err, bytes.TrimSpace(out))
+		}
+	}
+
+	if a.Webhook != "" {
+		client := http.Client{Timeout: actionTimeout}
+		resp, err := client.Post(a.Webhook, "application/json", bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("webhook %s returned %s", a.Webhook, resp.Status)
+		}
+	}
+	return nil
+}
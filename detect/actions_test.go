@@ -0,0 +1,76 @@
+package detect
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+func TestRunActions(t *testing.T) {
+	var received []report.Finding
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var f report.Finding
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&f))
+		received = append(received, f)
+	}))
+	defer server.Close()
+
+	actions := []config.Action{
+		{
+			Description: "rotate aws keys",
+			Tags:        []string{"AWS"},
+			Webhook:     server.URL,
+		},
+	}
+	verified, inactive := true, false
+	findings := []report.Finding{
+		{RuleID: "aws-access-key", Tags: []string{"key", "AWS"}, Verified: &verified, File: "a.txt"},
+		{RuleID: "slack-access-token", Tags: []string{"key", "Slack"}, Verified: &verified},
+		// only verified credentials are acted on
+		{RuleID: "aws-access-key", Tags: []string{"key", "AWS"}, Verified: &inactive, File: "b.txt"},
+		{RuleID: "aws-access-key", Tags: []string{"key", "AWS"}, File: "c.txt"},
+	}
+
+	err := RunActions(actions, findings)
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+	assert.Equal(t, "aws-access-key", received[0].RuleID)
+	assert.Equal(t, "a.txt", received[0].File)
+}
+
+func TestRunActionsFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	verified := true
+	actions := []config.Action{{Webhook: server.URL}}
+	err := RunActions(actions, []report.Finding{{RuleID: "aws-access-key", Verified: &verified}})
+	assert.Error(t, err)
+}
+
+func TestRunActionsTimeout(t *testing.T) {
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep is not installed")
+	}
+	timeout := actionTimeout
+	actionTimeout = 100 * time.Millisecond
+	defer func() { actionTimeout = timeout }()
+
+	verified := true
+	start := time.Now()
+	err = RunActions([]config.Action{{Command: []string{sleep, "10"}}}, []report.Finding{{RuleID: "aws-access-key", Verified: &verified}})
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
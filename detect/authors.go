@@ -0,0 +1,47 @@
+package detect
+
+import (
+	"time"
+
+	"github.com/gitleaks/go-gitdiff/gitdiff"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+var authorDomainRule = heuristicRule{
+	RuleID:      "author-email-domain",
+	Description: "Commit authored with an email address outside the allowed domains.",
+	Tags:        []string{"author-policy"},
+}
+
+// detectAuthor reports the commit of header if the config restricts the
+// domains commits may be authored from and its author's email is outside
+// them. The author's email stands in for the secret so the commits of each
+// author are grouped together.
+func (d *Detector) detectAuthor(header *gitdiff.PatchHeader) {
+	if d.Config.Authors.IsZero() || header.Author == nil || d.Config.Authors.Allowed(header.Author.Email) {
+		return
+	}
+	finding := report.Finding{
+		RuleID:      authorDomainRule.RuleID,
+		Description: authorDomainRule.Description,
+		Tags:        authorDomainRule.Tags,
+		Match:       header.Author.Email,
+		Secret:      header.Author.Email,
+		Commit:      header.SHA,
+		Author:      header.Author.Name,
+		Email:       header.Author.Email,
+		Date:        header.AuthorDate.UTC().Format(time.RFC3339),
+		Message:     header.Message(),
+		Repository:  d.Repository,
+		RemoteURL:   d.RemoteURL,
+	}
+	if header.Committer != nil {
+		finding.Committer = header.Committer.Name
+		finding.CommitterEmail = header.Committer.Email
+	}
+	if !header.CommitterDate.IsZero() {
+		finding.CommitDate = header.CommitterDate.UTC().Format(time.RFC3339)
+	}
+	d.addFinding(finding)
+}
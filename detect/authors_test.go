@@ -0,0 +1,40 @@
+package detect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+	"github.com/zricethezav/gitleaks/v8/sources"
+	"github.com/zricethezav/gitleaks/v8/sources/gittest"
+)
+
+func TestDetectAuthor(t *testing.T) {
+	r := gittest.New(t)
+	r.WriteFile("README.md", "readme\n")
+	r.Commit("initial commit")
+	r.Git("config", "user.email", "dev@gmail.com")
+	r.WriteFile("a.txt", "a\n")
+	r.WriteFile("b.txt", "b\n")
+	personal := r.Commit("commit from a personal address")
+	r.Git("config", "user.email", "dev@eng.example.com")
+	r.WriteFile("a.txt", "c\n")
+	r.Commit("commit from a subdomain")
+
+	cfg := gitTestConfig()
+	cfg.Authors = config.Authors{AllowedDomains: []string{"example.com"}}
+	detector := NewDetector(cfg)
+	gitCmd, err := sources.NewGitLogCmd(r.Dir, "")
+	require.NoError(t, err)
+	findings, err := detector.DetectGit(gitCmd)
+	require.NoError(t, err)
+
+	// one finding for the commit, not one for each file it changes
+	require.Len(t, findings, 1)
+	assert.Equal(t, authorDomainRule.RuleID, findings[0].RuleID)
+	assert.Equal(t, personal, findings[0].Commit)
+	assert.Equal(t, "dev@gmail.com", findings[0].Email)
+	assert.Equal(t, "", findings[0].File)
+}
@@ -0,0 +1,234 @@
+package detect
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+// awsKeyIDPrefixes are the prefixes of AWS access key ids. The rest of the id
+// is base32 encoded.
+var awsKeyIDPrefixes = []string{"AKIA", "ASIA", "ABIA", "ACCA", "A3T"}
+
+var awsKeyIDPattern = regexp.MustCompile(`\b(?:A3T[A-Z0-9]|AKIA|ASIA|ABIA|ACCA)[A-Z2-7]{16}\b`)
+
+// stsEndpoint is replaced in tests.
+var stsEndpoint = "https://sts.amazonaws.com/"
+
+// validAWSKeyID returns true if id has a known prefix and a base32 body.
+func validAWSKeyID(id string) bool {
+	if len(id) != 20 {
+		return false
+	}
+	for _, p := range awsKeyIDPrefixes {
+		if !strings.HasPrefix(id, p) {
+			continue
+		}
+		for _, c := range id[4:] {
+			if !(c >= 'A' && c <= 'Z' || c >= '2' && c <= '7') {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// awsKeyIDs returns the valid AWS access key ids in raw.
+func awsKeyIDs(raw string) []string {
+	var ids []string
+	for _, id := range awsKeyIDPattern.FindAllString(raw, -1) {
+		if validAWSKeyID(id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// validAWSSecretKey returns true if the secret looks like an AWS secret access
+// key that is paired with an access key id in the same fragment.
+func validAWSSecretKey(secret string, raw string) bool {
+	if len(secret) != 40 || !containsDigit(secret) || strings.ToLower(secret) == secret || strings.ToUpper(secret) == secret {
+		return false
+	}
+	return len(awsKeyIDs(raw)) > 0
+}
+
+// awsCandidate is a secret access key waiting to be verified with the key ids
+// found next to it.
+type awsCandidate struct {
+	secret string
+	keyIDs []string
+}
+
+// pendingVerification returns the Verified field of a finding of secret in
+// raw, which verify sets once the finding is accepted.
+func (d *Detector) pendingVerification(secret string, raw string) *bool {
+	verified := new(bool)
+	d.pendingVerifications.Store(verified, awsCandidate{secret: secret, keyIDs: awsKeyIDs(raw)})
+	return verified
+}
+
+// verify checks the credentials of a finding returned by detect against the
+// provider. Verified is left nil if the provider couldn't be asked.
+func (d *Detector) verify(finding report.Finding) report.Finding {
+	if finding.Verified == nil {
+		return finding
+	}
+	c, ok := d.pendingVerifications.LoadAndDelete(finding.Verified)
+	if !ok {
+		return finding
+	}
+	candidate := c.(awsCandidate)
+	finding.Verified = d.AWSVerifier.verifyKeyIDs(candidate.secret, candidate.keyIDs)
+	return finding
+}
+
+// discardVerification forgets the pending verification of a finding returned
+// by detect that was rejected, or only looked at, so its credentials are
+// never sent to the provider.
+func (d *Detector) discardVerification(finding report.Finding) {
+	if finding.Verified != nil {
+		d.pendingVerifications.Delete(finding.Verified)
+	}
+}
+
+// detectUnverified scans the fragment like detect for lookups that only need
+// the secrets found, which are never verified.
+func (d *Detector) detectUnverified(fragment Fragment) []report.Finding {
+	findings := d.detect(fragment)
+	for _, finding := range findings {
+		d.discardVerification(finding)
+	}
+	return findings
+}
+
+// AWSVerifier checks AWS credential pairs against STS GetCallerIdentity. The
+// call requires no permissions, so it succeeds for any active key.
+type AWSVerifier struct {
+	client *http.Client
+	cache  sync.Map
+}
+
+// NewAWSVerifier returns an AWSVerifier.
+func NewAWSVerifier() *AWSVerifier {
+	return &AWSVerifier{client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Verify returns true if any of the key ids in raw together with the secret
+// are active credentials, and false if none are or they couldn't be checked.
+func (v *AWSVerifier) Verify(secret string, raw string) bool {
+	verified := v.verifyKeyIDs(secret, awsKeyIDs(raw))
+	return verified != nil && *verified
+}
+
+// verifyKeyIDs returns true if any of the key ids together with the secret
+// are active credentials and false if STS rejected them. It returns nil if
+// STS couldn't be asked about any of them, throttling or an outage don't
+// make a secret inactive.
+func (v *AWSVerifier) verifyKeyIDs(secret string, keyIDs []string) *bool {
+	var verified *bool
+	for _, id := range keyIDs {
+		key := id + ":" + secret
+		active, ok := false, false
+		if cached, found := v.cache.Load(key); found {
+			active, ok = cached.(bool), true
+		} else {
+			var err error
+			if active, err = v.getCallerIdentity(id, secret); err != nil {
+				// don't cache errors, the next finding may succeed
+				log.Debug().Err(err).Msgf("could not verify %s", id)
+			} else {
+				v.cache.Store(key, active)
+				ok = true
+			}
+		}
+		if !ok {
+			continue
+		}
+		verified = &active
+		if active {
+			return verified
+		}
+	}
+	return verified
+}
+
+func (v *AWSVerifier) getCallerIdentity(keyID string, secret string) (bool, error) {
+	body := "Action=GetCallerIdentity&Version=2011-06-15"
+	req, err := http.NewRequest(http.MethodPost, stsEndpoint, strings.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	signAWSRequest(req, body, keyID, secret, "us-east-1", "sts", time.Now().UTC())
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return true, nil
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized:
+		return false, nil
+	}
+	return false, fmt.Errorf("unexpected sts response %s", resp.Status)
+}
+
+// signAWSRequest adds an AWS Signature Version 4 to the request.
+func signAWSRequest(req *http.Request, body string, keyID string, secret string, region string, service string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	payloadHash := sha256Hex(body)
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalHeaders := fmt.Sprintf("content-type:%s\nhost:%s\nx-amz-date:%s\n",
+		req.Header.Get("Content-Type"), req.URL.Host, amzDate)
+	signedHeaders := "content-type;host;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method, path, req.URL.RawQuery, canonicalHeaders, signedHeaders, payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", date, region, service)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex(canonicalRequest),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secret), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		keyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -2,10 +2,13 @@ package detect
 
 import (
 	"errors"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/zricethezav/gitleaks/v8/config"
 	"github.com/zricethezav/gitleaks/v8/report"
 )
 
@@ -87,6 +90,18 @@ func TestFileLoadBaseline(t *testing.T) {
 	}
 }
 
+func TestLoadBaselineEnvelope(t *testing.T) {
+	baseline, err := LoadBaseline("../testdata/baseline/baseline.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, baseline)
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	require.NoError(t, report.Write(baseline, config.Config{}, "json-envelope", path, report.Manifest{Version: "v8.18.0"}))
+	enveloped, err := LoadBaseline(path)
+	require.NoError(t, err)
+	assert.Equal(t, baseline, enveloped)
+}
+
 func TestIgnoreIssuesInBaseline(t *testing.T) {
 	tests := []struct {
 		findings    []report.Finding
@@ -0,0 +1,65 @@
+package detect
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+	"github.com/zricethezav/gitleaks/v8/sources"
+)
+
+// Blame attributes the findings of a git scan whose secret is still present
+// at HEAD to the author of the line holding it there, so whoever put the
+// secret in place is notified rather than the author of the commit it was
+// found in. Like AnalyzeHistory, the HEAD version of every file with a
+// finding is re-scanned to locate the secret.
+func (d *Detector) Blame(source string, findings []report.Finding) []report.Finding {
+	// the line, counting from 1, of each secret in each file at HEAD
+	type location struct {
+		file   string
+		digest string
+	}
+	lines := make(map[location]int)
+	scannedFiles := make(map[string]bool)
+	for _, f := range findings {
+		if f.File == "" || scannedFiles[f.File] {
+			continue
+		}
+		scannedFiles[f.File] = true
+		content, err := sources.FileAtRef(source, "HEAD", f.File)
+		if err != nil {
+			continue
+		}
+		for _, headFinding := range d.detectUnverified(Fragment{Raw: content, FilePath: f.File}) {
+			loc := location{f.File, headFinding.SecretDigest()}
+			if _, ok := lines[loc]; !ok {
+				lines[loc] = headFinding.StartLine + 1
+			}
+		}
+	}
+
+	blames := make(map[string]sources.BlameLine)
+	for i, f := range findings {
+		line, ok := lines[location{f.File, f.SecretDigest()}]
+		if !ok {
+			continue
+		}
+		key := fmt.Sprintf("%s:%d", f.File, line)
+		blame, ok := blames[key]
+		if !ok {
+			var err error
+			if blame, err = sources.Blame(source, "HEAD", f.File, line); err != nil {
+				log.Debug().Err(err).Msg("")
+				continue
+			}
+			blames[key] = blame
+		}
+		findings[i].BlameCommit = blame.Commit
+		findings[i].BlameAuthor = blame.Author
+		findings[i].BlameEmail = blame.Email
+		findings[i].BlameDate = blame.Date.Format(time.RFC3339)
+	}
+	return findings
+}
@@ -0,0 +1,104 @@
+package detect
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+// lowConfidencePathPattern matches paths that tend to contain example or
+// test credentials rather than real ones.
+var lowConfidencePathPattern = regexp.MustCompile(`(?i)(^|/)(tests?|testdata|__tests__|spec|fixtures?|mocks?|examples?|samples?|docs?)(/|$)|(_test|\.test|\.spec|\.example|\.sample)\.[a-z]+$`)
+
+// docExtensions are file extensions for documentation files where secrets
+// are often placeholders.
+var docExtensions = map[string]bool{
+	".md":   true,
+	".rst":  true,
+	".txt":  true,
+	".adoc": true,
+}
+
+// Classify assigns a severity and confidence level to each finding. Severity
+// comes from the rule that produced the finding, secrets on a public remote
+// are critical. Confidence is scored using the rule type, the entropy of the
+// secret, and the type of file it was found in.
+func Classify(findings []report.Finding, cfg config.Config) []report.Finding {
+	for i, f := range findings {
+		rule := cfg.Rules[f.RuleID]
+		findings[i].Severity = rule.Severity
+		if findings[i].Severity == "" {
+			findings[i].Severity = report.LevelMedium
+		}
+		if f.PubliclyExposed {
+			findings[i].Severity = report.LevelCritical
+		}
+		findings[i].Confidence = confidence(f, rule)
+	}
+	return findings
+}
+
+// FilterConfidence returns only the findings with a confidence level greater
+// than or equal to minConfidence.
+func FilterConfidence(findings []report.Finding, minConfidence string) []report.Finding {
+	var filtered []report.Finding
+	for _, f := range findings {
+		if report.LevelRank(f.Confidence) >= report.LevelRank(minConfidence) {
+			filtered = append(filtered, f)
+		}
+	}
+	return filtered
+}
+
+// confidence scores a finding from 0 to 100 and maps the score to a level.
+func confidence(f report.Finding, rule config.Rule) string {
+	score := 50
+
+	// rules targeting a specific provider are far more precise than
+	// the generic rules
+	if strings.HasPrefix(rule.RuleID, "generic") || len(rule.Keywords) == 0 {
+		score -= 10
+	} else {
+		score += 20
+	}
+
+	// path only rules do not have a secret to score
+	if !strings.HasPrefix(f.Match, "file detected") {
+		switch {
+		case f.Entropy >= 4.5:
+			score += 15
+		case f.Entropy < 3:
+			score -= 15
+		}
+		if rule.Entropy != 0 && float64(f.Entropy) >= rule.Entropy+1 {
+			score += 5
+		}
+	}
+
+	// verification is conclusive either way
+	if f.Verified != nil {
+		if *f.Verified {
+			return report.LevelHigh
+		}
+		score -= 20
+	}
+
+	if lowConfidencePathPattern.MatchString(filepath.ToSlash(f.File)) {
+		score -= 20
+	}
+	if docExtensions[strings.ToLower(filepath.Ext(f.File))] {
+		score -= 10
+	}
+
+	switch {
+	case score >= 70:
+		return report.LevelHigh
+	case score >= 40:
+		return report.LevelMedium
+	default:
+		return report.LevelLow
+	}
+}
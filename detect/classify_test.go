@@ -0,0 +1,74 @@
+package detect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+func TestClassify(t *testing.T) {
+	cfg := config.Config{
+		Rules: map[string]config.Rule{
+			"aws-access-key": {
+				RuleID:   "aws-access-key",
+				Severity: report.LevelCritical,
+				Keywords: []string{"akia"},
+			},
+			"generic-api-key": {
+				RuleID:   "generic-api-key",
+				Keywords: []string{"key"},
+				Entropy:  3.5,
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		finding            report.Finding
+		expectedSeverity   string
+		expectedConfidence string
+	}{
+		"provider rule": {
+			finding:            report.Finding{RuleID: "aws-access-key", File: "main.go", Entropy: 3.5},
+			expectedSeverity:   report.LevelCritical,
+			expectedConfidence: report.LevelHigh,
+		},
+		"provider rule in test file": {
+			finding:            report.Finding{RuleID: "aws-access-key", File: "api/api_test.go", Entropy: 3.5},
+			expectedSeverity:   report.LevelCritical,
+			expectedConfidence: report.LevelMedium,
+		},
+		"publicly exposed": {
+			finding:            report.Finding{RuleID: "generic-api-key", File: "main.go", Entropy: 3.5, PubliclyExposed: true},
+			expectedSeverity:   report.LevelCritical,
+			expectedConfidence: report.LevelMedium,
+		},
+		"generic rule with low entropy in docs": {
+			finding:            report.Finding{RuleID: "generic-api-key", File: "docs/README.md", Entropy: 2.5},
+			expectedSeverity:   report.LevelMedium,
+			expectedConfidence: report.LevelLow,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			findings := Classify([]report.Finding{test.finding}, cfg)
+			assert.Equal(t, test.expectedSeverity, findings[0].Severity)
+			assert.Equal(t, test.expectedConfidence, findings[0].Confidence)
+		})
+	}
+}
+
+func TestFilterConfidence(t *testing.T) {
+	findings := []report.Finding{
+		{RuleID: "a", Confidence: report.LevelLow},
+		{RuleID: "b", Confidence: report.LevelMedium},
+		{RuleID: "c", Confidence: report.LevelHigh},
+	}
+	filtered := FilterConfidence(findings, report.LevelMedium)
+	assert.Len(t, filtered, 2)
+	assert.Equal(t, "b", filtered[0].RuleID)
+	assert.Equal(t, "c", filtered[1].RuleID)
+}
@@ -0,0 +1,31 @@
+package detect
+
+import "strings"
+
+// surroundingLines returns up to n lines before and after the lines spanned
+// by loc. For git scans only the lines added in the same hunk are available.
+func surroundingLines(raw string, loc Location, n int) (before []string, after []string) {
+	if loc.startLineIndex > 0 && loc.startLineIndex <= len(raw) {
+		lines := strings.Split(raw[:loc.startLineIndex], "\n")
+		if len(lines) > n {
+			lines = lines[len(lines)-n:]
+		}
+		for _, l := range lines {
+			before = append(before, strings.TrimSuffix(l, "\r"))
+		}
+	}
+	if loc.endLineIndex < len(raw) {
+		rest := strings.TrimSuffix(raw[loc.endLineIndex:], "\n")
+		rest = strings.TrimPrefix(strings.TrimPrefix(rest, "\r"), "\n")
+		if rest != "" {
+			lines := strings.Split(rest, "\n")
+			if len(lines) > n {
+				lines = lines[:n]
+			}
+			for _, l := range lines {
+				after = append(after, strings.TrimSuffix(l, "\r"))
+			}
+		}
+	}
+	return before, after
+}
@@ -0,0 +1,138 @@
+package detect
+
+import (
+	"encoding/hex"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+const (
+	encodingBase64  = "base64"
+	encodingHex     = "hex"
+	encodingPercent = "percent"
+)
+
+var (
+	base64Pattern  = regexp.MustCompile(`[A-Za-z0-9+/_-]{16,}={0,2}`)
+	hexPattern     = regexp.MustCompile(`\b(?:[0-9a-fA-F]{2}){8,}\b`)
+	percentPattern = regexp.MustCompile(`[^\s'"\x60<>]*%[0-9A-Fa-f]{2}[^\s'"\x60<>]*`)
+)
+
+// encodedSpan is an encoded value in a fragment and its decoded text.
+type encodedSpan struct {
+	start    int
+	end      int
+	encoding string
+	decoded  string
+}
+
+// detectDecoded decodes base64, hex and percent encoded values in the
+// fragment and runs the rules on the decoded text. Decoded text is decoded
+// again up to depth times. Findings are located at the encoded value and list
+// the encodings that were removed, outermost first. findings are the findings
+// already made so a secret found both ways isn't reported twice.
+func (d *Detector) detectDecoded(fragment Fragment, depth int, findings []report.Finding) []report.Finding {
+	seen := make(map[string]bool, len(findings))
+	for _, f := range findings {
+		seen[decodedKey(f)] = true
+	}
+
+	var decodedFindings []report.Finding
+	for _, f := range d.decode(fragment, depth) {
+		if seen[decodedKey(f)] {
+			continue
+		}
+		seen[decodedKey(f)] = true
+		decodedFindings = append(decodedFindings, f)
+	}
+	return decodedFindings
+}
+
+func (d *Detector) decode(fragment Fragment, depth int) []report.Finding {
+	if depth <= 0 {
+		return nil
+	}
+	fragment.newlineIndices = regexp.MustCompile("\n").FindAllStringIndex(fragment.Raw, -1)
+
+	var findings []report.Finding
+	for _, span := range encodedSpans(fragment.Raw) {
+		decoded := Fragment{
+			Raw:         span.decoded,
+			FilePath:    fragment.FilePath,
+			SymlinkFile: fragment.SymlinkFile,
+			CommitSHA:   fragment.CommitSHA,
+		}
+		inner := d.detectRules(decoded)
+		inner = append(inner, d.decode(decoded, depth-1)...)
+
+		loc := location(fragment, []int{span.start, span.end})
+		for _, f := range inner {
+			f = d.relocateTo(fragment, f, loc)
+			f.Encodings = append([]string{span.encoding}, f.Encodings...)
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// relocateTo moves a finding made on decoded text to loc, the location of the
+// encoded text in fragment.
+func (d *Detector) relocateTo(fragment Fragment, f report.Finding, loc Location) report.Finding {
+	f.StartLine = loc.startLine
+	f.EndLine = loc.endLine
+	f.StartColumn = loc.startColumn
+	f.EndColumn = loc.endColumn
+	f.Line = fragment.Raw[loc.startLineIndex:loc.endLineIndex]
+	f.ContextBefore, f.ContextAfter = nil, nil
+	if d.ContextLines > 0 {
+		f.ContextBefore, f.ContextAfter = surroundingLines(fragment.Raw, loc, d.ContextLines)
+	}
+	return f
+}
+
+// encodedSpans returns the values in raw that decode to printable text.
+func encodedSpans(raw string) []encodedSpan {
+	var spans []encodedSpan
+	for _, m := range hexPattern.FindAllStringIndex(raw, -1) {
+		if b, err := hex.DecodeString(raw[m[0]:m[1]]); err == nil && printable(b) {
+			spans = append(spans, encodedSpan{m[0], m[1], encodingHex, string(b)})
+		}
+	}
+	for _, m := range base64Pattern.FindAllStringIndex(raw, -1) {
+		if b, ok := decodeBase64(raw[m[0]:m[1]]); ok && printable(b) {
+			spans = append(spans, encodedSpan{m[0], m[1], encodingBase64, string(b)})
+		}
+	}
+	for _, m := range percentPattern.FindAllStringIndex(raw, -1) {
+		encoded := raw[m[0]:m[1]]
+		if s, err := url.PathUnescape(encoded); err == nil && s != encoded && printable([]byte(s)) {
+			spans = append(spans, encodedSpan{m[0], m[1], encodingPercent, s})
+		}
+	}
+	return spans
+}
+
+// printable returns true if b is valid UTF-8 text without control
+// characters other than whitespace. Most random bytes are not.
+func printable(b []byte) bool {
+	if len(b) == 0 || !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// decodedKey identifies a finding by rule, line and secret.
+func decodedKey(f report.Finding) string {
+	return strings.Join([]string{f.RuleID, f.Secret, strconv.Itoa(f.StartLine)}, "\x00")
+}
@@ -5,9 +5,11 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/zricethezav/gitleaks/v8/config"
 	"github.com/zricethezav/gitleaks/v8/report"
@@ -22,8 +24,12 @@ import (
 const (
 	gitleaksAllowSignature = "gitleaks:allow"
 	chunkSize              = 10 * 1_000 // 10kb
+	maxWorkers             = 40
 )
 
+// now is replaced in tests.
+var now = time.Now
+
 // Detector is the main detector struct
 type Detector struct {
 	// Config is the configuration for the detector
@@ -34,6 +40,10 @@ type Detector struct {
 	// without calling `detector.Start(cmd *cobra.Command)`
 	Redact uint
 
+	// HMACKey replaces secrets with an HMAC of them keyed with it in all
+	// findings instead of redacting them, see report.Finding.Pseudonymize.
+	HMACKey []byte
+
 	// verbose is a flag to print findings
 	Verbose bool
 
@@ -49,14 +59,102 @@ type Detector struct {
 	// IgnoreGitleaksAllow is a flag to ignore gitleaks:allow comments.
 	IgnoreGitleaksAllow bool
 
+	// RecordSuppressions records the matches suppressed by allowlists,
+	// stopwords, gitleaks:allow comments, .gitleaksignore and the baseline,
+	// see Suppressions.
+	RecordSuppressions bool
+
+	// ContextLines is the number of lines before and after a match
+	// included in each finding.
+	ContextLines int
+
+	// VaultAllowlist ignores findings that reference secrets stored in
+	// HashiCorp Vault. Disabled when nil.
+	VaultAllowlist *VaultAllowlist
+
+	// YAMLAware decodes the data of Kubernetes Secret manifests before
+	// matching and flags sensitive keys in Secrets and Helm values files.
+	YAMLAware bool
+
+	// KeyValueAware flags values assigned to sensitive keys in .env,
+	// .properties, .ini and .tfvars files.
+	KeyValueAware bool
+
+	// DisabledHeuristics holds the IDs of the built in detectors, see
+	// HeuristicRuleIDs, that don't report findings.
+	DisabledHeuristics map[string]bool
+
+	// NormalizeUnicode also scans fragments with zero width characters
+	// removed and look-alike letters replaced.
+	NormalizeUnicode bool
+
+	// MaxDecodeDepth is the number of times base64, hex and percent encoded
+	// values are decoded and scanned again. Disabled when 0.
+	MaxDecodeDepth int
+
+	// AWSVerifier checks paired AWS credentials against STS. Disabled when nil.
+	AWSVerifier *AWSVerifier
+
+	// pendingVerifications holds the awsCandidate of findings that are
+	// verified once addFinding accepts them, keyed by their Verified field.
+	pendingVerifications sync.Map
+
+	// Repository and RemoteURL are attached to every finding from a git
+	// scan so reports spanning many repositories can be attributed.
+	Repository string
+	RemoteURL  string
+
+	// MaxCommitFiles is the number of files scanned in each commit of a git
+	// scan. The rest are skipped. Unlimited when 0.
+	MaxCommitFiles int
+
+	// LiteralsOnly drops findings outside of the string literals and
+	// comments of source files in common languages, see literalFindings.
+	LiteralsOnly bool
+
+	// SkipGenerated skips lockfiles, minified scripts and stylesheets, source
+	// maps and generated code, going by their names and content.
+	SkipGenerated bool
+
+	// Transcode converts files in other encodings than UTF-8, like UTF-16,
+	// Latin-1 and Shift-JIS, to UTF-8 before scanning them, see textEncoding.
+	Transcode bool
+
+	// FetchLFS fetches the large files that Git LFS pointers in git scans
+	// stand in for with git-lfs and scans them instead of skipping the
+	// pointers. Larger files than MaxLFSMegaBytes are skipped, unless 0.
+	FetchLFS        bool
+	MaxLFSMegaBytes int
+
+	// MaxFindings stops the scan once this many findings are recorded, for
+	// checks that only need to know whether there are any. Further findings
+	// of files already being scanned are dropped. Unlimited when 0.
+	MaxFindings int
+	// MinConfidence drops findings below this confidence before they count
+	// towards MaxFindings, so a scan doesn't stop at one that isn't reported.
+	MinConfidence string
+
+	// plugins are external detectors run on every fragment.
+	plugins []*Plugin
+
 	// commitMap is used to keep track of commits that have been scanned.
 	// This is only used for logging purposes and git scans.
 	commitMap map[string]bool
 
+	// configMutex guards Config and prefilter so the config can be
+	// reloaded while a scan is running.
+	configMutex *sync.RWMutex
+
 	// findingMutex is to prevent concurrent access to the
 	// findings slice when adding findings.
 	findingMutex *sync.Mutex
 
+	// skipped are the files that were not scanned and why.
+	skipped []Skip
+
+	// suppressions are the matches that were not reported and why.
+	suppressions []Suppression
+
 	// findings is a slice of report.Findings. This is the result
 	// of the detector's scan which can then be used to generate a
 	// report.
@@ -75,6 +173,10 @@ type Detector struct {
 	// gitleaksIgnore
 	gitleaksIgnore map[string]bool
 
+	// ignoreMutex guards gitleaksIgnore which can grow during a directory
+	// scan as nested .gitleaksignore files are found.
+	ignoreMutex *sync.RWMutex
+
 	// Sema (https://github.com/fatih/semgroup) controls the concurrency
 	Sema *semgroup.Group
 }
@@ -98,6 +200,10 @@ type Fragment struct {
 	// keywords is a map of all the keywords contain within the contents
 	// of this fragment
 	keywords map[string]bool
+
+	// suppressed collects the matches of the fragment that are suppressed
+	// if set, see Detector.RecordSuppressions.
+	suppressed *[]Suppression
 }
 
 // NewDetector creates a new detector with the given config
@@ -105,11 +211,13 @@ func NewDetector(cfg config.Config) *Detector {
 	return &Detector{
 		commitMap:      make(map[string]bool),
 		gitleaksIgnore: make(map[string]bool),
+		ignoreMutex:    &sync.RWMutex{},
 		findingMutex:   &sync.Mutex{},
+		configMutex:    &sync.RWMutex{},
 		findings:       make([]report.Finding, 0),
 		Config:         cfg,
 		prefilter:      *ahocorasick.NewTrieBuilder().AddStrings(cfg.Keywords).Build(),
-		Sema:           semgroup.NewGroup(context.Background(), 40),
+		Sema:           semgroup.NewGroup(context.Background(), maxWorkers),
 	}
 }
 
@@ -132,7 +240,44 @@ func NewDetectorDefaultConfig() (*Detector, error) {
 	return NewDetector(cfg), nil
 }
 
+// ReloadConfig atomically replaces the config of the detector. Fragments that
+// are being scanned finish with the previous config.
+func (d *Detector) ReloadConfig(cfg config.Config) {
+	d.configMutex.Lock()
+	defer d.configMutex.Unlock()
+	d.Config = cfg
+	d.prefilter = *ahocorasick.NewTrieBuilder().AddStrings(cfg.Keywords).Build()
+}
+
+// targetAllowed returns true if the config's target filters allow scanning
+// the file at path with the given MIME type.
+func (d *Detector) targetAllowed(path string, mimeType string) bool {
+	d.configMutex.RLock()
+	defer d.configMutex.RUnlock()
+	return d.Config.Targets.Allowed(path, mimeType)
+}
+
+// ConfigHash returns the hash of the config the detector is currently using.
+func (d *Detector) ConfigHash() string {
+	d.configMutex.RLock()
+	defer d.configMutex.RUnlock()
+	return d.Config.Hash()
+}
+
 func (d *Detector) AddGitleaksIgnore(gitleaksIgnorePath string) error {
+	return d.addGitleaksIgnore(gitleaksIgnorePath, "")
+}
+
+// AddNestedGitleaksIgnore adds the fingerprints of a .gitleaksignore file
+// found in a subdirectory of a directory scan. The file paths in its
+// fingerprints are relative to the directory containing it.
+func (d *Detector) AddNestedGitleaksIgnore(gitleaksIgnorePath string) error {
+	return d.addGitleaksIgnore(gitleaksIgnorePath, filepath.Dir(gitleaksIgnorePath))
+}
+
+// addGitleaksIgnore reads the fingerprints in gitleaksIgnorePath, prefixing
+// their file paths with dir if it is set.
+func (d *Detector) addGitleaksIgnore(gitleaksIgnorePath string, dir string) error {
 	log.Debug().Msgf("found .gitleaksignore file: %s", gitleaksIgnorePath)
 	file, err := os.Open(gitleaksIgnorePath)
 
@@ -149,7 +294,24 @@ func (d *Detector) AddGitleaksIgnore(gitleaksIgnorePath string) error {
 	scanner := bufio.NewScanner(file)
 
 	for scanner.Scan() {
-		d.gitleaksIgnore[scanner.Text()] = true
+		line := scanner.Text()
+		// fingerprints may be followed by an expiration, e.g.
+		// `<fingerprint> until:2024-06-01`
+		line, until, err := config.SplitUntil(line)
+		if err != nil {
+			log.Warn().Msgf("invalid expiration in %s: %s", gitleaksIgnorePath, line)
+			continue
+		}
+		if config.ExpiredAt(until, now()) {
+			log.Warn().Msgf("ignored fingerprint %s expired on %s and is no longer applied", line, until.Format(config.UntilDateFormat))
+			continue
+		}
+		if dir != "" {
+			line = filepath.Join(dir, line)
+		}
+		d.ignoreMutex.Lock()
+		d.gitleaksIgnore[config.SlashPath(line)] = true
+		d.ignoreMutex.Unlock()
 	}
 	return nil
 }
@@ -168,10 +330,21 @@ func (d *Detector) DetectString(content string) []report.Finding {
 
 // Detect scans the given fragment and returns a list of findings
 func (d *Detector) Detect(fragment Fragment) []report.Finding {
+	findings := d.detect(fragment)
+	for i, finding := range findings {
+		findings[i] = d.verify(finding)
+	}
+	return findings
+}
+
+// detect scans the fragment like Detect but leaves the credentials in the
+// findings unverified until they are passed to addFinding, so secrets in the
+// .gitleaksignore or baseline are never sent to the provider.
+func (d *Detector) detect(fragment Fragment) []report.Finding {
 	var findings []report.Finding
 
-	// initiate fragment keywords
-	fragment.keywords = make(map[string]bool)
+	d.configMutex.RLock()
+	defer d.configMutex.RUnlock()
 
 	// check if filepath is allowed
 	if fragment.FilePath != "" && (d.Config.Allowlist.PathAllowed(fragment.FilePath) ||
@@ -179,6 +352,35 @@ func (d *Detector) Detect(fragment Fragment) []report.Finding {
 		return findings
 	}
 
+	findings = append(findings, d.detectRules(fragment)...)
+	findings = append(findings, d.detectPlugins(fragment)...)
+	findings = append(findings, d.detectYAML(fragment, findings)...)
+	findings = append(findings, d.detectKeyValues(fragment, findings)...)
+	findings = append(findings, d.detectTerraform(fragment, findings)...)
+	if d.NormalizeUnicode {
+		findings = append(findings, d.detectNormalized(fragment, findings)...)
+	}
+	if d.MaxDecodeDepth > 0 {
+		findings = append(findings, d.detectDecoded(fragment, d.MaxDecodeDepth, findings)...)
+	}
+	if d.LiteralsOnly {
+		findings = literalFindings(fragment, findings)
+	}
+	// offsets are found before the secrets are redacted
+	for i, finding := range findings {
+		findings[i] = locateSecret(finding, fragment.Raw)
+	}
+	return filter(findings, d.Redact, d.HMACKey)
+}
+
+// detectRules runs every rule whose keywords appear in the fragment. The
+// caller must hold configMutex.
+func (d *Detector) detectRules(fragment Fragment) []report.Finding {
+	var findings []report.Finding
+
+	// initiate fragment keywords
+	fragment.keywords = make(map[string]bool)
+
 	// add newline indices for location calculation in detectRule
 	fragment.newlineIndices = regexp.MustCompile("\n").FindAllStringIndex(fragment.Raw, -1)
 
@@ -207,7 +409,19 @@ func (d *Detector) Detect(fragment Fragment) []report.Finding {
 			findings = append(findings, d.detectRule(fragment, rule)...)
 		}
 	}
-	return filter(findings, d.Redact)
+	return findings
+}
+
+// submatch returns the text captured by group in a match returned by
+// FindAllStringSubmatchIndex. The group is read from the match itself rather
+// than by running the regex again on the matched text, which can fail or
+// capture something else once the surrounding text is gone.
+func submatch(raw string, matchIndex []int, group int) string {
+	start, end := matchIndex[2*group], matchIndex[2*group+1]
+	if start < 0 {
+		return ""
+	}
+	return raw[start:end]
 }
 
 // detectRule scans the given fragment for the given rule and returns a list of findings
@@ -220,9 +434,13 @@ func (d *Detector) detectRule(fragment Fragment, rule config.Rule) []report.Find
 		return findings
 	}
 
+	// rule paths are written with forward slashes, normalize windows paths
+	// so the same config works on every platform
+	slashPath := config.SlashPath(fragment.FilePath)
+
 	if rule.Path != nil && rule.Regex == nil {
 		// Path _only_ rule
-		if rule.Path.MatchString(fragment.FilePath) {
+		if rule.Path.MatchString(slashPath) {
 			finding := report.Finding{
 				Description: rule.Description,
 				File:        fragment.FilePath,
@@ -237,7 +455,7 @@ func (d *Detector) detectRule(fragment Fragment, rule config.Rule) []report.Find
 		// if path is set _and_ a regex is set, then we need to check both
 		// so if the path does not match, then we should return early and not
 		// consider the regex
-		if !rule.Path.MatchString(fragment.FilePath) {
+		if !rule.Path.MatchString(slashPath) {
 			return findings
 		}
 	}
@@ -256,7 +474,7 @@ func (d *Detector) detectRule(fragment Fragment, rule config.Rule) []report.Find
 		}
 	}
 
-	matchIndices := rule.Regex.FindAllStringIndex(fragment.Raw, -1)
+	matchIndices := rule.Regex.FindAllStringSubmatchIndex(fragment.Raw, -1)
 	for _, matchIndex := range matchIndices {
 		// extract secret from match
 		secret := strings.Trim(fragment.Raw[matchIndex[0]:matchIndex[1]], "\n")
@@ -286,28 +504,28 @@ func (d *Detector) detectRule(fragment Fragment, rule config.Rule) []report.Find
 			Line:        fragment.Raw[loc.startLineIndex:loc.endLineIndex],
 		}
 
+		if d.ContextLines > 0 {
+			finding.ContextBefore, finding.ContextAfter = surroundingLines(fragment.Raw, loc, d.ContextLines)
+		}
+
 		if strings.Contains(fragment.Raw[loc.startLineIndex:loc.endLineIndex],
 			gitleaksAllowSignature) && !d.IgnoreGitleaksAllow {
+			d.suppress(fragment, finding, SuppressAllowComment, "")
 			continue
 		}
 
 		// by default if secret group is not set, we will check to see if there
-		// are any capture groups. If there are, we will use the first capture to start
-		groups := rule.Regex.FindStringSubmatch(secret)
-		if rule.SecretGroup == 0 {
-			// if len(groups) == 2 that means there is only one capture group
-			// the first element in groups is the full match, the second is the
-			// first capture group
-			if len(groups) == 2 {
-				secret = groups[1]
-				finding.Secret = secret
-			}
-		} else {
-			if len(groups) <= rule.SecretGroup || len(groups) == 0 {
-				// Config validation should prevent this
-				continue
-			}
-			secret = groups[rule.SecretGroup]
+		// are any capture groups. If there is only one, we will use it as the secret
+		secretGroup := rule.SecretGroup
+		if secretGroup == 0 && rule.Regex.NumSubexp() == 1 {
+			secretGroup = 1
+		}
+		if secretGroup > rule.Regex.NumSubexp() {
+			// Config validation should prevent this
+			continue
+		}
+		if secretGroup > 0 {
+			secret = submatch(fragment.Raw, matchIndex, secretGroup)
 			finding.Secret = secret
 		}
 
@@ -327,14 +545,38 @@ func (d *Detector) detectRule(fragment Fragment, rule config.Rule) []report.Find
 		case "line":
 			globalAllowlistTarget = finding.Line
 		}
-		if rule.Allowlist.RegexAllowed(allowlistTarget) ||
-			d.Config.Allowlist.RegexAllowed(globalAllowlistTarget) {
+		if rule.Allowlist.RegexAllowed(allowlistTarget) {
+			d.suppress(fragment, finding, SuppressAllowlistRegex, "rule")
+			continue
+		}
+		if d.Config.Allowlist.RegexAllowed(globalAllowlistTarget) {
+			d.suppress(fragment, finding, SuppressAllowlistRegex, "global")
 			continue
 		}
 
 		// check if the secret is in the list of stopwords
-		if rule.Allowlist.ContainsStopWord(finding.Secret) ||
-			d.Config.Allowlist.ContainsStopWord(finding.Secret) {
+		if rule.Allowlist.ContainsStopWord(finding.Secret) {
+			d.suppress(fragment, finding, SuppressStopWord, "rule")
+			continue
+		}
+		if d.Config.Allowlist.ContainsStopWord(finding.Secret) {
+			d.suppress(fragment, finding, SuppressStopWord, "global")
+			continue
+		}
+
+		// check if the hash of the secret is allowed
+		if rule.Allowlist.SecretHashAllowed(finding.Secret) {
+			d.suppress(fragment, finding, SuppressSecretHash, "rule")
+			continue
+		}
+		if d.Config.Allowlist.SecretHashAllowed(finding.Secret) {
+			d.suppress(fragment, finding, SuppressSecretHash, "global")
+			continue
+		}
+
+		// check if the secret is a reference to a secret stored in vault
+		if d.VaultAllowlist != nil && d.VaultAllowlist.Allowed(finding) {
+			d.suppress(fragment, finding, SuppressVault, "")
 			continue
 		}
 
@@ -360,45 +602,135 @@ func (d *Detector) detectRule(fragment Fragment, rule config.Rule) []report.Find
 			}
 		}
 
+		// check the structure of the secret
+		if rule.Validate != "" && !validSecret(rule.Validate, finding.Secret, fragment.Raw) {
+			continue
+		}
+		if rule.Validate == config.ValidateAWSSecretKey && d.AWSVerifier != nil {
+			finding.Verified = d.pendingVerification(finding.Secret, fragment.Raw)
+		}
+
 		findings = append(findings, finding)
 	}
 	return findings
 }
 
+// Findings returns the findings of every scan the detector has run so far.
+func (d *Detector) Findings() []report.Finding {
+	d.findingMutex.Lock()
+	defer d.findingMutex.Unlock()
+	return append([]report.Finding(nil), d.findings...)
+}
+
+// Reset forgets the findings, skipped files and scanned commits of previous
+// scans so a long running detector doesn't grow without bound. It must not be
+// called while a scan is running.
+func (d *Detector) Reset() {
+	d.findingMutex.Lock()
+	defer d.findingMutex.Unlock()
+	d.findings = make([]report.Finding, 0)
+	d.skipped = nil
+	d.suppressions = nil
+	d.commitMap = make(map[string]bool)
+	d.pendingVerifications.Range(func(key, _ interface{}) bool {
+		d.pendingVerifications.Delete(key)
+		return true
+	})
+	// the semgroup keeps the errors of every scan, start from a clean one
+	d.Sema = semgroup.NewGroup(context.Background(), maxWorkers)
+}
+
 // addFinding synchronously adds a finding to the findings slice
 func (d *Detector) addFinding(finding report.Finding) {
-	globalFingerprint := fmt.Sprintf("%s:%s:%d", finding.File, finding.RuleID, finding.StartLine)
-	if finding.Commit != "" {
-		finding.Fingerprint = fmt.Sprintf("%s:%s:%s:%d", finding.Commit, finding.File, finding.RuleID, finding.StartLine)
-	} else {
-		finding.Fingerprint = globalFingerprint
+	finding, ok := d.prepareFinding(finding)
+	if !ok {
+		d.discardVerification(finding)
+		return
+	}
+	finding = d.verify(finding)
+	if !d.confident(finding) {
+		return
+	}
+
+	d.findingMutex.Lock()
+	defer d.findingMutex.Unlock()
+	if d.MaxFindings > 0 && len(d.findings) >= d.MaxFindings {
+		return
+	}
+	d.findings = append(d.findings, finding)
+	if d.Verbose {
+		printFinding(finding, d.NoColor)
+	}
+	if len(d.findings) == d.MaxFindings {
+		log.Warn().Msgf("stopping the scan after %d findings, the results are incomplete", d.MaxFindings)
 	}
+}
+
+// confident returns false if the finding is below MinConfidence.
+func (d *Detector) confident(finding report.Finding) bool {
+	if d.MinConfidence == "" {
+		return true
+	}
+	d.configMutex.RLock()
+	rule := d.Config.Rules[finding.RuleID]
+	d.configMutex.RUnlock()
+	return report.LevelRank(confidence(finding, rule)) >= report.LevelRank(d.MinConfidence)
+}
+
+// FindingLimitReached returns true once MaxFindings findings are recorded and
+// the scan should stop.
+func (d *Detector) FindingLimitReached() bool {
+	d.findingMutex.Lock()
+	defer d.findingMutex.Unlock()
+	return d.MaxFindings > 0 && len(d.findings) >= d.MaxFindings
+}
+
+// prepareFinding sets the fingerprint of a finding and returns false if the
+// finding should be ignored because of the .gitleaksignore or baseline.
+func (d *Detector) prepareFinding(finding report.Finding) (report.Finding, bool) {
+	globalFingerprint := fmt.Sprintf("%s:%s:%d", finding.File, finding.RuleID, finding.StartLine)
+	finding.Fingerprint = fingerprint(finding)
 
 	// check if we should ignore this finding
-	if _, ok := d.gitleaksIgnore[globalFingerprint]; ok {
+	if d.ignored(globalFingerprint) {
 		log.Debug().Msgf("ignoring finding with global Fingerprint %s",
 			finding.Fingerprint)
-		return
+		d.addSuppression(Suppression{Finding: finding, Reason: SuppressIgnoreFile})
+		return finding, false
 	} else if finding.Commit != "" {
 		// Awkward nested if because I'm not sure how to chain these two conditions.
-		if _, ok := d.gitleaksIgnore[finding.Fingerprint]; ok {
+		if d.ignored(finding.Fingerprint) {
 			log.Debug().Msgf("ignoring finding with Fingerprint %s",
 				finding.Fingerprint)
-			return
+			d.addSuppression(Suppression{Finding: finding, Reason: SuppressIgnoreFile})
+			return finding, false
 		}
 	}
 
 	if d.baseline != nil && !IsNew(finding, d.baseline) {
 		log.Debug().Msgf("baseline duplicate -- ignoring finding with Fingerprint %s", finding.Fingerprint)
-		return
+		d.addSuppression(Suppression{Finding: finding, Reason: SuppressBaseline})
+		return finding, false
 	}
+	return finding, true
+}
 
-	d.findingMutex.Lock()
-	d.findings = append(d.findings, finding)
-	if d.Verbose {
-		printFinding(finding, d.NoColor)
+// fingerprint identifies a finding in .gitleaksignore files and baselines.
+func fingerprint(finding report.Finding) string {
+	if finding.Commit != "" {
+		return fmt.Sprintf("%s:%s:%s:%d", finding.Commit, finding.File, finding.RuleID, finding.StartLine)
 	}
-	d.findingMutex.Unlock()
+	return fmt.Sprintf("%s:%s:%d", finding.File, finding.RuleID, finding.StartLine)
+}
+
+// ignored returns true if the fingerprint is in the .gitleaksignore file.
+// Fingerprints and entries are compared with forward slashes so a single
+// .gitleaksignore works on every platform.
+func (d *Detector) ignored(fingerprint string) bool {
+	d.ignoreMutex.RLock()
+	defer d.ignoreMutex.RUnlock()
+	_, ok := d.gitleaksIgnore[config.SlashPath(fingerprint)]
+	return ok
 }
 
 // addCommit synchronously adds a commit to the commit slice
@@ -1,87 +1,197 @@
 package detect
 
 import (
+	"bytes"
 	"io"
+	"mime"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/h2non/filetype"
 	"github.com/rs/zerolog/log"
+	"golang.org/x/text/transform"
+
 	"github.com/zricethezav/gitleaks/v8/report"
 	"github.com/zricethezav/gitleaks/v8/sources"
 )
 
+// skippedMimeTypes are the binary file types that are never scanned.
+var skippedMimeTypes = map[string]bool{
+	"application": true,
+	"image":       true,
+	"font":        true,
+	"audio":       true,
+	"video":       true,
+}
+
 func (d *Detector) DetectFiles(paths <-chan sources.ScanTarget) ([]report.Finding, error) {
 	for pa := range paths {
 		p := pa
 		d.Sema.Go(func() error {
-
-			f, err := os.Open(p.Path)
-			if err != nil {
-				return err
+			// the rest of the paths are drained without being scanned
+			if d.FindingLimitReached() {
+				return nil
 			}
-			defer f.Close()
+			return d.detectFile(p, d.addFinding)
+		})
+	}
+
+	if err := d.Sema.Wait(); err != nil {
+		return d.findings, err
+	}
+
+	return d.findings, nil
+}
+
+// DetectFile scans a single file and returns its findings. Unlike DetectFiles,
+// the findings are not accumulated by the detector which makes it suitable for
+// repeatedly scanning files as they change.
+func (d *Detector) DetectFile(target sources.ScanTarget) ([]report.Finding, error) {
+	var findings []report.Finding
+	err := d.detectFile(target, func(finding report.Finding) {
+		finding, ok := d.prepareFinding(finding)
+		if !ok {
+			d.discardVerification(finding)
+			return
+		}
+		finding = d.verify(finding)
+		findings = append(findings, finding)
+		if d.Verbose {
+			printFinding(finding, d.NoColor)
+		}
+	})
+	return findings, err
+}
+
+// detectFile scans the file in chunks, calling addFinding for each finding.
+func (d *Detector) detectFile(p sources.ScanTarget, addFinding func(report.Finding)) error {
+	f, err := os.Open(p.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// Get file size
+	fileInfo, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	fileSize := fileInfo.Size()
+	if d.MaxTargetMegaBytes > 0 {
+		rawLength := fileSize / 1000000
+		if rawLength > int64(d.MaxTargetMegaBytes) {
+			log.Debug().Msgf("skipping file: %s scan due to size: %d", p.Path, rawLength)
+			return nil
+		}
+	}
+
+	var r io.Reader = f
+	// offsets in transcoded text aren't offsets in the file
+	transcoded := false
+	if d.Transcode {
+		sample := make([]byte, binarySniffLen)
+		n, err := io.ReadFull(f, sample)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		r = io.MultiReader(bytes.NewReader(sample[:n]), f)
+		if enc := textEncoding(sample[:n]); enc != nil {
+			log.Trace().Msgf("transcoding %s from %v", p.Path, enc)
+			r = transform.NewReader(r, enc.NewDecoder())
+			transcoded = true
+		}
+	}
 
-			// Get file size
-			fileInfo, err := f.Stat()
+	// Buffer to hold file chunks
+	buf := make([]byte, chunkSize)
+	totalLines := 0
+	// offset of the chunk in the file
+	offset := 0
+	checkedTargets := false
+	for {
+		n, err := r.Read(buf)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if n == 0 {
+			break
+		}
+
+		// only the first chunk is sniffed, file signatures live at the start
+		if !checkedTargets {
+			checkedTargets = true
+			if binaryContent(buf[:n]) {
+				log.Trace().Msgf("skipping binary file: %s", p.Path)
+				return nil
+			}
+			mimetype, err := filetype.Match(buf[:n])
 			if err != nil {
 				return err
 			}
-			fileSize := fileInfo.Size()
-			if d.MaxTargetMegaBytes > 0 {
-				rawLength := fileSize / 1000000
-				if rawLength > int64(d.MaxTargetMegaBytes) {
-					log.Debug().Msgf("skipping file: %s scan due to size: %d", p.Path, rawLength)
-					return nil
-				}
+			if skippedMimeTypes[mimetype.MIME.Type] {
+				return nil // skip binary files
 			}
-
-			// Buffer to hold file chunks
-			buf := make([]byte, chunkSize)
-			totalLines := 0
-			for {
-				n, err := f.Read(buf)
-				if err != nil && err != io.EOF {
-					return err
-				}
-				if n == 0 {
-					break
-				}
-
-				// TODO: optimization could be introduced here
-				mimetype, err := filetype.Match(buf[:n])
-				if err != nil {
-					return err
-				}
-				if mimetype.MIME.Type == "application" {
-					return nil // skip binary files
-				}
-
-				// Count the number of newlines in this chunk
-				linesInChunk := strings.Count(string(buf[:n]), "\n")
-				totalLines += linesInChunk
-				fragment := Fragment{
-					Raw:      string(buf[:n]),
-					FilePath: p.Path,
-				}
-				if p.Symlink != "" {
-					fragment.SymlinkFile = p.Symlink
-				}
-				for _, finding := range d.Detect(fragment) {
-					// need to add 1 since line counting starts at 1
-					finding.StartLine += (totalLines - linesInChunk) + 1
-					finding.EndLine += (totalLines - linesInChunk) + 1
-					d.addFinding(finding)
-				}
+			mimeType := mimetype.MIME.Value
+			if mimeType == "" {
+				mimeType = mime.TypeByExtension(filepath.Ext(p.Path))
+			}
+			if !d.targetAllowed(p.Path, mimeType) {
+				log.Trace().Msgf("skipping file: %s due to target filters", p.Path)
+				return nil
+			}
+			if d.SkipGenerated && generatedFile(p.Path, string(buf[:n])) {
+				d.addSkip("", p.Path, SkipGenerated)
+				return nil
 			}
+		}
 
-			return nil
-		})
+		// Count the number of newlines in this chunk
+		linesInChunk := strings.Count(string(buf[:n]), "\n")
+		totalLines += linesInChunk
+		fragment := Fragment{
+			Raw:      string(buf[:n]),
+			FilePath: p.Path,
+		}
+		if p.Symlink != "" {
+			fragment.SymlinkFile = p.Symlink
+		}
+		var suppressed []Suppression
+		if d.RecordSuppressions {
+			fragment.suppressed = &suppressed
+		}
+		for _, finding := range d.detect(fragment) {
+			if transcoded {
+				// the offsets are in the transcoded text
+				finding.StartOffset, finding.EndOffset = 0, 0
+			} else {
+				finding = withOffsets(finding, offset)
+			}
+			// need to add 1 since line counting starts at 1
+			finding.StartLine += (totalLines - linesInChunk) + 1
+			finding.EndLine += (totalLines - linesInChunk) + 1
+			addFinding(finding)
+		}
+		for _, s := range suppressed {
+			s.StartLine += (totalLines - linesInChunk) + 1
+			s.EndLine += (totalLines - linesInChunk) + 1
+			d.addSuppression(s)
+		}
+		offset += n
 	}
 
-	if err := d.Sema.Wait(); err != nil {
-		return d.findings, err
-	}
+	return nil
+}
 
-	return d.findings, nil
+// binarySniffLen is how much of a file is checked for NUL bytes, the same
+// amount git looks at to decide whether a file is binary.
+const binarySniffLen = 8000
+
+// binaryContent returns true if the start of a file contains a NUL byte.
+// This catches binaries filetype doesn't have a signature for.
+func binaryContent(buf []byte) bool {
+	if len(buf) > binarySniffLen {
+		buf = buf[:binarySniffLen]
+	}
+	return bytes.IndexByte(buf, 0) != -1
 }
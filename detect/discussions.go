@@ -0,0 +1,46 @@
+package detect
+
+import (
+	"time"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+	"github.com/zricethezav/gitleaks/v8/sources"
+)
+
+// DetectDiscussions scans issues, pull requests and comments and returns
+// their findings. The File and Link of a finding are the URL of the
+// discussion it was found in, its Author who wrote it and its Date when.
+func (d *Detector) DetectDiscussions(discussions []sources.Discussion) []report.Finding {
+	before := len(d.Findings())
+	for _, discussion := range discussions {
+		fragment := Fragment{
+			Raw:      discussion.Body,
+			FilePath: discussion.URL,
+		}
+		var suppressed []Suppression
+		if d.RecordSuppressions {
+			fragment.suppressed = &suppressed
+		}
+		augment := func(finding report.Finding) report.Finding {
+			finding.File = discussion.URL
+			finding.Link = discussion.URL
+			finding.Author = discussion.Author
+			if !discussion.Date.IsZero() {
+				finding.Date = discussion.Date.UTC().Format(time.RFC3339)
+			}
+			finding.Repository = d.Repository
+			return finding
+		}
+		for _, finding := range d.detect(fragment) {
+			d.addFinding(augment(finding))
+		}
+		for _, s := range suppressed {
+			s.Finding = augment(s.Finding)
+			d.addSuppression(s)
+		}
+		if d.FindingLimitReached() {
+			break
+		}
+	}
+	return d.Findings()[before:]
+}
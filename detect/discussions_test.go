@@ -0,0 +1,46 @@
+package detect
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+	"github.com/zricethezav/gitleaks/v8/sources"
+)
+
+func TestDetectDiscussions(t *testing.T) {
+	rule := config.Rule{
+		RuleID: "env-api-key",
+		Regex:  regexp.MustCompile(`API_KEY=(\w{16})`),
+	}
+	detector := NewDetector(config.Config{Rules: map[string]config.Rule{rule.RuleID: rule}})
+	detector.Repository = "acme/api"
+
+	findings := detector.DetectDiscussions([]sources.Discussion{
+		{
+			URL:    "https://github.com/acme/api/issues/7",
+			Author: "octocat",
+			Date:   time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC),
+			Body:   "Deploy fails\nrunning with API_KEY=b4Kx9pQ2mZ7vLr3T gives a 500",
+		},
+		{
+			URL:  "https://github.com/acme/api/issues/7#issuecomment-1",
+			Body: "works for me",
+		},
+	})
+	require.Len(t, findings, 1)
+	f := findings[0]
+	assert.Equal(t, "b4Kx9pQ2mZ7vLr3T", f.Secret)
+	assert.Equal(t, "https://github.com/acme/api/issues/7", f.File)
+	assert.Equal(t, f.File, f.Link)
+	assert.Equal(t, "octocat", f.Author)
+	assert.Equal(t, "2024-05-06T09:00:00Z", f.Date)
+	assert.Equal(t, "acme/api", f.Repository)
+	assert.Equal(t, "https://github.com/acme/api/issues/7:env-api-key:1", f.Fingerprint)
+	assert.Equal(t, 34, f.StartOffset)
+	assert.Equal(t, findings, detector.Findings())
+}
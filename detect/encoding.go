@@ -0,0 +1,136 @@
+package detect
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+var (
+	utf8BOM    = []byte{0xef, 0xbb, 0xbf}
+	utf16LEBOM = []byte{0xff, 0xfe}
+	utf16BEBOM = []byte{0xfe, 0xff}
+)
+
+// textEncoding guesses the encoding of text from sample, its start. nil is
+// returned for UTF-8 without a byte order mark, which needs no transcoding,
+// and for binary content.
+// UTF-8 and UTF-16 are recognized by their byte order mark, UTF-16 without
+// one by the NUL bytes of ASCII characters. Text that isn't valid UTF-8 is
+// taken for Shift-JIS if it looks like it, otherwise for Windows-1252, the
+// superset of Latin-1 legacy Windows tools write.
+func textEncoding(sample []byte) encoding.Encoding {
+	switch {
+	case bytes.HasPrefix(sample, utf8BOM):
+		return unicode.UTF8BOM
+	case bytes.HasPrefix(sample, utf16LEBOM):
+		return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)
+	case bytes.HasPrefix(sample, utf16BEBOM):
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
+	}
+	if order, ok := utf16Order(sample); ok {
+		return unicode.UTF16(order, unicode.IgnoreBOM)
+	}
+	if binaryContent(sample) || validUTF8Prefix(sample) {
+		return nil
+	}
+	if shiftJIS(sample) {
+		return japanese.ShiftJIS
+	}
+	return charmap.Windows1252
+}
+
+// utf16Order returns the byte order of sample if it looks like UTF-16
+// without a byte order mark: the high byte of most characters is NUL, as
+// for ASCII text, and the low byte never is.
+func utf16Order(sample []byte) (unicode.Endianness, bool) {
+	if len(sample) > binarySniffLen {
+		sample = sample[:binarySniffLen]
+	}
+	pairs := len(sample) / 2
+	if pairs == 0 {
+		return unicode.LittleEndian, false
+	}
+	var even, odd int
+	for i := 0; i+1 < len(sample); i += 2 {
+		if sample[i] == 0 {
+			even++
+		}
+		if sample[i+1] == 0 {
+			odd++
+		}
+	}
+	switch {
+	case odd*2 > pairs && even == 0:
+		return unicode.LittleEndian, true
+	case even*2 > pairs && odd == 0:
+		return unicode.BigEndian, true
+	}
+	return unicode.LittleEndian, false
+}
+
+// validUTF8Prefix returns true if sample is valid UTF-8, allowing for a
+// character cut off at its end.
+func validUTF8Prefix(sample []byte) bool {
+	for i := len(sample) - 1; i >= 0 && i >= len(sample)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(sample[i]) {
+			if !utf8.FullRune(sample[i:]) {
+				sample = sample[:i]
+			}
+			break
+		}
+	}
+	return utf8.Valid(sample)
+}
+
+// shiftJIS returns true if sample is valid Shift-JIS with double byte
+// characters, at least half of them kana, punctuation and common kanji,
+// starting with 0x81 to 0x9f, or with a second byte outside ASCII. The
+// accented letters of Latin-1 text can pass for the first byte of other
+// characters but are mostly followed by ASCII letters, spaces or
+// punctuation, which isn't valid Shift-JIS.
+func shiftJIS(sample []byte) bool {
+	var double, japanese int
+	for i := 0; i < len(sample); i++ {
+		b := sample[i]
+		switch {
+		case b < 0x80 || b >= 0xa1 && b <= 0xdf:
+			// ASCII and half width katakana
+		case b >= 0x81 && b <= 0x9f || b >= 0xe0 && b <= 0xfc:
+			if i+1 == len(sample) {
+				// cut off at the end
+				break
+			}
+			trail := sample[i+1]
+			if trail < 0x40 || trail == 0x7f || trail > 0xfc {
+				return false
+			}
+			double++
+			if b <= 0x9f || trail >= 0x80 {
+				japanese++
+			}
+			i++
+		default:
+			return false
+		}
+	}
+	return double > 0 && japanese*2 >= double
+}
+
+// transcode returns text converted to UTF-8 from the encoding textEncoding
+// guesses for it.
+func transcode(text string) string {
+	enc := textEncoding([]byte(text))
+	if enc == nil {
+		return text
+	}
+	decoded, err := enc.NewDecoder().String(text)
+	if err != nil {
+		return text
+	}
+	return decoded
+}
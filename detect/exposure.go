@@ -0,0 +1,76 @@
+package detect
+
+import (
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+	"github.com/zricethezav/gitleaks/v8/sources"
+)
+
+// CheckPublicExposure flags the findings of a git scan of the repository at
+// source whose commit is on a branch of a remote anyone can read, as far as
+// the remote-tracking branches of the repository know. Such secrets have to
+// be treated as leaked to the world, Classify makes them critical.
+func CheckPublicExposure(source string, findings []report.Finding) []report.Finding {
+	remotes, err := sources.Remotes(source)
+	if err != nil {
+		log.Debug().Err(err).Msg("could not list remotes")
+		return findings
+	}
+	var public []sources.Remote
+	for _, remote := range remotes {
+		if sources.PublicRemote(remote.URL) {
+			public = append(public, remote)
+		} else {
+			log.Debug().Msgf("remote %s is not public", remote.Name)
+		}
+	}
+	return flagPublicFindings(findings, public, func(commit string) []string {
+		branches, err := sources.RemoteBranchesContaining(source, commit)
+		if err != nil {
+			log.Debug().Err(err).Msgf("could not find the remote branches containing %s", commit)
+		}
+		return branches
+	})
+}
+
+// flagPublicFindings flags the findings whose commit is on a branch of one
+// of the public remotes. branches returns the remote-tracking branches
+// containing a commit.
+func flagPublicFindings(findings []report.Finding, public []sources.Remote, branches func(commit string) []string) []report.Finding {
+	if len(public) == 0 {
+		return findings
+	}
+	// the public remote each commit is on, empty if it isn't
+	exposedOn := make(map[string]string)
+	for i, f := range findings {
+		if f.Commit == "" {
+			continue
+		}
+		remote, ok := exposedOn[f.Commit]
+		if !ok {
+			remote = publicRemoteContaining(branches(f.Commit), public)
+			exposedOn[f.Commit] = remote
+		}
+		if remote != "" {
+			findings[i].PubliclyExposed = true
+			findings[i].PublicRemote = remote
+		}
+	}
+	return findings
+}
+
+// publicRemoteContaining returns the URL of the first public remote one of
+// the branches belongs to.
+func publicRemoteContaining(branches []string, public []sources.Remote) string {
+	for _, remote := range public {
+		for _, branch := range branches {
+			if strings.HasPrefix(branch, remote.Name+"/") {
+				return sources.RedactURL(remote.URL)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+package detect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+	"github.com/zricethezav/gitleaks/v8/sources"
+)
+
+func TestFlagPublicFindings(t *testing.T) {
+	findings := []report.Finding{
+		{Commit: "pushed", File: "a.go"},
+		{Commit: "fork-only", File: "b.go"},
+		{Commit: "local", File: "c.go"},
+		{File: "staged.go"},
+		{Commit: "pushed", File: "d.go"},
+	}
+	public := []sources.Remote{{Name: "origin", URL: "https://token@github.com/acme/api.git"}}
+	branches := map[string][]string{
+		"pushed":    {"fork/main", "origin/main"},
+		"fork-only": {"fork/main"},
+	}
+	lookups := 0
+	findings = flagPublicFindings(findings, public, func(commit string) []string {
+		lookups++
+		return branches[commit]
+	})
+
+	var exposed []string
+	for _, f := range findings {
+		if f.PubliclyExposed {
+			assert.Equal(t, "https://github.com/acme/api.git", f.PublicRemote)
+			exposed = append(exposed, f.File)
+		}
+	}
+	assert.Equal(t, []string{"a.go", "d.go"}, exposed)
+	// every commit is only looked up once
+	assert.Equal(t, 3, lookups)
+}
@@ -0,0 +1,91 @@
+package detect
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// minifiedLineLength is the average line length above which a script
+	// or stylesheet is considered minified. Hand written code rarely
+	// averages more than 100 characters per line.
+	minifiedLineLength = 500
+
+	// generatedHeaderLength is how much of the start of a file is searched
+	// for a generated code marker.
+	generatedHeaderLength = 1000
+)
+
+// generatedNames are files written by package managers.
+var generatedNames = map[string]bool{
+	"package-lock.json":   true,
+	"npm-shrinkwrap.json": true,
+	"yarn.lock":           true,
+	"pnpm-lock.yaml":      true,
+	"bun.lockb":           true,
+	"composer.lock":       true,
+	"Gemfile.lock":        true,
+	"Cargo.lock":          true,
+	"poetry.lock":         true,
+	"Pipfile.lock":        true,
+	"go.sum":              true,
+	"packages.lock.json":  true,
+	"mix.lock":            true,
+	"Podfile.lock":        true,
+	"pubspec.lock":        true,
+}
+
+// generatedSuffixes are minified assets, source maps and protobuf and gRPC
+// generated code.
+var generatedSuffixes = []string{
+	".min.js", ".min.css", ".min.mjs", ".js.map", ".css.map", ".mjs.map",
+	".pb.go", ".pb.gw.go", ".pb.cc", ".pb.h", ".pb.swift", ".pb.dart",
+	"_pb2.py", "_pb2_grpc.py", "_pb2.pyi", "_pb.js", "_pb.d.ts", "_grpc_pb.js",
+}
+
+// minifiableExtensions are the files checked for minified content.
+var minifiableExtensions = map[string]bool{
+	".js":  true,
+	".mjs": true,
+	".cjs": true,
+	".css": true,
+}
+
+// generatedMarker matches the comments code generators put at the top of
+// their output, like Go's "Code generated ... DO NOT EDIT." and @generated.
+var generatedMarker = regexp.MustCompile(`(?m)^\W*(?:Code generated .* DO NOT EDIT\.?|@generated\b|<auto-generated)`)
+
+// generatedFile returns true if the file at path is generated, minified or a
+// lockfile, going by its name and the start of its content. These files are
+// the main source of false positives in front-end repositories.
+func generatedFile(path string, content string) bool {
+	name := filepath.Base(filepath.ToSlash(path))
+	if generatedNames[name] {
+		return true
+	}
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+
+	header := content
+	if len(header) > generatedHeaderLength {
+		header = header[:generatedHeaderLength]
+	}
+	if generatedMarker.MatchString(header) {
+		return true
+	}
+	return minifiableExtensions[strings.ToLower(filepath.Ext(name))] && minified(content)
+}
+
+// minified returns true if the average line of content is longer than any
+// hand written code would be.
+func minified(content string) bool {
+	content = strings.TrimSpace(content)
+	if len(content) < minifiedLineLength {
+		return false
+	}
+	return len(content)/(strings.Count(content, "\n")+1) > minifiedLineLength
+}
@@ -0,0 +1,36 @@
+package detect
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratedFile(t *testing.T) {
+	minified := "var a=1;" + strings.Repeat("function f(){return \"x\"};", 100)
+	tests := []struct {
+		path      string
+		content   string
+		generated bool
+	}{
+		{path: "package-lock.json", generated: true},
+		{path: "web/yarn.lock", generated: true},
+		{path: "go.sum", generated: true},
+		{path: "static/app.min.js", generated: true},
+		{path: "static/app.js.map", generated: true},
+		{path: "api/v1/service.pb.go", generated: true},
+		{path: "api/service_pb2.py", generated: true},
+		{path: "main.go", content: "// Code generated by mockgen. DO NOT EDIT.\npackage mocks\n", generated: true},
+		{path: "schema.ts", content: "/**\n * @generated\n */\nexport type A = string\n", generated: true},
+		{path: "static/bundle.js", content: minified, generated: true},
+		{path: "static/bundle.js", content: strings.Repeat("const a = 1;\n", 100)},
+		{path: "config.json", content: minified},
+		{path: "main.go", content: "package main\n\n// Code generated by hand, do edit.\n"},
+		{path: "lock.go"},
+		{path: "package.json"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.generated, generatedFile(tt.path, tt.content), tt.path)
+	}
+}
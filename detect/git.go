@@ -1,17 +1,33 @@
 package detect
 
 import (
+	"mime"
+	"path/filepath"
+	"strings"
+
 	"github.com/gitleaks/go-gitdiff/gitdiff"
 	"github.com/rs/zerolog/log"
 	"github.com/zricethezav/gitleaks/v8/report"
 	"github.com/zricethezav/gitleaks/v8/sources"
 )
 
+const (
+	gitModeSymlink = 0o120000
+	gitModeGitlink = 0o160000
+
+	// maxPathDepth is the deepest directory nesting that is scanned, deeper
+	// paths are generated or crafted to slow down scans
+	maxPathDepth = 64
+)
+
 func (d *Detector) DetectGit(gitCmd *sources.GitCmd) ([]report.Finding, error) {
 	defer gitCmd.Wait()
 	diffFilesCh := gitCmd.DiffFilesCh()
 	errCh := gitCmd.ErrCh()
 
+	// number of files scanned in each commit
+	commitFiles := make(map[string]int)
+
 	// loop to range over both DiffFiles (stdout) and ErrCh (stderr)
 	for diffFilesCh != nil || errCh != nil {
 		select {
@@ -20,12 +36,21 @@ func (d *Detector) DetectGit(gitCmd *sources.GitCmd) ([]report.Finding, error) {
 				diffFilesCh = nil
 				break
 			}
+			if d.FindingLimitReached() {
+				gitCmd.Kill()
+				diffFilesCh, errCh = nil, nil
+				break
+			}
 
 			// skip binary files
 			if gitdiffFile.IsBinary || gitdiffFile.IsDelete {
 				continue
 			}
 
+			if !d.targetAllowed(gitdiffFile.NewName, mime.TypeByExtension(filepath.Ext(gitdiffFile.NewName))) {
+				continue
+			}
+
 			// Check if commit is allowed
 			commitSHA := ""
 			if gitdiffFile.PatchHeader != nil {
@@ -33,9 +58,28 @@ func (d *Detector) DetectGit(gitCmd *sources.GitCmd) ([]report.Finding, error) {
 				if d.Config.Allowlist.CommitAllowed(gitdiffFile.PatchHeader.SHA) {
 					continue
 				}
+				if !d.commitMap[commitSHA] {
+					d.detectAuthor(gitdiffFile.PatchHeader)
+				}
 			}
 			d.addCommit(commitSHA)
 
+			if reason := d.gitSkipReason(gitdiffFile, commitFiles[commitSHA]); reason != "" {
+				if reason == SkipCommitFileLimit && commitFiles[commitSHA] == d.MaxCommitFiles {
+					log.Warn().Msgf("commit %s changes more than %d files, skipping the rest", commitSHA, d.MaxCommitFiles)
+				}
+				commitFiles[commitSHA]++
+				d.addSkip(commitSHA, gitdiffFile.NewName, reason)
+				continue
+			}
+			commitFiles[commitSHA]++
+
+			if pointer, ok := sources.ParseLFSPointer(addedText(gitdiffFile)); ok {
+				d.detectLFS(gitCmd.Dir(), commitSHA, gitdiffFile, pointer)
+				continue
+			}
+
+			blob := blobContent(gitCmd.Dir(), commitSHA, gitdiffFile.NewName)
 			d.Sema.Go(func() error {
 				for _, textFragment := range gitdiffFile.TextFragments {
 					if textFragment == nil {
@@ -47,9 +91,34 @@ func (d *Detector) DetectGit(gitCmd *sources.GitCmd) ([]report.Finding, error) {
 						CommitSHA: commitSHA,
 						FilePath:  gitdiffFile.NewName,
 					}
+					if d.Transcode {
+						fragment.Raw = transcode(fragment.Raw)
+					}
+					var suppressed []Suppression
+					if d.RecordSuppressions {
+						fragment.suppressed = &suppressed
+					}
 
-					for _, finding := range d.Detect(fragment) {
-						d.addFinding(augmentGitFinding(finding, textFragment, gitdiffFile))
+					augment := func(finding report.Finding) report.Finding {
+						fragmentLine := finding.StartLine
+						finding = augmentGitFinding(finding, textFragment, gitdiffFile)
+						finding.Repository = d.Repository
+						finding.RemoteURL = d.RemoteURL
+						finding.Link = report.Permalink(d.RemoteURL, finding.Commit, finding.File, finding.StartLine, finding.EndLine)
+						if content, ok := blob(); ok {
+							// the lines of the finding count from 1 in the file
+							finding = withLineOffsets(finding, fragment.Raw, fragmentLine, content, finding.StartLine-1)
+						} else {
+							finding.StartOffset, finding.EndOffset = 0, 0
+						}
+						return finding
+					}
+					for _, finding := range d.detect(fragment) {
+						d.addFinding(augment(finding))
+					}
+					for _, s := range suppressed {
+						s.Finding = augment(s.Finding)
+						d.addSuppression(s)
 					}
 				}
 				return nil
@@ -71,3 +140,33 @@ func (d *Detector) DetectGit(gitCmd *sources.GitCmd) ([]report.Finding, error) {
 	log.Debug().Msg("Note: this number might be smaller than expected due to commits with no additions")
 	return d.findings, nil
 }
+
+// gitSkipReason returns why a changed file should not be scanned, or an empty
+// string if it should be. scanned is the number of files of the same commit
+// that came before it.
+func (d *Detector) gitSkipReason(f *gitdiff.File, scanned int) string {
+	switch {
+	case f.NewMode == gitModeSymlink:
+		// the content of a symlink is the path it points to
+		return SkipSymlink
+	case f.NewMode == gitModeGitlink:
+		// submodules are a commit hash, scan the submodule's repository instead
+		return SkipSubmodule
+	case strings.Count(f.NewName, "/") >= maxPathDepth:
+		return SkipPathTooDeep
+	case d.SkipGenerated && generatedFile(f.NewName, addedText(f)):
+		return SkipGenerated
+	case d.MaxCommitFiles > 0 && scanned >= d.MaxCommitFiles:
+		return SkipCommitFileLimit
+	}
+	return ""
+}
+
+// addedText returns the lines added in the first fragment of a changed file,
+// the start of the file when it was added.
+func addedText(f *gitdiff.File) string {
+	if len(f.TextFragments) == 0 || f.TextFragments[0] == nil {
+		return ""
+	}
+	return f.TextFragments[0].Raw(gitdiff.OpAdd)
+}
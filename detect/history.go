@@ -0,0 +1,149 @@
+package detect
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+	"github.com/zricethezav/gitleaks/v8/sources"
+)
+
+// AnalyzeHistory annotates findings from a git scan with the commit that first
+// introduced each secret and whether the secret is still present at HEAD.
+// Presence is determined by re-scanning the HEAD version of every file that
+// contains a finding and comparing secret digests, so it works the same for
+// redacted findings. Findings whose file still holds the secret at HEAD get
+// the line it is on there. Secrets that are gone from HEAD are also annotated
+// with the commit that removed them, and every secret with how long it was
+// exposed.
+func (d *Detector) AnalyzeHistory(source string, findings []report.Finding) []report.Finding {
+	introducedIn := make(map[string]report.Finding)
+	for _, f := range findings {
+		digest := f.SecretDigest()
+		first, ok := introducedIn[digest]
+		if !ok || commitTime(f) < commitTime(first) {
+			introducedIn[digest] = f
+		}
+	}
+
+	versions := make(map[string]map[string]report.Finding)
+	atHead := make(map[string]bool)
+	for _, f := range findings {
+		if f.File == "" {
+			continue
+		}
+		for digest := range d.secretsAt(source, "HEAD", f.File, versions) {
+			atHead[digest] = true
+		}
+	}
+
+	// a secret is exposed until it is deleted from the last file holding it
+	removals := make(map[string]sources.FileCommit)
+	checked := make(map[string]bool)
+	for _, f := range findings {
+		digest := f.SecretDigest()
+		introduced := introducedIn[digest].Commit
+		if f.File == "" || introduced == "" || atHead[digest] || checked[digest+"\x00"+f.File] {
+			continue
+		}
+		checked[digest+"\x00"+f.File] = true
+		removal, ok := d.removal(source, introduced, f.File, digest, versions)
+		if ok && removal.Date.After(removals[digest].Date) {
+			removals[digest] = removal
+		}
+	}
+
+	now := time.Now()
+	for i, f := range findings {
+		digest := f.SecretDigest()
+		present := atHead[digest]
+		findings[i].IntroducedIn = introducedIn[digest].Commit
+		findings[i].PresentAtHead = &present
+		if f.File != "" {
+			if head, ok := d.secretsAt(source, "HEAD", f.File, versions)[digest]; ok {
+				// detect counts lines from 0
+				findings[i].HeadLine = head.StartLine + 1
+				findings[i].HeadLineContent = strings.Trim(head.Line, "\r\n")
+			}
+		}
+
+		introduced, err := time.Parse(time.RFC3339, commitTime(introducedIn[digest]))
+		if err != nil {
+			continue
+		}
+		end := now
+		if removal, ok := removals[digest]; ok {
+			findings[i].RemovedIn = removal.Commit
+			findings[i].RemovedDate = removal.Date.Format(time.RFC3339)
+			end = removal.Date
+		} else if !present {
+			// the commit removing it couldn't be found
+			continue
+		}
+		findings[i].ExposureDays = exposureDays(end.Sub(introduced))
+	}
+	return findings
+}
+
+// removal returns the last commit since introducedIn that deleted the secret
+// with the digest from file. The file is re-scanned at every commit that
+// changed it, false is returned if none of them deleted the secret.
+func (d *Detector) removal(source string, introducedIn string, file string, digest string, versions map[string]map[string]report.Finding) (sources.FileCommit, bool) {
+	commits, err := sources.FileHistory(source, introducedIn, "HEAD", file)
+	if err != nil {
+		log.Debug().Err(err).Msg("")
+		return sources.FileCommit{}, false
+	}
+	var removal sources.FileCommit
+	found := false
+	_, present := d.secretsAt(source, introducedIn, file, versions)[digest]
+	for _, commit := range commits {
+		_, has := d.secretsAt(source, commit.Commit, file, versions)[digest]
+		if present && !has {
+			removal, found = commit, true
+		}
+		present = has
+	}
+	return removal, found
+}
+
+// secretsAt returns the first finding of each secret in file as of ref by
+// the digest of the secret. Versions are scanned once and kept in versions.
+func (d *Detector) secretsAt(source string, ref string, file string, versions map[string]map[string]report.Finding) map[string]report.Finding {
+	key := ref + ":" + file
+	if secrets, ok := versions[key]; ok {
+		return secrets
+	}
+	secrets := make(map[string]report.Finding)
+	versions[key] = secrets
+	content, err := sources.FileAtRef(source, ref, file)
+	if err != nil {
+		// the file doesn't exist at ref
+		log.Trace().Msgf("%s not present at %s", file, ref)
+		return secrets
+	}
+	for _, finding := range d.detectUnverified(Fragment{Raw: content, FilePath: file}) {
+		if _, ok := secrets[finding.SecretDigest()]; !ok {
+			secrets[finding.SecretDigest()] = finding
+		}
+	}
+	return secrets
+}
+
+// exposureDays converts how long a secret was exposed to days, rounded to
+// a thousandth of a day so secrets removed within minutes still show.
+func exposureDays(exposed time.Duration) float64 {
+	return math.Round(exposed.Hours()/24*1000) / 1000
+}
+
+// commitTime returns the time a finding was committed in a format that can be
+// compared lexically.
+func commitTime(f report.Finding) string {
+	if f.CommitDate != "" {
+		return f.CommitDate
+	}
+	return f.Date
+}
@@ -0,0 +1,130 @@
+package detect
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+// heuristicRule describes the findings of a built in detector that isn't
+// driven by a config rule.
+type heuristicRule struct {
+	RuleID      string
+	Description string
+	Tags        []string
+}
+
+// heuristicRules are the built in detectors that --enable-rule and
+// --disable-rule filter like config rules.
+var heuristicRules = []heuristicRule{kubernetesSecretRule, helmValuesRule, keyValueRule, terraformRule}
+
+// HeuristicRuleIDs returns the rule IDs of the built in detectors.
+func HeuristicRuleIDs() []string {
+	ids := make([]string, len(heuristicRules))
+	for i, rule := range heuristicRules {
+		ids[i] = rule.RuleID
+	}
+	return ids
+}
+
+// sensitiveKeyPattern matches key names that usually hold a credential.
+var sensitiveKeyPattern = regexp.MustCompile(`(?i)(passw(?:or)?d|pwd|secret|token|api[_.-]?key|access[_.-]?key|private[_.-]?key|credentials?)$`)
+
+// referenceKeyPattern matches key names that point to a secret stored
+// elsewhere instead of holding one, ex: existingSecret.
+var referenceKeyPattern = regexp.MustCompile(`(?i)^(existing|use|create|generate)`)
+
+// placeholderPattern matches values that are clearly not real secrets.
+var placeholderPattern = regexp.MustCompile(`(?i)^(null|none|nil|true|false|changeme|change[_-]?me|password|secret|token|example|dummy|redacted|your[_-].*|x+|\*+|\.+|<[^>]*>)$`)
+
+// sensitiveKey returns true if the key name suggests its value is a secret.
+func sensitiveKey(key string) bool {
+	key = strings.TrimSpace(key)
+	return sensitiveKeyPattern.MatchString(key) && !referenceKeyPattern.MatchString(key)
+}
+
+// placeholderValue returns true if value is empty, a placeholder or a
+// reference to a variable or template that is resolved at runtime.
+func placeholderValue(value string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" || placeholderPattern.MatchString(value) {
+		return true
+	}
+	// templates and environment variables, ex: {{ .Values.password }}, ${DB_PASSWORD}
+	for _, ref := range []string{"{{", "${", "$("} {
+		if strings.Contains(value, ref) {
+			return true
+		}
+	}
+	return strings.HasPrefix(value, "$") && strings.ToUpper(value) == value
+}
+
+// keyValueFinding returns a finding for a secret assigned to a sensitive key.
+func (d *Detector) keyValueFinding(fragment Fragment, loc Location, key string, secret string, rule heuristicRule, covered map[int]bool) []report.Finding {
+	if !sensitiveKey(key) {
+		return nil
+	}
+	return d.heuristicFinding(fragment, loc, key, secret, rule, covered)
+}
+
+// heuristicFinding returns a finding for a secret found by a built in
+// detector. Nothing is returned if the detector is disabled, the value is a
+// placeholder or on a line in covered, which holds the lines already reported
+// by the rules. Allowed values are suppressed like the matches of rules.
+func (d *Detector) heuristicFinding(fragment Fragment, loc Location, key string, secret string, rule heuristicRule, covered map[int]bool) []report.Finding {
+	if d.DisabledHeuristics[rule.RuleID] || placeholderValue(secret) || covered[loc.startLine] {
+		return nil
+	}
+	line := fragment.Raw[loc.startLineIndex:loc.endLineIndex]
+	finding := report.Finding{
+		Description: rule.Description,
+		File:        fragment.FilePath,
+		SymlinkFile: fragment.SymlinkFile,
+		RuleID:      rule.RuleID,
+		StartLine:   loc.startLine,
+		EndLine:     loc.endLine,
+		StartColumn: loc.startColumn,
+		EndColumn:   loc.endColumn,
+		Secret:      secret,
+		Match:       fmt.Sprintf("%s: %s", key, secret),
+		Line:        line,
+		Entropy:     float32(shannonEntropy(secret)),
+		Tags:        rule.Tags,
+	}
+
+	if strings.Contains(line, gitleaksAllowSignature) && !d.IgnoreGitleaksAllow {
+		d.suppress(fragment, finding, SuppressAllowComment, "")
+		return nil
+	}
+	if d.Config.Allowlist.RegexAllowed(secret) {
+		d.suppress(fragment, finding, SuppressAllowlistRegex, "global")
+		return nil
+	}
+	if d.Config.Allowlist.ContainsStopWord(secret) {
+		d.suppress(fragment, finding, SuppressStopWord, "global")
+		return nil
+	}
+	if d.Config.Allowlist.SecretHashAllowed(secret) {
+		d.suppress(fragment, finding, SuppressSecretHash, "global")
+		return nil
+	}
+	if d.VaultAllowlist != nil && d.VaultAllowlist.Allowed(finding) {
+		d.suppress(fragment, finding, SuppressVault, "")
+		return nil
+	}
+	if d.ContextLines > 0 {
+		finding.ContextBefore, finding.ContextAfter = surroundingLines(fragment.Raw, loc, d.ContextLines)
+	}
+	return []report.Finding{finding}
+}
+
+// coveredLines returns the start lines of findings.
+func coveredLines(findings []report.Finding) map[int]bool {
+	covered := make(map[int]bool, len(findings))
+	for _, f := range findings {
+		covered[f.StartLine] = true
+	}
+	return covered
+}
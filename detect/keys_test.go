@@ -0,0 +1,50 @@
+package detect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+)
+
+func TestSensitiveKey(t *testing.T) {
+	for _, key := range []string{"password", "DB_PASSWORD", "passwd", "client_secret", "apiKey", "api-key", "githubToken", "aws_access_key", "credentials"} {
+		assert.True(t, sensitiveKey(key), key)
+	}
+	for _, key := range []string{"username", "existingSecret", "secretName", "tokenPath", "createSecret"} {
+		assert.False(t, sensitiveKey(key), key)
+	}
+}
+
+func TestPlaceholderValue(t *testing.T) {
+	for _, value := range []string{"", "changeme", "<password>", "${DB_PASSWORD}", "{{ .Values.token }}", "xxxxxx", "****", "$PASSWORD", "your-api-key"} {
+		assert.True(t, placeholderValue(value), value)
+	}
+	for _, value := range []string{"Zx8#qL2!vR7m", "hunter2", "$ecretValue1"} {
+		assert.False(t, placeholderValue(value), value)
+	}
+}
+
+func TestHeuristicFindingFiltered(t *testing.T) {
+	raw := "DB_PASSWORD=Zx8#qL2!vR7m\nAPI_TOKEN=dummy-token-value\nSESSION_SECRET=k3y-value # gitleaks:allow\n"
+	cfg := config.Config{Allowlist: config.Allowlist{StopWords: []string{"dummy"}}}
+
+	detector := NewDetector(cfg)
+	detector.KeyValueAware = true
+	var suppressed []Suppression
+	findings := detector.Detect(Fragment{Raw: raw, FilePath: ".env", suppressed: &suppressed})
+	require.Len(t, findings, 1)
+	assert.Equal(t, "Zx8#qL2!vR7m", findings[0].Secret)
+
+	// allowed values are suppressed like the matches of rules
+	require.Len(t, suppressed, 2)
+	assert.Equal(t, keyValueRule.RuleID, suppressed[0].RuleID)
+	assert.Equal(t, SuppressStopWord, suppressed[0].Reason)
+	assert.Equal(t, "global", suppressed[0].Allowlist)
+	assert.Equal(t, SuppressAllowComment, suppressed[1].Reason)
+
+	detector.DisabledHeuristics = map[string]bool{keyValueRule.RuleID: true}
+	assert.Empty(t, detector.Detect(Fragment{Raw: raw, FilePath: ".env"}))
+}
@@ -0,0 +1,93 @@
+package detect
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+var keyValueRule = heuristicRule{
+	RuleID:      "config-file-secret",
+	Description: "Found a value assigned to a sensitive key in a configuration file.",
+	Tags:        []string{"config"},
+}
+
+// keyValueFilePattern matches .env, .properties, .ini and .tfvars files,
+// ex: .env.production, app.properties, prod.tfvars
+var keyValueFilePattern = regexp.MustCompile(`(?i)(^|/)(\.env(\.[\w.-]+)?|[\w.-]+\.(env|properties|ini|cfg|tfvars))$`)
+
+// assignmentPattern matches `KEY=value`, `export KEY=value`,
+// `key = "value"` and `key: value` assignments.
+var assignmentPattern = regexp.MustCompile(`^\s*(?:export\s+)?([A-Za-z0-9_.\-]+)\s*[=:]\s*(.*?)\s*$`)
+
+// detectKeyValues flags values assigned to sensitive keys in config style
+// files. findings are the findings already made by the rules so the same
+// line isn't reported twice.
+func (d *Detector) detectKeyValues(fragment Fragment, findings []report.Finding) []report.Finding {
+	if !d.KeyValueAware || !keyValueFilePattern.MatchString(filepath.ToSlash(fragment.FilePath)) {
+		return nil
+	}
+
+	fragment.newlineIndices = regexp.MustCompile("\n").FindAllStringIndex(fragment.Raw, -1)
+	covered := coveredLines(findings)
+
+	var kvFindings []report.Finding
+	lineStart := 0
+	for _, line := range strings.Split(fragment.Raw, "\n") {
+		offset := lineStart
+		lineStart += len(line) + 1
+		if commentLine(line) {
+			continue
+		}
+		m := assignmentPattern.FindStringSubmatchIndex(line)
+		if m == nil {
+			continue
+		}
+		start, end, ok := assignedValue(line, m[4], m[5])
+		if !ok {
+			continue
+		}
+		loc := location(fragment, []int{offset + start, offset + end})
+		kvFindings = append(kvFindings, d.keyValueFinding(fragment, loc, line[m[2]:m[3]], line[start:end], keyValueRule, covered)...)
+	}
+	return kvFindings
+}
+
+// commentLine returns true for comments in .env, .properties, .ini and
+// .tfvars files.
+func commentLine(line string) bool {
+	line = strings.TrimSpace(line)
+	for _, prefix := range []string{"#", ";", "!", "//"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// assignedValue returns the bounds of the value between start and end with
+// quotes and trailing comments removed. Lists, maps and heredocs are not
+// single values and are skipped.
+func assignedValue(line string, start int, end int) (int, int, bool) {
+	value := line[start:end]
+	if value == "" || strings.HasPrefix(value, "[") || strings.HasPrefix(value, "{") || strings.HasPrefix(value, "<<") {
+		return 0, 0, false
+	}
+	if quote := value[0]; quote == '"' || quote == '\'' {
+		closing := strings.IndexByte(value[1:], quote)
+		if closing < 0 {
+			return 0, 0, false
+		}
+		return start + 1, start + 1 + closing, true
+	}
+	// inline comments must be preceded by whitespace, `#` is common in passwords
+	for _, comment := range []string{" #", "\t#", " ;"} {
+		if i := strings.Index(value, comment); i >= 0 {
+			end = start + len(strings.TrimSpace(value[:i]))
+			value = line[start:end]
+		}
+	}
+	return start, end, value != ""
+}
@@ -0,0 +1,68 @@
+package detect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+)
+
+func TestDetectKeyValues(t *testing.T) {
+	tests := map[string]struct {
+		filePath string
+		raw      string
+		secrets  []string
+	}{
+		"env": {
+			filePath: ".env.production",
+			raw: `# database
+DB_HOST=db.internal
+DB_PASSWORD=Zx8#qL2!vR7m
+export API_KEY="k3y-with-quotes"
+SESSION_SECRET=${SESSION_SECRET}
+STRIPE_TOKEN=changeme # set in ci
+`,
+			secrets: []string{"Zx8#qL2!vR7m", "k3y-with-quotes"},
+		},
+		"properties": {
+			filePath: "src/main/resources/application.properties",
+			raw: `spring.datasource.username=app
+spring.datasource.password: s3cr3t-value ; rotated yearly
+! mail.password=old-password
+`,
+			secrets: []string{"s3cr3t-value"},
+		},
+		"tfvars": {
+			filePath: "infra/prod.tfvars",
+			raw: `region      = "us-east-1"
+db_password = "Zx8#qL2!vR7m"
+admin_token = <<EOT
+EOT
+allowed_secrets = ["a", "b"]
+`,
+			secrets: []string{"Zx8#qL2!vR7m"},
+		},
+		"not a config file": {
+			filePath: "main.go",
+			raw:      `password = "Zx8#qL2!vR7m"`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			detector := NewDetector(config.Config{})
+			assert.Empty(t, detector.Detect(Fragment{Raw: tt.raw, FilePath: tt.filePath}))
+
+			detector.KeyValueAware = true
+			findings := detector.Detect(Fragment{Raw: tt.raw, FilePath: tt.filePath})
+			require.Len(t, findings, len(tt.secrets))
+			for i, f := range findings {
+				assert.Equal(t, keyValueRule.RuleID, f.RuleID)
+				assert.Equal(t, tt.secrets[i], f.Secret)
+				assert.Contains(t, f.Line, tt.secrets[i])
+			}
+		})
+	}
+}
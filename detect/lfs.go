@@ -0,0 +1,52 @@
+package detect
+
+import (
+	"github.com/gitleaks/go-gitdiff/gitdiff"
+	"github.com/h2non/filetype"
+	"github.com/rs/zerolog/log"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+	"github.com/zricethezav/gitleaks/v8/sources"
+)
+
+// detectLFS scans the large file that a Git LFS pointer, changed in f of a
+// commit of the repository at dir, stands in for. The pointer itself holds
+// nothing worth scanning. Without FetchLFS, or for objects larger than
+// MaxLFSMegaBytes, the file is skipped instead.
+func (d *Detector) detectLFS(dir string, commitSHA string, f *gitdiff.File, pointer sources.LFSPointer) {
+	switch {
+	case !d.FetchLFS || dir == "":
+		d.addSkip(commitSHA, f.NewName, SkipLFSPointer)
+		return
+	case d.MaxLFSMegaBytes > 0 && pointer.Size > int64(d.MaxLFSMegaBytes)*1_000_000:
+		d.addSkip(commitSHA, f.NewName, SkipLFSTooLarge)
+		return
+	}
+
+	d.Sema.Go(func() error {
+		content, err := sources.LFSObject(dir, pointer)
+		if err != nil {
+			log.Warn().Err(err).Msgf("could not scan %s in commit %s", f.NewName, commitSHA)
+			d.addSkip(commitSHA, f.NewName, SkipLFSUnavailable)
+			return nil
+		}
+		if binaryContent(content) {
+			return nil
+		}
+		if kind, err := filetype.Match(content); err == nil && skippedMimeTypes[kind.MIME.Type] {
+			return nil
+		}
+
+		fragment := Fragment{Raw: string(content), CommitSHA: commitSHA, FilePath: f.NewName}
+		// the object is scanned whole, its lines count from the start
+		whole := &gitdiff.TextFragment{NewPosition: 1}
+		for _, finding := range d.detect(fragment) {
+			finding = augmentGitFinding(finding, whole, f)
+			finding.Repository = d.Repository
+			finding.RemoteURL = d.RemoteURL
+			finding.Link = report.Permalink(d.RemoteURL, finding.Commit, finding.File, finding.StartLine, finding.EndLine)
+			d.addFinding(finding)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,156 @@
+package detect
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+// codeSyntax is how comments and string literals are written in a family of
+// programming languages.
+type codeSyntax struct {
+	lineComments  []string
+	blockComments [][2]string
+	// quotes start and end string literals on a single line, multiline
+	// quotes ones that can span lines.
+	quotes          string
+	multilineQuotes string
+	tripleQuotes    bool
+}
+
+var (
+	cSyntax = codeSyntax{
+		lineComments:    []string{"//"},
+		blockComments:   [][2]string{{"/*", "*/"}},
+		quotes:          `"'`,
+		multilineQuotes: "`",
+	}
+	hashSyntax = codeSyntax{
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+	}
+	pythonSyntax = codeSyntax{
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+		tripleQuotes: true,
+	}
+	phpSyntax = codeSyntax{
+		lineComments:  []string{"//", "#"},
+		blockComments: [][2]string{{"/*", "*/"}},
+		quotes:        `"'`,
+	}
+	sqlSyntax = codeSyntax{
+		lineComments:  []string{"--"},
+		blockComments: [][2]string{{"/*", "*/"}},
+		quotes:        `'"`,
+	}
+)
+
+// codeSyntaxes are the languages that are tokenized by their extension.
+var codeSyntaxes = map[string]codeSyntax{
+	".go": cSyntax, ".c": cSyntax, ".h": cSyntax, ".cc": cSyntax, ".cpp": cSyntax, ".hpp": cSyntax,
+	".cs": cSyntax, ".java": cSyntax, ".kt": cSyntax, ".kts": cSyntax, ".scala": cSyntax, ".groovy": cSyntax,
+	".js": cSyntax, ".jsx": cSyntax, ".mjs": cSyntax, ".cjs": cSyntax, ".ts": cSyntax, ".tsx": cSyntax,
+	".swift": cSyntax, ".rs": cSyntax, ".dart": cSyntax,
+	".py": pythonSyntax,
+	".rb": hashSyntax, ".sh": hashSyntax, ".bash": hashSyntax, ".zsh": hashSyntax, ".pl": hashSyntax, ".r": hashSyntax,
+	".php": phpSyntax,
+	".sql": sqlSyntax,
+}
+
+// literalFindings drops the findings whose secret is outside of the string
+// literals and comments of a source file, like identifiers and hashes in
+// code. Hardcoded credentials are string literals or sit in comments. The
+// rules still see the whole fragment so the keywords and assignments around
+// a literal are matched as usual. Files in languages that aren't tokenized
+// keep all of their findings.
+func literalFindings(fragment Fragment, findings []report.Finding) []report.Finding {
+	syntax, ok := codeSyntaxes[strings.ToLower(filepath.Ext(fragment.FilePath))]
+	if !ok || len(findings) == 0 {
+		return findings
+	}
+	lines := strings.Split(literalText(fragment.Raw, syntax), "\n")
+	var kept []report.Finding
+	for _, f := range findings {
+		if f.Secret == "" || f.StartLine < 0 || f.EndLine >= len(lines) ||
+			strings.Contains(strings.Join(lines[f.StartLine:f.EndLine+1], "\n"), f.Secret) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// literalText returns code with everything but its string literals and
+// comments replaced by spaces. Newlines are kept so lines and columns don't
+// change. Scanning diffs, a fragment can start inside a multiline literal,
+// single line literals end at the end of their line to limit the damage.
+func literalText(code string, syntax codeSyntax) string {
+	out := []byte(code)
+	blank := func(from, to int) {
+		for i := from; i < to && i < len(out); i++ {
+			if out[i] != '\n' {
+				out[i] = ' '
+			}
+		}
+	}
+
+	i := 0
+	for i < len(code) {
+		rest := code[i:]
+		// the end of the literal or comment starting at i, -1 if there's none
+		end := -1
+		for _, prefix := range syntax.lineComments {
+			if strings.HasPrefix(rest, prefix) {
+				end = lineEnd(code, i)
+			}
+		}
+		for _, block := range syntax.blockComments {
+			if end == -1 && strings.HasPrefix(rest, block[0]) {
+				end = closingIndex(code, i+len(block[0]), block[1], false)
+			}
+		}
+		if end == -1 && syntax.tripleQuotes && (strings.HasPrefix(rest, `"""`) || strings.HasPrefix(rest, "'''")) {
+			end = closingIndex(code, i+3, rest[:3], true)
+		}
+		if end == -1 && strings.IndexByte(syntax.multilineQuotes, code[i]) >= 0 {
+			end = closingIndex(code, i+1, code[i:i+1], true)
+		}
+		if end == -1 && strings.IndexByte(syntax.quotes, code[i]) >= 0 {
+			end = closingIndex(code[:lineEnd(code, i)], i+1, code[i:i+1], true)
+		}
+
+		if end == -1 {
+			blank(i, i+1)
+			i++
+			continue
+		}
+		i = end
+	}
+	return string(out)
+}
+
+// closingIndex returns the index after the first closing delimiter in code
+// at or after start, or the end of code if it isn't closed. Delimiters
+// escaped with a backslash are skipped if escapes is set.
+func closingIndex(code string, start int, closing string, escapes bool) int {
+	for i := start; i < len(code); i++ {
+		if escapes && code[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(code[i:], closing) {
+			return i + len(closing)
+		}
+	}
+	return len(code)
+}
+
+// lineEnd returns the index of the newline ending the line i is on, or the
+// end of code.
+func lineEnd(code string, i int) int {
+	if n := strings.IndexByte(code[i:], '\n'); n >= 0 {
+		return i + n
+	}
+	return len(code)
+}
@@ -0,0 +1,164 @@
+package detect
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+const encodingUnicode = "unicode"
+
+// zeroWidth are invisible characters that can be inserted into a secret to
+// break up keywords and regexes.
+var zeroWidth = map[rune]bool{
+	'\u200b': true, // zero width space
+	'\u200c': true, // zero width non-joiner
+	'\u200d': true, // zero width joiner
+	'\u2060': true, // word joiner
+	'\u00ad': true, // soft hyphen
+	'\ufeff': true, // zero width no-break space
+}
+
+// confusables maps letters that look like ASCII letters to the letter they
+// imitate. NFKC already folds fullwidth and mathematical letters.
+var confusables = map[rune]rune{
+	// cyrillic
+	'а': 'a', 'в': 'B', 'е': 'e', 'к': 'k', 'м': 'M', 'н': 'H', 'о': 'o', 'р': 'p', 'с': 'c', 'т': 'T',
+	'у': 'y', 'х': 'x', 'ѕ': 's', 'і': 'i', 'ј': 'j', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w',
+	'А': 'A', 'В': 'B', 'Е': 'E', 'К': 'K', 'М': 'M', 'Н': 'H', 'О': 'O', 'Р': 'P', 'С': 'C', 'Т': 'T',
+	'Х': 'X', 'Ѕ': 'S', 'І': 'I', 'Ј': 'J',
+	// greek
+	'α': 'a', 'ο': 'o', 'ρ': 'p', 'ν': 'v', 'ι': 'i', 'κ': 'k',
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I', 'Κ': 'K', 'Μ': 'M', 'Ν': 'N', 'Ο': 'O',
+	'Ρ': 'P', 'Τ': 'T', 'Υ': 'Y', 'Χ': 'X',
+}
+
+// normalizedText is text with zero width characters removed, NFKC applied
+// and confusable letters replaced. start and end map every byte of text to
+// the bytes of the original text it came from.
+type normalizedText struct {
+	text  string
+	start []int
+	end   []int
+}
+
+// normalize returns the normalized form of raw. ok is false if normalizing
+// doesn't change raw.
+func normalize(raw string) (normalizedText, bool) {
+	var (
+		n       normalizedText
+		sb      strings.Builder
+		changed bool
+	)
+	for i := 0; i < len(raw); {
+		// the width in raw, invalid bytes decode to a RuneError of one byte
+		// that is copied through as it is
+		r, size := utf8.DecodeRuneInString(raw[i:])
+		if r < utf8.RuneSelf || (r == utf8.RuneError && size == 1) {
+			sb.WriteString(raw[i : i+size])
+			n.start = append(n.start, i)
+			n.end = append(n.end, i+size)
+			i += size
+			continue
+		}
+		next := i + size
+		if zeroWidth[r] {
+			changed = true
+			i = next
+			continue
+		}
+		s := norm.NFKC.String(string(r))
+		if c, ok := confusables[r]; ok {
+			s = string(c)
+		}
+		if s != string(r) {
+			changed = true
+		}
+		sb.WriteString(s)
+		for j := 0; j < len(s); j++ {
+			n.start = append(n.start, i)
+			n.end = append(n.end, next)
+		}
+		i = next
+	}
+	n.text = sb.String()
+	return n, changed
+}
+
+// span returns the bounds in the original text of text[start:end].
+func (n normalizedText) span(start int, end int) (int, int) {
+	return n.start[start], n.end[end-1]
+}
+
+// detectNormalized runs the rules on the normalized fragment and reports
+// the secrets that were hidden by invisible or look-alike characters.
+// findings are the findings already made on the raw fragment.
+func (d *Detector) detectNormalized(fragment Fragment, findings []report.Finding) []report.Finding {
+	n, changed := normalize(fragment.Raw)
+	if !changed {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(findings))
+	for _, f := range findings {
+		seen[decodedKey(f)] = true
+	}
+
+	fragment.newlineIndices = regexp.MustCompile("\n").FindAllStringIndex(fragment.Raw, -1)
+	lineStarts := lineOffsets(n.text)
+
+	var normalizedFindings []report.Finding
+	for _, f := range d.detectRules(Fragment{
+		Raw:         n.text,
+		FilePath:    fragment.FilePath,
+		SymlinkFile: fragment.SymlinkFile,
+		CommitSHA:   fragment.CommitSHA,
+	}) {
+		// newlines are never removed so the line is the same, find the
+		// match within it and map it back to the original text
+		if f.StartLine >= len(lineStarts) || f.Match == "" || f.Secret == "" {
+			continue
+		}
+		lineStart := lineStarts[f.StartLine]
+		matchStart := strings.Index(n.text[lineStart:], f.Match)
+		secretOffset := strings.Index(f.Match, f.Secret)
+		if matchStart < 0 || secretOffset < 0 {
+			continue
+		}
+		matchStart += lineStart
+		secretStart := matchStart + secretOffset
+
+		start, end := n.span(matchStart, matchStart+len(f.Match))
+		rawSecretStart, rawSecretEnd := n.span(secretStart, secretStart+len(f.Secret))
+		rawSecret := fragment.Raw[rawSecretStart:rawSecretEnd]
+		if rawSecret == f.Secret {
+			// the secret isn't obfuscated, the raw pass reports it
+			continue
+		}
+
+		f = d.relocateTo(fragment, f, location(fragment, []int{start, end}))
+		f.Match = fragment.Raw[start:end]
+		f.Secret = rawSecret
+		f.Encodings = []string{encodingUnicode}
+		if seen[decodedKey(f)] {
+			continue
+		}
+		normalizedFindings = append(normalizedFindings, f)
+	}
+	return normalizedFindings
+}
+
+// lineOffsets returns the offset of the start of every line in s.
+func lineOffsets(s string) []int {
+	offsets := []int{0}
+	for i, c := range s {
+		if c == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return offsets
+}
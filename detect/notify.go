@@ -0,0 +1,192 @@
+package detect
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+// pagerDutyEventsURL is the PagerDuty Events API v2 endpoint, a variable so
+// tests can replace it.
+var pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// Notify sends the findings matching each notification to its Slack webhook,
+// email recipients and PagerDuty integration. Secrets are redacted before
+// anything is sent. Failing notifications are logged and do not stop the
+// remaining ones. The number of failed notifications is returned as an error.
+func Notify(notifications []config.Notification, findings []report.Finding) error {
+	failed := 0
+	for _, n := range notifications {
+		var matched []report.Finding
+		for _, f := range findings {
+			if !n.Matches(f.RuleID, f.Tags) {
+				continue
+			}
+			if n.Severity != "" && report.LevelRank(f.Severity) < report.LevelRank(n.Severity) {
+				continue
+			}
+			// the context lines are shared with the caller's finding
+			f.ContextBefore = append([]string(nil), f.ContextBefore...)
+			f.ContextAfter = append([]string(nil), f.ContextAfter...)
+			f.Redact(100)
+			if f.Link == "" {
+				f.Link = report.Permalink(f.RemoteURL, f.Commit, f.File, f.StartLine, f.EndLine)
+			}
+			matched = append(matched, f)
+		}
+		if len(matched) == 0 {
+			continue
+		}
+		for _, err := range sendNotification(n, matched) {
+			log.Error().Err(err).Msgf("notification %q failed", n.Description)
+			failed++
+		}
+		log.Debug().Msgf("notification %q sent for %d findings", n.Description, len(matched))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d notifications failed", failed)
+	}
+	return nil
+}
+
+func sendNotification(n config.Notification, findings []report.Finding) []error {
+	var errs []error
+	if n.Slack != "" {
+		payload := map[string]string{"text": notificationText(n, findings)}
+		if err := postJSON(n.Slack, payload); err != nil {
+			errs = append(errs, fmt.Errorf("slack: %w", err))
+		}
+	}
+	if len(n.Email) > 0 {
+		if err := sendEmail(n, findings); err != nil {
+			errs = append(errs, fmt.Errorf("email: %w", err))
+		}
+	}
+	if n.PagerDuty != "" {
+		for _, f := range findings {
+			if err := postJSON(pagerDutyEventsURL, pagerDutyEvent(n.PagerDuty, f)); err != nil {
+				errs = append(errs, fmt.Errorf("pagerduty: %w", err))
+			}
+		}
+	}
+	return errs
+}
+
+// notificationText summarizes findings, one per line, for Slack and email.
+func notificationText(n config.Notification, findings []report.Finding) string {
+	var sb strings.Builder
+	subject := n.Description
+	if subject == "" {
+		subject = "gitleaks"
+	}
+	fmt.Fprintf(&sb, "%s: %d new leaks found\n", subject, len(findings))
+	for _, f := range findings {
+		location := fmt.Sprintf("%s:%d", f.File, f.StartLine)
+		if f.Repository != "" {
+			location = f.Repository + "/" + location
+		}
+		fmt.Fprintf(&sb, "- %s in %s", f.RuleID, location)
+		if f.Severity != "" {
+			fmt.Fprintf(&sb, " (%s)", f.Severity)
+		}
+		if f.Commit != "" {
+			fmt.Fprintf(&sb, " commit %s", f.Commit)
+		}
+		if f.Link != "" {
+			fmt.Fprintf(&sb, " %s", f.Link)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func sendEmail(n config.Notification, findings []report.Finding) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.Email, ", "))
+	fmt.Fprintf(&msg, "Subject: [gitleaks] %d new leaks found\r\n", len(findings))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(notificationText(n, findings), "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if username := os.Getenv("GITLEAKS_SMTP_USERNAME"); username != "" {
+		host := n.SMTP
+		if i := strings.LastIndex(host, ":"); i != -1 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", username, os.Getenv("GITLEAKS_SMTP_PASSWORD"), host)
+	}
+	return smtp.SendMail(n.SMTP, auth, n.From, n.Email, msg.Bytes())
+}
+
+// pagerDutyEvent triggers an alert for a finding. The fingerprint is the
+// dedup key so a finding reported twice updates the same alert.
+func pagerDutyEvent(routingKey string, f report.Finding) map[string]interface{} {
+	severity := "warning"
+	switch f.Severity {
+	case report.LevelCritical:
+		severity = "critical"
+	case report.LevelHigh:
+		severity = "error"
+	case report.LevelLow:
+		severity = "info"
+	}
+	source := f.Repository
+	if source == "" {
+		source = f.File
+	}
+	event := map[string]interface{}{
+		"routing_key":  routingKey,
+		"event_action": "trigger",
+		"dedup_key":    f.Fingerprint,
+		"payload": map[string]interface{}{
+			"summary":  fmt.Sprintf("gitleaks: %s leaked in %s:%d", f.RuleID, f.File, f.StartLine),
+			"source":   source,
+			"severity": severity,
+			"custom_details": map[string]interface{}{
+				"rule":        f.RuleID,
+				"file":        f.File,
+				"line":        f.StartLine,
+				"commit":      f.Commit,
+				"fingerprint": f.Fingerprint,
+				"match":       f.Match,
+			},
+		},
+	}
+	if f.Link != "" {
+		event["links"] = []map[string]string{{"href": f.Link, "text": "leaked secret"}}
+	}
+	return event
+}
+
+// postJSON posts v to endpoint. Webhook URLs are credentials, so they are
+// kept out of the errors.
+func postJSON(endpoint string, v interface{}) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	client := http.Client{Timeout: actionTimeout}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("request returned %s", resp.Status)
+	}
+	return nil
+}
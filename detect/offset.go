@@ -0,0 +1,135 @@
+package detect
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+	"github.com/zricethezav/gitleaks/v8/sources"
+)
+
+// locateSecret sets the byte offsets of the secret of f, a finding of detect,
+// in the fragment it was found in. It runs before the secret is redacted.
+// Secrets that don't appear on their lines as is, ex: decoded ones, get no
+// offsets.
+func locateSecret(f report.Finding, fragment string) report.Finding {
+	start, ok := secretOffset(fragment, f.Secret, f.StartLine, f.EndLine)
+	if !ok {
+		f.StartOffset, f.EndOffset = 0, 0
+		return f
+	}
+	f.StartOffset = start
+	f.EndOffset = start + len(f.Secret)
+	return f
+}
+
+// withOffsets moves the offsets of f from its fragment to the file the
+// fragment starts at base in.
+func withOffsets(f report.Finding, base int) report.Finding {
+	if f.EndOffset == 0 {
+		return f
+	}
+	f.StartOffset += base
+	f.EndOffset += base
+	return f
+}
+
+// withLineOffsets moves the offsets of f from its fragment, made of some of
+// the lines of content like the added lines of a diff, to content. line is
+// the line of f in the fragment and contentLine in content, both counting
+// from 0. f gets no offsets if the lines differ.
+func withLineOffsets(f report.Finding, fragment string, line int, content string, contentLine int) report.Finding {
+	if f.EndOffset == 0 {
+		return f
+	}
+	fragmentStart, fragmentEnd, ok := lineBounds(fragment, line)
+	contentStart, contentEnd, contentOK := lineBounds(content, contentLine)
+	if !ok || !contentOK || f.StartOffset < fragmentStart || f.StartOffset > fragmentEnd ||
+		fragment[fragmentStart:fragmentEnd] != content[contentStart:contentEnd] {
+		f.StartOffset, f.EndOffset = 0, 0
+		return f
+	}
+	length := f.EndOffset - f.StartOffset
+	f.StartOffset = contentStart + f.StartOffset - fragmentStart
+	f.EndOffset = f.StartOffset + length
+	if f.EndOffset > len(content) {
+		f.StartOffset, f.EndOffset = 0, 0
+	}
+	return f
+}
+
+// lineBounds returns the offsets of the start and end of line in text,
+// counting from 0, without its newline.
+func lineBounds(text string, line int) (int, int, bool) {
+	start := 0
+	for i := 0; i < line; i++ {
+		next := strings.IndexByte(text[start:], '\n')
+		if next == -1 {
+			return 0, 0, false
+		}
+		start += next + 1
+	}
+	end := strings.IndexByte(text[start:], '\n')
+	if end == -1 {
+		return start, len(text), true
+	}
+	return start, start + end, true
+}
+
+// secretOffset returns the offset of the first occurrence of secret on the
+// lines from startLine to endLine of text, counting from 0.
+func secretOffset(text string, secret string, startLine int, endLine int) (int, bool) {
+	if secret == "" || startLine < 0 || endLine < startLine {
+		return 0, false
+	}
+	lineStart := 0
+	for i := 0; i < startLine; i++ {
+		next := strings.IndexByte(text[lineStart:], '\n')
+		if next == -1 {
+			return 0, false
+		}
+		lineStart += next + 1
+	}
+	lineEnd := lineStart
+	for i := startLine; i <= endLine && lineEnd < len(text); i++ {
+		next := strings.IndexByte(text[lineEnd:], '\n')
+		if next == -1 {
+			lineEnd = len(text)
+			break
+		}
+		lineEnd += next + 1
+	}
+	i := strings.Index(text[lineStart:lineEnd], secret)
+	if i == -1 {
+		return 0, false
+	}
+	return lineStart + i, true
+}
+
+// blobContent returns a function reading the content of path at commit in the
+// repository at dir the first time it's called, for locating the secrets
+// found in the changes to it. It reports false when the content can't be
+// read, ex: for diffs not read from a repository.
+func blobContent(dir string, commit string, path string) func() (string, bool) {
+	var (
+		once    sync.Once
+		content string
+		ok      bool
+	)
+	return func() (string, bool) {
+		once.Do(func() {
+			if dir == "" || commit == "" {
+				return
+			}
+			var err error
+			if content, err = sources.FileAtRef(dir, commit, path); err != nil {
+				log.Debug().Err(err).Msgf("could not read %s in commit %s to locate its secrets", path, commit)
+				return
+			}
+			ok = true
+		})
+		return content, ok
+	}
+}
@@ -0,0 +1,331 @@
+package detect
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+// Plugin is an external detector running as a subprocess. Gitleaks writes one
+// JSON request per line to the plugin's stdin for every fragment and reads
+// one JSON response per line from its stdout:
+//
+//	request:  {"id": 1, "raw": "...", "filePath": "config.yml", "commitSha": "..."}
+//	response: {"id": 1, "findings": [{"RuleID": "luhn", "Secret": "...", "StartLine": 1, ...}]}
+//
+// Findings use the same fields as the JSON report. Lines and columns are
+// 1-based and relative to the fragment. The plugin should exit when its
+// stdin is closed. A plugin that answers out of turn, with something other
+// than a response or not within Timeout is killed and started again.
+type Plugin struct {
+	// Name is used as the rule id of findings without one.
+	Name string
+
+	// Timeout limits how long the plugin may take to answer a request, no
+	// limit when 0.
+	Timeout time.Duration
+
+	command []string
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stdout  *bufio.Reader
+
+	mu     sync.Mutex
+	nextID int
+}
+
+type pluginRequest struct {
+	ID        int    `json:"id"`
+	Raw       string `json:"raw"`
+	FilePath  string `json:"filePath,omitempty"`
+	CommitSHA string `json:"commitSha,omitempty"`
+}
+
+// DefaultPluginTimeout is the Timeout of started plugins.
+const DefaultPluginTimeout = 30 * time.Second
+
+type pluginResponse struct {
+	ID       int              `json:"id"`
+	Findings []report.Finding `json:"findings"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// StartPlugin starts the plugin command.
+func StartPlugin(command []string) (*Plugin, error) {
+	if len(command) == 0 {
+		return nil, errors.New("plugin command is empty")
+	}
+	p := &Plugin{
+		Name:    filepath.Base(command[0]),
+		Timeout: DefaultPluginTimeout,
+		command: command,
+	}
+	if err := p.start(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// start runs the plugin's command.
+func (p *Plugin) start() error {
+	cmd := exec.Command(p.command[0], p.command[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	log.Debug().Msgf("started plugin: %s", cmd.String())
+	p.cmd = cmd
+	p.stdin = stdin
+	p.stdout = bufio.NewReader(stdout)
+	return nil
+}
+
+// restart kills the plugin and starts it again. It's called after a protocol
+// error since the responses still to come can no longer be matched to their
+// requests.
+func (p *Plugin) restart(cause error) error {
+	log.Warn().Err(cause).Msgf("restarting plugin %s", p.Name)
+	if p.cmd != nil {
+		p.stdin.Close()
+		_ = p.cmd.Process.Kill()
+		_ = p.cmd.Wait()
+		p.cmd = nil
+	}
+	if err := p.start(); err != nil {
+		return fmt.Errorf("plugin %s could not be restarted: %w", p.Name, err)
+	}
+	return cause
+}
+
+// Detect sends the fragment to the plugin and returns the findings it reports,
+// with line numbers converted to those used by the rest of the detector.
+func (p *Plugin) Detect(fragment Fragment) ([]report.Finding, error) {
+	// requests are serialized since the plugin answers one line at a time
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cmd == nil {
+		if err := p.start(); err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", p.Name, err)
+		}
+	}
+
+	p.nextID++
+	req, err := json.Marshal(pluginRequest{
+		ID:        p.nextID,
+		Raw:       fragment.Raw,
+		FilePath:  fragment.FilePath,
+		CommitSHA: fragment.CommitSHA,
+	})
+	if err != nil {
+		return nil, err
+	}
+	line, err := p.roundTrip(append(req, '\n'))
+	if err != nil {
+		return nil, p.restart(fmt.Errorf("plugin %s: %w", p.Name, err))
+	}
+	var resp pluginResponse
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return nil, p.restart(fmt.Errorf("plugin %s returned invalid response: %w", p.Name, err))
+	}
+	if resp.ID != p.nextID {
+		return nil, p.restart(fmt.Errorf("plugin %s returned response %d for request %d", p.Name, resp.ID, p.nextID))
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("plugin %s: %s", p.Name, resp.Error)
+	}
+
+	findings := resp.Findings[:0]
+	for _, f := range resp.Findings {
+		if f.Secret == "" {
+			continue
+		}
+		if f.RuleID == "" {
+			f.RuleID = p.Name
+		}
+		if f.Match == "" {
+			f.Match = f.Secret
+		}
+		f.File = fragment.FilePath
+		f.SymlinkFile = fragment.SymlinkFile
+		// the detector counts lines from 0 within a fragment
+		if f.StartLine > 0 {
+			f.StartLine--
+		}
+		if f.EndLine > 0 {
+			f.EndLine--
+		}
+		if f.EndLine < f.StartLine {
+			f.EndLine = f.StartLine
+		}
+		f.Line = fragmentLines(fragment.Raw, f.StartLine, f.EndLine)
+		if f.Line == "" {
+			f.Line = f.Match
+		}
+		f.Entropy = float32(shannonEntropy(f.Secret))
+		findings = append(findings, f)
+	}
+	return findings, nil
+}
+
+// roundTrip writes a request to the plugin and reads its response. The
+// caller must hold mu and restart the plugin if an error is returned, which
+// also ends a write or read still blocked after a timeout.
+func (p *Plugin) roundTrip(req []byte) ([]byte, error) {
+	type result struct {
+		line []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	stdin, stdout := p.stdin, p.stdout
+	go func() {
+		if _, err := stdin.Write(req); err != nil {
+			done <- result{err: err}
+			return
+		}
+		line, err := stdout.ReadBytes('\n')
+		done <- result{line, err}
+	}()
+
+	var timeout <-chan time.Time
+	if p.Timeout > 0 {
+		timer := time.NewTimer(p.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case r := <-done:
+		return r.line, r.err
+	case <-timeout:
+		return nil, fmt.Errorf("no response within %s", p.Timeout)
+	}
+}
+
+// fragmentLines returns lines start to end of raw, counted from 0.
+func fragmentLines(raw string, start, end int) string {
+	lines := strings.Split(raw, "\n")
+	if start < 0 || start >= len(lines) {
+		return ""
+	}
+	if end >= len(lines) {
+		end = len(lines) - 1
+	}
+	return strings.Join(lines[start:end+1], "\n")
+}
+
+// Close closes the plugin's stdin and waits for it to exit.
+func (p *Plugin) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cmd == nil {
+		return nil
+	}
+	cmd := p.cmd
+	p.cmd = nil
+	if err := p.stdin.Close(); err != nil {
+		return err
+	}
+	return cmd.Wait()
+}
+
+// AddPlugin adds an external detector that is run on every fragment.
+func (d *Detector) AddPlugin(p *Plugin) {
+	d.plugins = append(d.plugins, p)
+}
+
+// Close stops the detector's plugins. The detector must not be used
+// afterwards.
+func (d *Detector) Close() error {
+	var firstErr error
+	for _, p := range d.plugins {
+		if err := p.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("plugin %s: %w", p.Name, err)
+		}
+	}
+	return firstErr
+}
+
+// detectPlugins runs the plugins on the fragment. Their findings are
+// suppressed by gitleaks:allow, the global allowlist and, when a plugin
+// reports findings under the id of a configured rule, that rule's allowlist.
+func (d *Detector) detectPlugins(fragment Fragment) []report.Finding {
+	var findings []report.Finding
+	for _, p := range d.plugins {
+		pluginFindings, err := p.Detect(fragment)
+		if err != nil {
+			log.Error().Err(err).Msg("")
+			continue
+		}
+		for _, f := range pluginFindings {
+			if strings.Contains(f.Line, gitleaksAllowSignature) && !d.IgnoreGitleaksAllow {
+				d.suppress(fragment, f, SuppressAllowComment, "")
+				continue
+			}
+			if rule, ok := d.Config.Rules[f.RuleID]; ok {
+				if allowed, reason := pluginFindingAllowed(rule.Allowlist, fragment, f); allowed {
+					if reason != "" {
+						d.suppress(fragment, f, reason, "rule")
+					}
+					continue
+				}
+			}
+			if allowed, reason := pluginFindingAllowed(d.Config.Allowlist, fragment, f); allowed {
+				if reason != "" {
+					d.suppress(fragment, f, reason, "global")
+				}
+				continue
+			}
+			if d.VaultAllowlist != nil && d.VaultAllowlist.Allowed(f) {
+				d.suppress(fragment, f, SuppressVault, "")
+				continue
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// pluginFindingAllowed returns true and the reason to record if allowlist
+// allows a plugin finding. Like for rules, findings in allowed commits and
+// paths are dropped without recording a reason.
+func pluginFindingAllowed(allowlist config.Allowlist, fragment Fragment, f report.Finding) (bool, string) {
+	if allowlist.CommitAllowed(fragment.CommitSHA) || allowlist.PathAllowed(fragment.FilePath) {
+		return true, ""
+	}
+	target := f.Secret
+	switch allowlist.RegexTarget {
+	case "match":
+		target = f.Match
+	case "line":
+		target = f.Line
+	}
+	switch {
+	case allowlist.RegexAllowed(target):
+		return true, SuppressAllowlistRegex
+	case allowlist.ContainsStopWord(f.Secret):
+		return true, SuppressStopWord
+	case allowlist.SecretHashAllowed(f.Secret):
+		return true, SuppressSecretHash
+	}
+	return false, ""
+}
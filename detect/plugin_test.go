@@ -0,0 +1,175 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+)
+
+const echoPlugin = `#!/bin/sh
+while IFS= read -r line; do
+	id=$(printf '%s\n' "$line" | sed -E 's/^\{"id":([0-9]+).*/\1/')
+	echo "{\"id\":$id,\"findings\":[{\"Description\":\"credit card\",\"Secret\":\"4111111111111111\",\"StartLine\":2,\"StartColumn\":6,\"EndColumn\":21}]}"
+done
+`
+
+func TestPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin test requires sh")
+	}
+	path := filepath.Join(t.TempDir(), "gitleaks-luhn")
+	require.NoError(t, os.WriteFile(path, []byte(echoPlugin), 0o755))
+
+	plugin, err := StartPlugin([]string{path})
+	require.NoError(t, err)
+	defer plugin.Close()
+
+	detector := NewDetector(config.Config{})
+	detector.AddPlugin(plugin)
+
+	for i := 0; i < 2; i++ {
+		findings := detector.Detect(Fragment{Raw: "name: test\ncard 4111111111111111\n", FilePath: "cards.yml"})
+		require.Len(t, findings, 1)
+		assert.Equal(t, "gitleaks-luhn", findings[0].RuleID)
+		assert.Equal(t, "cards.yml", findings[0].File)
+		assert.Equal(t, "4111111111111111", findings[0].Match)
+		assert.Equal(t, 1, findings[0].StartLine)
+		assert.Equal(t, 1, findings[0].EndLine)
+	}
+}
+
+func TestPluginAllowlists(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin test requires sh")
+	}
+	path := filepath.Join(t.TempDir(), "gitleaks-luhn")
+	require.NoError(t, os.WriteFile(path, []byte(echoPlugin), 0o755))
+
+	tests := map[string]struct {
+		cfg      config.Config
+		raw      string
+		filePath string
+	}{
+		"gitleaks:allow": {
+			raw: "name: test\ncard 4111111111111111 # gitleaks:allow\n",
+		},
+		"rule allowlist regex": {
+			cfg: config.Config{Rules: map[string]config.Rule{"gitleaks-luhn": {
+				RuleID:    "gitleaks-luhn",
+				Allowlist: config.Allowlist{RegexTarget: "line", Regexes: []*regexp.Regexp{regexp.MustCompile(`^card `)}},
+			}}},
+			raw: "name: test\ncard 4111111111111111\n",
+		},
+		"rule allowlist path": {
+			cfg: config.Config{Rules: map[string]config.Rule{"gitleaks-luhn": {
+				RuleID:    "gitleaks-luhn",
+				Allowlist: config.Allowlist{Paths: []*regexp.Regexp{regexp.MustCompile(`fixtures/`)}},
+			}}},
+			raw:      "name: test\ncard 4111111111111111\n",
+			filePath: "fixtures/cards.yml",
+		},
+		"global stopword": {
+			cfg: config.Config{Allowlist: config.Allowlist{StopWords: []string{"41111111"}}},
+			raw: "name: test\ncard 4111111111111111\n",
+		},
+	}
+	for name, tt := range tests {
+		plugin, err := StartPlugin([]string{path})
+		require.NoError(t, err)
+
+		detector := NewDetector(tt.cfg)
+		detector.AddPlugin(plugin)
+		var suppressed []Suppression
+		findings := detector.Detect(Fragment{Raw: tt.raw, FilePath: tt.filePath, suppressed: &suppressed})
+		assert.Empty(t, findings, name)
+		assert.NoError(t, detector.Close(), name)
+	}
+}
+
+// flakyPlugin answers its first request out of turn, then works once the
+// file it is given exists.
+const flakyPlugin = `#!/bin/sh
+while IFS= read -r line; do
+	id=$(printf '%s\n' "$line" | sed -E 's/^\{"id":([0-9]+).*/\1/')
+	if [ ! -e "$1" ]; then
+		touch "$1"
+		echo "{\"id\":0,\"findings\":[]}"
+		echo "{\"id\":$id,\"findings\":[]}"
+		continue
+	fi
+	echo "{\"id\":$id,\"findings\":[{\"Secret\":\"4111111111111111\",\"StartLine\":1}]}"
+done
+`
+
+func TestPluginRestartsAfterProtocolError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin test requires sh")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gitleaks-flaky")
+	require.NoError(t, os.WriteFile(path, []byte(flakyPlugin), 0o755))
+
+	plugin, err := StartPlugin([]string{path, filepath.Join(dir, "started")})
+	require.NoError(t, err)
+	defer plugin.Close()
+
+	fragment := Fragment{Raw: "card 4111111111111111\n"}
+	_, err = plugin.Detect(fragment)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "returned response 0 for request 1")
+
+	// the response to the first request would be read as the answer to the
+	// second one if the plugin wasn't restarted
+	findings, err := plugin.Detect(fragment)
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "4111111111111111", findings[0].Secret)
+	assert.Equal(t, "card 4111111111111111", findings[0].Line)
+}
+
+// hangingPlugin only answers after the file given as its argument exists.
+const hangingPlugin = `#!/bin/sh
+while IFS= read -r line; do
+	id=$(printf '%s\n' "$line" | sed -E 's/^\{"id":([0-9]+).*/\1/')
+	if [ ! -e "$1" ]; then
+		touch "$1"
+		# the orphaned sleep must not keep the pipes open
+		sleep 10 </dev/null >/dev/null 2>&1
+	fi
+	echo "{\"id\":$id,\"findings\":[{\"Secret\":\"4111111111111111\",\"StartLine\":1}]}"
+done
+`
+
+func TestPluginTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin test requires sh")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gitleaks-hanging")
+	require.NoError(t, os.WriteFile(path, []byte(hangingPlugin), 0o755))
+
+	plugin, err := StartPlugin([]string{path, filepath.Join(dir, "hung")})
+	require.NoError(t, err)
+	defer plugin.Close()
+	plugin.Timeout = 200 * time.Millisecond
+
+	fragment := Fragment{Raw: "card 4111111111111111\n"}
+	start := time.Now()
+	_, err = plugin.Detect(fragment)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no response within 200ms")
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// the hanging plugin was replaced
+	findings, err := plugin.Detect(fragment)
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+}
@@ -0,0 +1,74 @@
+package detect
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+// PolicyViolation is a policy whose findings exceeded its limit.
+type PolicyViolation struct {
+	Policy config.Policy
+
+	// RuleID is set for per-rule policies.
+	RuleID string
+
+	Count int
+}
+
+func (v PolicyViolation) String() string {
+	name := v.Policy.Description
+	if name == "" {
+		name = "policy"
+	}
+	if v.RuleID != "" {
+		name = fmt.Sprintf("%s (%s)", name, v.RuleID)
+	}
+	return fmt.Sprintf("%s: %d findings, %d allowed", name, v.Count, v.Policy.MaxFindings)
+}
+
+// EvaluatePolicies returns the policies violated by the findings, in the
+// order they are configured.
+func EvaluatePolicies(policies []config.Policy, findings []report.Finding) []PolicyViolation {
+	var violations []PolicyViolation
+	for _, p := range policies {
+		counts := make(map[string]int)
+		for _, f := range findings {
+			if !p.Matches(f.RuleID, f.Tags) {
+				continue
+			}
+			if p.Severity != "" && report.LevelRank(f.Severity) < report.LevelRank(p.Severity) {
+				continue
+			}
+			key := ""
+			if p.PerRule {
+				key = f.RuleID
+			}
+			counts[key]++
+		}
+
+		var keys []string
+		for k := range counts {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if counts[k] > p.MaxFindings {
+				violations = append(violations, PolicyViolation{Policy: p, RuleID: k, Count: counts[k]})
+			}
+		}
+	}
+	return violations
+}
+
+// PolicyFailed returns true if any violation has the fail action.
+func PolicyFailed(violations []PolicyViolation) bool {
+	for _, v := range violations {
+		if v.Policy.Action == config.PolicyFail {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package detect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+func TestEvaluatePolicies(t *testing.T) {
+	findings := []report.Finding{
+		{RuleID: "aws-access-token", Severity: report.LevelCritical},
+		{RuleID: "generic-api-key", Severity: report.LevelMedium},
+		{RuleID: "generic-api-key", Severity: report.LevelMedium},
+		{RuleID: "generic-api-key", Severity: report.LevelLow},
+	}
+
+	tests := []struct {
+		name       string
+		policies   []config.Policy
+		violations int
+		failed     bool
+	}{
+		{
+			name:       "fail on high severity",
+			policies:   []config.Policy{{Severity: report.LevelHigh, Action: config.PolicyFail}},
+			violations: 1,
+			failed:     true,
+		},
+		{
+			name:     "high severity within limit",
+			policies: []config.Policy{{Severity: report.LevelHigh, MaxFindings: 1, Action: config.PolicyFail}},
+		},
+		{
+			name:       "warn per rule",
+			policies:   []config.Policy{{PerRule: true, MaxFindings: 2, Action: config.PolicyWarn}},
+			violations: 1,
+		},
+		{
+			name:       "rule selector",
+			policies:   []config.Policy{{RuleIDs: []string{"generic-api-key"}, Severity: report.LevelMedium, MaxFindings: 1, Action: config.PolicyFail}},
+			violations: 1,
+			failed:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := EvaluatePolicies(tt.policies, findings)
+			assert.Len(t, violations, tt.violations)
+			assert.Equal(t, tt.failed, PolicyFailed(violations))
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"io"
 
+	"github.com/rs/zerolog/log"
 	"github.com/zricethezav/gitleaks/v8/report"
 )
 
@@ -13,6 +14,8 @@ func (d *Detector) DetectReader(r io.Reader, bufSize int) ([]report.Finding, err
 	buf := make([]byte, 0, 1000*bufSize)
 	findings := []report.Finding{}
 
+	// offset of the buffer in the input
+	offset := 0
 	for {
 		n, err := reader.Read(buf[:cap(buf)])
 		buf = buf[:n]
@@ -26,11 +29,34 @@ func (d *Detector) DetectReader(r io.Reader, bufSize int) ([]report.Finding, err
 		fragment := Fragment{
 			Raw: string(buf),
 		}
-		for _, finding := range d.Detect(fragment) {
+		var suppressed []Suppression
+		if d.RecordSuppressions {
+			fragment.suppressed = &suppressed
+		}
+		for _, finding := range d.detect(fragment) {
+			finding, ok := d.prepareFinding(withOffsets(finding, offset))
+			if !ok {
+				d.discardVerification(finding)
+				continue
+			}
+			if finding = d.verify(finding); !d.confident(finding) {
+				continue
+			}
 			findings = append(findings, finding)
 			if d.Verbose {
 				printFinding(finding, d.NoColor)
 			}
+			if len(findings) == d.MaxFindings {
+				break
+			}
+		}
+		for _, s := range suppressed {
+			d.addSuppression(s)
+		}
+		offset += n
+		if d.MaxFindings > 0 && len(findings) >= d.MaxFindings {
+			log.Warn().Msgf("stopping the scan after %d findings, the results are incomplete", d.MaxFindings)
+			break
 		}
 	}
 
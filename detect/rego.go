@@ -0,0 +1,99 @@
+package detect
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+// regoQuery evaluates data.gitleaks.decision once per finding, keyed by the
+// index of the finding. Findings without a decision are left unchanged.
+const regoQuery = `{i: d | f := input.findings[i]; d := data.gitleaks.decision with input as f}`
+
+// opaCommand is the opa CLI run by ApplyRegoPolicy, replaced in tests.
+var opaCommand = "opa"
+
+// RegoDecision is the value of data.gitleaks.decision for a finding.
+type RegoDecision struct {
+	// Drop removes the finding.
+	Drop bool `json:"drop"`
+
+	// Severity overrides the severity of the finding.
+	Severity string `json:"severity"`
+}
+
+// ApplyRegoPolicy evaluates the Rego policy at policyPath against each
+// finding using the opa CLI, which must be installed. The policy must be in
+// package gitleaks and define decision, with the finding as input, e.g.
+//
+//	package gitleaks
+//
+//	import rego.v1
+//
+//	decision := {"drop": true} if startswith(input.File, "testdata/")
+//	decision := {"severity": "critical"} if input.RuleID == "aws-access-token"
+func ApplyRegoPolicy(policyPath string, findings []report.Finding) ([]report.Finding, error) {
+	if len(findings) == 0 {
+		return findings, nil
+	}
+	input, err := json.Marshal(map[string][]report.Finding{"findings": findings})
+	if err != nil {
+		return findings, err
+	}
+
+	cmd := exec.Command(opaCommand, "eval", "--format", "json", "--stdin-input", "--data", policyPath, regoQuery)
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return findings, fmt.Errorf("opa eval failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var result struct {
+		Result []struct {
+			Expressions []struct {
+				Value map[string]RegoDecision `json:"value"`
+			} `json:"expressions"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return findings, fmt.Errorf("could not parse opa output: %w", err)
+	}
+	if len(result.Result) == 0 || len(result.Result[0].Expressions) == 0 {
+		return findings, nil
+	}
+	return applyRegoDecisions(findings, result.Result[0].Expressions[0].Value)
+}
+
+// applyRegoDecisions drops findings or overrides their severity according
+// to the decisions, which are keyed by the index of the finding.
+func applyRegoDecisions(findings []report.Finding, decisions map[string]RegoDecision) ([]report.Finding, error) {
+	var kept []report.Finding
+	for i, f := range findings {
+		decision, ok := decisions[strconv.Itoa(i)]
+		if !ok {
+			kept = append(kept, f)
+			continue
+		}
+		if decision.Drop {
+			log.Debug().Msgf("rego policy dropped finding %s", f.Fingerprint)
+			continue
+		}
+		if decision.Severity != "" {
+			if !report.ValidLevel(decision.Severity) {
+				return findings, fmt.Errorf("rego policy returned invalid severity %q for %s", decision.Severity, f.Fingerprint)
+			}
+			f.Severity = strings.ToLower(decision.Severity)
+		}
+		kept = append(kept, f)
+	}
+	return kept, nil
+}
@@ -0,0 +1,99 @@
+package detect
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+func TestApplyRegoDecisions(t *testing.T) {
+	findings := []report.Finding{
+		{RuleID: "a", Severity: report.LevelLow},
+		{RuleID: "b", Severity: report.LevelLow},
+		{RuleID: "c", Severity: report.LevelLow},
+	}
+	kept, err := applyRegoDecisions(findings, map[string]RegoDecision{
+		"0": {Drop: true},
+		"2": {Severity: "Critical"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []report.Finding{
+		{RuleID: "b", Severity: report.LevelLow},
+		{RuleID: "c", Severity: report.LevelCritical},
+	}, kept)
+
+	_, err = applyRegoDecisions(findings, map[string]RegoDecision{"1": {Severity: "urgent"}})
+	assert.Error(t, err)
+}
+
+func TestApplyRegoPolicyCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake opa is a shell script")
+	}
+	// a fake opa records how it was invoked and drops the first finding
+	dir := t.TempDir()
+	fake := filepath.Join(dir, "opa")
+	require.NoError(t, os.WriteFile(fake, []byte(`#!/bin/sh
+printf '%s\n' "$@" > "$(dirname "$0")/args"
+cat > "$(dirname "$0")/stdin"
+echo '{"result": [{"expressions": [{"value": {"0": {"drop": true}, "1": {"severity": "high"}}}]}]}'
+`), 0o755))
+	defer func(command string) { opaCommand = command }(opaCommand)
+	opaCommand = fake
+
+	kept, err := ApplyRegoPolicy("policy.rego", []report.Finding{
+		{RuleID: "a", File: "testdata/key.pem"},
+		{RuleID: "b", File: "main.go"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []report.Finding{{RuleID: "b", File: "main.go", Severity: report.LevelHigh}}, kept)
+
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	require.NoError(t, err)
+	assert.Equal(t, "eval\n--format\njson\n--stdin-input\n--data\npolicy.rego\n"+regoQuery+"\n", string(args))
+	stdin, err := os.ReadFile(filepath.Join(dir, "stdin"))
+	require.NoError(t, err)
+	assert.Contains(t, string(stdin), `"findings":[`)
+	assert.Contains(t, string(stdin), `"File":"testdata/key.pem"`)
+
+	opaCommand = filepath.Join(dir, "missing")
+	_, err = ApplyRegoPolicy("policy.rego", []report.Finding{{RuleID: "a"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "opa eval failed")
+}
+
+func TestApplyRegoPolicy(t *testing.T) {
+	if _, err := exec.LookPath("opa"); err != nil {
+		// CI installs opa, so the policy syntax is always checked there
+		if os.Getenv("CI") != "" {
+			t.Fatal("opa is not installed")
+		}
+		t.Skip("opa is not installed")
+	}
+	policy := filepath.Join(t.TempDir(), "policy.rego")
+	require.NoError(t, os.WriteFile(policy, []byte(`package gitleaks
+
+import rego.v1
+
+decision := {"drop": true} if startswith(input.File, "testdata/")
+decision := {"severity": "critical"} if input.RuleID == "aws-access-token"
+`), 0o644))
+
+	kept, err := ApplyRegoPolicy(policy, []report.Finding{
+		{RuleID: "a", File: "testdata/key.pem"},
+		{RuleID: "b", File: "main.go"},
+		{RuleID: "aws-access-token", File: "main.go"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []report.Finding{
+		{RuleID: "b", File: "main.go"},
+		{RuleID: "aws-access-token", File: "main.go", Severity: report.LevelCritical},
+	}, kept)
+}
@@ -0,0 +1,40 @@
+package detect
+
+import (
+	"github.com/rs/zerolog/log"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+	"github.com/zricethezav/gitleaks/v8/sources"
+)
+
+// VerifySignatures adds the result of verifying the signature of the commit
+// that introduced each secret to the findings of a git scan of the repository
+// at source. That is IntroducedIn when history analysis found it and the
+// commit of the finding otherwise. A secret added in an unsigned commit, or
+// one signed with an unknown key, by someone who signs their commits points
+// at a compromised account rather than a mistake.
+func VerifySignatures(source string, findings []report.Finding) []report.Finding {
+	signatures := make(map[string]sources.CommitSignature)
+	for i, f := range findings {
+		commit := f.IntroducedIn
+		if commit == "" {
+			commit = f.Commit
+		}
+		if commit == "" {
+			continue
+		}
+		signature, ok := signatures[commit]
+		if !ok {
+			var err error
+			if signature, err = sources.VerifyCommitSignature(source, commit); err != nil {
+				log.Debug().Err(err).Msg("")
+				continue
+			}
+			signatures[commit] = signature
+		}
+		findings[i].SignatureStatus = signature.Status
+		findings[i].Signer = signature.Signer
+		findings[i].SigningKey = signature.Key
+	}
+	return findings
+}
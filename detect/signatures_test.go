@@ -0,0 +1,30 @@
+package detect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+	"github.com/zricethezav/gitleaks/v8/sources"
+	"github.com/zricethezav/gitleaks/v8/sources/gittest"
+)
+
+func TestVerifySignatures(t *testing.T) {
+	repo := gittest.New(t)
+	repo.WriteFile("config.txt", "a\n")
+	first := repo.Commit("initial")
+	repo.WriteFile("config.txt", "a\nb\n")
+	second := repo.Commit("add b")
+
+	findings := VerifySignatures(repo.Dir, []report.Finding{
+		{Commit: second, File: "config.txt"},
+		{Commit: second, IntroducedIn: first, File: "config.txt"},
+		{Commit: "0000000000000000000000000000000000000000", File: "gone.txt"},
+		{File: "staged.txt"},
+	})
+	assert.Equal(t, sources.SignatureNone, findings[0].SignatureStatus)
+	assert.Equal(t, sources.SignatureNone, findings[1].SignatureStatus)
+	assert.Empty(t, findings[2].SignatureStatus)
+	assert.Empty(t, findings[3].SignatureStatus)
+}
@@ -0,0 +1,39 @@
+package detect
+
+import (
+	"github.com/rs/zerolog/log"
+)
+
+// Reasons a file is skipped.
+const (
+	SkipSymlink         = "symlink"
+	SkipSubmodule       = "submodule"
+	SkipPathTooDeep     = "path too deep"
+	SkipCommitFileLimit = "commit file limit"
+	SkipGenerated       = "generated"
+	SkipLFSPointer      = "lfs pointer"
+	SkipLFSTooLarge     = "lfs object too large"
+	SkipLFSUnavailable  = "lfs object unavailable"
+)
+
+// Skip records a file that was not scanned and why.
+type Skip struct {
+	Commit string
+	File   string
+	Reason string
+}
+
+// addSkip records that a file was not scanned.
+func (d *Detector) addSkip(commit string, file string, reason string) {
+	log.Debug().Str("commit", commit).Str("file", file).Msgf("skipping file: %s", reason)
+	d.findingMutex.Lock()
+	d.skipped = append(d.skipped, Skip{Commit: commit, File: file, Reason: reason})
+	d.findingMutex.Unlock()
+}
+
+// Skipped returns the files that were not scanned.
+func (d *Detector) Skipped() []Skip {
+	d.findingMutex.Lock()
+	defer d.findingMutex.Unlock()
+	return append([]Skip(nil), d.skipped...)
+}
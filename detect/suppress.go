@@ -0,0 +1,62 @@
+package detect
+
+import (
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+// Reasons a match is suppressed.
+const (
+	SuppressAllowComment   = "gitleaks:allow"
+	SuppressAllowlistRegex = "allowlist regex"
+	SuppressStopWord       = "stopword"
+	SuppressSecretHash     = "allowlisted secret hash"
+	SuppressVault          = "vault reference"
+	SuppressIgnoreFile     = ".gitleaksignore"
+	SuppressBaseline       = "baseline"
+)
+
+// Suppression records a match that was not reported and why, so
+// suppressions can be audited.
+type Suppression struct {
+	report.Finding
+	Reason string
+	// Allowlist is "rule" or "global" for matches suppressed by the
+	// allowlist of the rule or the global allowlist.
+	Allowlist string `json:",omitempty"`
+}
+
+// suppress records that finding, matched in fragment, was suppressed. It is
+// a no-op unless the caller of Detect collects the suppressions of the
+// fragment.
+func (d *Detector) suppress(fragment Fragment, finding report.Finding, reason string, allowlist string) {
+	if fragment.suppressed == nil {
+		return
+	}
+	if d.HMACKey != nil {
+		finding.Pseudonymize(d.HMACKey)
+	} else if d.Redact > 0 {
+		finding.Redact(d.Redact)
+	}
+	*fragment.suppressed = append(*fragment.suppressed, Suppression{Finding: finding, Reason: reason, Allowlist: allowlist})
+}
+
+// addSuppression records a suppressed match when RecordSuppressions is set.
+func (d *Detector) addSuppression(s Suppression) {
+	if !d.RecordSuppressions {
+		return
+	}
+	if s.Fingerprint == "" {
+		s.Fingerprint = fingerprint(s.Finding)
+	}
+	d.findingMutex.Lock()
+	d.suppressions = append(d.suppressions, s)
+	d.findingMutex.Unlock()
+}
+
+// Suppressions returns the matches that were suppressed, only recorded when
+// RecordSuppressions is set.
+func (d *Detector) Suppressions() []Suppression {
+	d.findingMutex.Lock()
+	defer d.findingMutex.Unlock()
+	return append([]Suppression(nil), d.suppressions...)
+}
@@ -0,0 +1,281 @@
+package detect
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+var terraformRule = heuristicRule{
+	RuleID:      "terraform-state-secret",
+	Description: "Found a sensitive value in a Terraform state or plan file, which usually holds every credential the configuration manages.",
+	Tags:        []string{"terraform"},
+}
+
+// terraformState is the part of a version 4 state file that holds values.
+type terraformState struct {
+	Outputs map[string]struct {
+		Value     interface{} `json:"value"`
+		Sensitive bool        `json:"sensitive"`
+	} `json:"outputs"`
+	Resources []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			IndexKey            interface{}                `json:"index_key"`
+			Attributes          map[string]interface{}     `json:"attributes"`
+			SensitiveAttributes [][]map[string]interface{} `json:"sensitive_attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// terraformPlan is the part of `terraform show -json <plan>` that holds
+// values.
+type terraformPlan struct {
+	Variables map[string]struct {
+		Value interface{} `json:"value"`
+	} `json:"variables"`
+	ResourceChanges []struct {
+		Address string `json:"address"`
+		Change  struct {
+			After          interface{} `json:"after"`
+			AfterSensitive interface{} `json:"after_sensitive"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+	OutputChanges map[string]struct {
+		After          interface{} `json:"after"`
+		AfterSensitive interface{} `json:"after_sensitive"`
+	} `json:"output_changes"`
+}
+
+// terraformValue is a secret found in a state or plan file. address is the
+// resource address and attribute path, ex: aws_db_instance.main.password, and
+// key is the JSON key the secret is assigned to.
+type terraformValue struct {
+	address string
+	key     string
+	secret  string
+}
+
+// detectTerraform parses terraform state and plan files and flags values
+// that terraform marks as sensitive or that are assigned to sensitive keys.
+// Plain regexes miss most of these since the values are JSON escaped.
+func (d *Detector) detectTerraform(fragment Fragment, findings []report.Finding) []report.Finding {
+	var (
+		values []terraformValue
+		err    error
+	)
+	switch {
+	case terraformStateFile(fragment.FilePath):
+		values, err = terraformStateValues(fragment.Raw)
+	case terraformPlanFile(fragment.FilePath, fragment.Raw):
+		values, err = terraformPlanValues(fragment.Raw)
+	default:
+		return nil
+	}
+	if err != nil {
+		// git scans only see the lines added by a commit
+		log.Debug().Err(err).Msgf("skipping terraform detection for %s", fragment.FilePath)
+		return nil
+	}
+
+	// maps are walked in random order, sort by address for stable output
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].address < values[j].address
+	})
+
+	fragment.newlineIndices = regexp.MustCompile("\n").FindAllStringIndex(fragment.Raw, -1)
+	covered := coveredLines(findings)
+
+	var tfFindings []report.Finding
+	searchFrom := make(map[string]int)
+	for _, v := range values {
+		start, end := jsonStringIndex(fragment.Raw, v.key, v.secret, searchFrom[v.key+v.secret])
+		if start < 0 {
+			start, end = 0, 0
+		} else {
+			searchFrom[v.key+v.secret] = end
+		}
+		loc := location(fragment, []int{start, end})
+		tfFindings = append(tfFindings, d.heuristicFinding(fragment, loc, v.address, v.secret, terraformRule, covered)...)
+	}
+	return tfFindings
+}
+
+func terraformStateFile(path string) bool {
+	path = strings.ToLower(path)
+	return strings.HasSuffix(path, ".tfstate") || strings.HasSuffix(path, ".tfstate.backup")
+}
+
+func terraformPlanFile(path string, raw string) bool {
+	return strings.ToLower(filepath.Ext(path)) == ".json" &&
+		strings.Contains(raw, `"terraform_version"`) &&
+		strings.Contains(raw, `"resource_changes"`)
+}
+
+func terraformStateValues(raw string) ([]terraformValue, error) {
+	var state terraformState
+	if err := json.Unmarshal([]byte(raw), &state); err != nil {
+		return nil, err
+	}
+
+	var values []terraformValue
+	for name, output := range state.Outputs {
+		values = append(values, sensitiveValues("output."+name, "value", output.Value, output.Sensitive || sensitiveKey(name))...)
+	}
+	for _, r := range state.Resources {
+		address := r.Type + "." + r.Name
+		if r.Mode == "data" {
+			address = "data." + address
+		}
+		if r.Module != "" {
+			address = r.Module + "." + address
+		}
+		for _, instance := range r.Instances {
+			instanceAddress := address
+			switch key := instance.IndexKey.(type) {
+			case string:
+				instanceAddress += fmt.Sprintf("[%q]", key)
+			case float64:
+				instanceAddress += fmt.Sprintf("[%d]", int(key))
+			}
+			sensitive := sensitivePaths(instance.SensitiveAttributes)
+			for attr, value := range instance.Attributes {
+				values = append(values, sensitiveValues(instanceAddress+"."+attr, attr, value, lookupSensitive(sensitive, attr))...)
+			}
+		}
+	}
+	return values, nil
+}
+
+func terraformPlanValues(raw string) ([]terraformValue, error) {
+	var plan terraformPlan
+	if err := json.Unmarshal([]byte(raw), &plan); err != nil {
+		return nil, err
+	}
+
+	var values []terraformValue
+	for name, variable := range plan.Variables {
+		// variables are not marked as sensitive in plans
+		if v, ok := variable.Value.(string); ok && sensitiveKey(name) {
+			values = append(values, terraformValue{address: "var." + name, key: "value", secret: v})
+		}
+	}
+	for _, rc := range plan.ResourceChanges {
+		values = append(values, sensitiveValues(rc.Address, "after", rc.Change.After, rc.Change.AfterSensitive)...)
+	}
+	for name, output := range plan.OutputChanges {
+		sensitive := output.AfterSensitive
+		if sensitiveKey(name) {
+			sensitive = true
+		}
+		values = append(values, sensitiveValues("output."+name, "after", output.After, sensitive)...)
+	}
+	return values, nil
+}
+
+// sensitiveValues walks value, which is assigned to key, and returns the
+// strings that are marked as sensitive or assigned to a sensitive key.
+// sensitive mirrors the shape of value with true marking sensitive leaves, as
+// in a plan's after_sensitive.
+func sensitiveValues(address string, key string, value interface{}, sensitive interface{}) []terraformValue {
+	var values []terraformValue
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, elem := range v {
+			values = append(values, sensitiveValues(address+"."+k, k, elem, lookupSensitive(sensitive, k))...)
+		}
+	case []interface{}:
+		for i, elem := range v {
+			values = append(values, sensitiveValues(fmt.Sprintf("%s[%d]", address, i), "", elem, lookupSensitive(sensitive, fmt.Sprint(i)))...)
+		}
+	case string:
+		if sensitive == true || sensitiveKey(key) {
+			values = append(values, terraformValue{address: address, key: key, secret: v})
+		}
+	}
+	return values
+}
+
+// lookupSensitive returns the sensitivity of key in sensitive.
+func lookupSensitive(sensitive interface{}, key string) interface{} {
+	switch s := sensitive.(type) {
+	case bool:
+		return s
+	case map[string]interface{}:
+		return s[key]
+	case []interface{}:
+		for i, elem := range s {
+			if fmt.Sprint(i) == key {
+				return elem
+			}
+		}
+	}
+	return nil
+}
+
+// sensitivePaths converts the sensitive_attributes of a state instance,
+// ex: [[{"type": "get_attr", "value": "password"}]], to the shape used by
+// after_sensitive so both can be walked the same way.
+func sensitivePaths(paths [][]map[string]interface{}) interface{} {
+	root := make(map[string]interface{})
+	for _, path := range paths {
+		node := root
+		for i, step := range path {
+			key := fmt.Sprint(step["value"])
+			if index, ok := step["value"].(map[string]interface{}); ok {
+				key = fmt.Sprint(index["value"])
+			}
+			if i == len(path)-1 {
+				node[key] = true
+				break
+			}
+			child, ok := node[key].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				node[key] = child
+			}
+			node = child
+		}
+	}
+	return root
+}
+
+// jsonStringIndex returns the bounds of the JSON encoded value in raw,
+// searching from the given offset. The value is looked up as the value of key
+// first, ex: "password": "value", so a secret that is repeated in a file is
+// attributed to the right line. -1 is returned if it isn't found.
+func jsonStringIndex(raw string, key string, value string, from int) (int, int) {
+	for _, escapeHTML := range []bool{true, false} {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(escapeHTML)
+		if err := enc.Encode(value); err != nil {
+			return -1, -1
+		}
+		encoded := strings.TrimSuffix(buf.String(), "\n")
+
+		var patterns []*regexp.Regexp
+		if key != "" {
+			patterns = append(patterns, regexp.MustCompile(regexp.QuoteMeta(fmt.Sprintf("%q", key))+`\s*:\s*(`+regexp.QuoteMeta(encoded)+`)`))
+		}
+		patterns = append(patterns, regexp.MustCompile(`(`+regexp.QuoteMeta(encoded)+`)`))
+		for _, pattern := range patterns {
+			if m := pattern.FindStringSubmatchIndex(raw[from:]); m != nil {
+				// exclude the quotes
+				return from + m[2] + 1, from + m[3] - 1
+			}
+		}
+	}
+	return -1, -1
+}
@@ -4,6 +4,7 @@ import (
 	// "encoding/json"
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 	"time"
 
@@ -31,6 +32,13 @@ func augmentGitFinding(finding report.Finding, textFragment *gitdiff.TextFragmen
 			finding.Email = f.PatchHeader.Author.Email
 		}
 		finding.Date = f.PatchHeader.AuthorDate.UTC().Format(time.RFC3339)
+		if f.PatchHeader.Committer != nil {
+			finding.Committer = f.PatchHeader.Committer.Name
+			finding.CommitterEmail = f.PatchHeader.Committer.Email
+		}
+		if !f.PatchHeader.CommitterDate.IsZero() {
+			finding.CommitDate = f.PatchHeader.CommitterDate.UTC().Format(time.RFC3339)
+		}
 	}
 	return finding
 }
@@ -59,10 +67,16 @@ func shannonEntropy(data string) (entropy float64) {
 	return entropy
 }
 
-// filter will dedupe and redact findings
-func filter(findings []report.Finding, redact uint) []report.Finding {
-	var retFindings []report.Finding
+// filter will dedupe and redact or pseudonymize findings. The secrets of all
+// findings are redacted in the context of each, a line near one secret may
+// hold another.
+func filter(findings []report.Finding, redact uint, hmacKey []byte) []report.Finding {
+	var (
+		retFindings  []report.Finding
+		replacements []contextReplacement
+	)
 	for _, f := range findings {
+		secret := f.Secret
 		include := true
 		if strings.Contains(strings.ToLower(f.RuleID), "generic") {
 			for _, fPrime := range findings {
@@ -81,13 +95,31 @@ func filter(findings []report.Finding, redact uint) []report.Finding {
 			}
 		}
 
-		if redact > 0 {
+		if hmacKey != nil {
+			f.Pseudonymize(hmacKey)
+		} else if redact > 0 {
 			f.Redact(redact)
 		}
+		if f.Secret != secret {
+			replacements = append(replacements, contextReplacement{secret, f.Secret})
+		}
 		if include {
 			retFindings = append(retFindings, f)
 		}
 	}
+	if len(replacements) == 0 {
+		return retFindings
+	}
+
+	// longer secrets first so one containing another is replaced whole
+	sort.SliceStable(replacements, func(i, j int) bool {
+		return len(replacements[i].secret) > len(replacements[j].secret)
+	})
+	for i := range retFindings {
+		for _, r := range replacements {
+			retFindings[i].RedactContext(r.secret, r.replacement)
+		}
+	}
 	return retFindings
 }
 
@@ -158,6 +190,9 @@ func printFinding(f report.Finding, noColor bool) {
 
 	fmt.Printf("%-12s %s\n", "RuleID:", f.RuleID)
 	fmt.Printf("%-12s %f\n", "Entropy:", f.Entropy)
+	if len(f.Encodings) > 0 {
+		fmt.Printf("%-12s %s\n", "Decoded:", strings.Join(f.Encodings, " > "))
+	}
 	if f.File == "" {
 		fmt.Println("")
 		return
@@ -187,3 +222,9 @@ func containsDigit(s string) bool {
 	}
 	return false
 }
+
+// contextReplacement is a secret and what filter replaced it with.
+type contextReplacement struct {
+	secret      string
+	replacement string
+}
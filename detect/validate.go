@@ -0,0 +1,99 @@
+package detect
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+)
+
+// validSecret returns true if the secret passes the named structural check.
+// raw is the fragment the secret was found in.
+func validSecret(validator string, secret string, raw string) bool {
+	switch validator {
+	case config.ValidateAWSKeyID:
+		return validAWSKeyID(secret)
+	case config.ValidateAWSSecretKey:
+		return validAWSSecretKey(secret, raw)
+	case config.ValidateJWT:
+		return validJWT(secret)
+	case config.ValidateLuhn:
+		return validLuhn(secret)
+	case config.ValidateBase64:
+		_, ok := decodeBase64(secret)
+		return ok
+	case config.ValidateBasicAuth:
+		return validBasicAuth(secret)
+	}
+	return true
+}
+
+// validBasicAuth returns true if the secret is a base64 encoded user:password
+// pair, as registries store credentials, with a password.
+func validBasicAuth(secret string) bool {
+	decoded, ok := decodeBase64(secret)
+	if !ok {
+		return false
+	}
+	_, password, found := strings.Cut(string(decoded), ":")
+	return found && password != "" && utf8.ValidString(password)
+}
+
+// validJWT returns true if the token has a decodable header and a signature.
+// Unsigned tokens (alg "none") can't be used to impersonate anyone.
+func validJWT(token string) bool {
+	parts := strings.Split(strings.TrimRight(token, "="), ".")
+	if len(parts) != 3 || parts[2] == "" {
+		return false
+	}
+	header, ok := decodeBase64(parts[0])
+	if !ok {
+		return false
+	}
+	var h struct {
+		Alg string `json:"alg"`
+	}
+	if json.Unmarshal(header, &h) == nil && strings.EqualFold(h.Alg, "none") {
+		return false
+	}
+	return true
+}
+
+// validLuhn returns true if the digits in s have a valid Luhn checksum.
+// Spaces and dashes are ignored.
+func validLuhn(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 12 && sum%10 == 0
+}
+
+// decodeBase64 decodes standard or URL-safe base64, with or without padding.
+func decodeBase64(s string) ([]byte, bool) {
+	for _, enc := range []*base64.Encoding{
+		base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding,
+	} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return b, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,95 @@
+package detect
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+// vaultReferencePatterns match the common ways infrastructure code references a
+// secret stored in HashiCorp Vault instead of inlining it.
+var vaultReferencePatterns = []*regexp.Regexp{
+	// consul-template and nomad templates, ex: {{ with secret "secret/data/db" }}
+	regexp.MustCompile(`\{\{-?\s*(?:with\s+)?(?:vault|secret)\s+"[^"]+"[^}]*-?\}\}`),
+	// bank-vaults and vault-env, ex: vault:secret/data/db#password
+	regexp.MustCompile(`\bvault:[a-zA-Z0-9_\-/]+#[a-zA-Z0-9_\-]+`),
+	// spring cloud vault and helmfile, ex: ${vault:secret/db:password}
+	regexp.MustCompile(`\$\{vault:[^}]+\}`),
+	// vals, ex: ref+vault://secret/db#/password
+	regexp.MustCompile(`\bref\+vault://[^\s"']+`),
+}
+
+// vaultPathPattern matches secrets that look like a Vault path.
+var vaultPathPattern = regexp.MustCompile(`^/?(?:secret|kv|database|aws|pki|transit)/[a-zA-Z0-9_\-/]+$`)
+
+// VaultAllowlist ignores findings that are references to secrets stored in
+// HashiCorp Vault rather than secrets themselves. If an address and token
+// are set, secrets that look like Vault paths are confirmed to exist in Vault
+// before being allowed.
+type VaultAllowlist struct {
+	Address string
+	Token   string
+
+	client *http.Client
+	mu     sync.Mutex
+	paths  map[string]bool
+}
+
+// NewVaultAllowlist creates a VaultAllowlist. Address and token are optional.
+func NewVaultAllowlist(address string, token string) *VaultAllowlist {
+	return &VaultAllowlist{
+		Address: strings.TrimSuffix(address, "/"),
+		Token:   token,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		paths:   make(map[string]bool),
+	}
+}
+
+// Allowed returns true if the finding is a Vault reference.
+func (v *VaultAllowlist) Allowed(f report.Finding) bool {
+	for _, re := range vaultReferencePatterns {
+		for _, loc := range re.FindAllStringIndex(f.Line, -1) {
+			if strings.Contains(f.Line[loc[0]:loc[1]], f.Secret) {
+				return true
+			}
+		}
+	}
+	if v.Address != "" && v.Token != "" && vaultPathPattern.MatchString(f.Secret) {
+		return v.pathExists(strings.TrimPrefix(f.Secret, "/"))
+	}
+	return false
+}
+
+// pathExists checks if a secret exists at path in Vault. Results are cached
+// since the same reference tends to appear in many commits. The lock isn't
+// held during the request so lookups of other paths aren't blocked by it.
+func (v *VaultAllowlist) pathExists(path string) bool {
+	v.mu.Lock()
+	exists, ok := v.paths[path]
+	v.mu.Unlock()
+	if ok {
+		return exists
+	}
+
+	req, err := http.NewRequest(http.MethodGet, v.Address+"/v1/"+path, nil)
+	if err == nil {
+		req.Header.Set("X-Vault-Token", v.Token)
+		resp, err := v.client.Do(req)
+		if err != nil {
+			log.Debug().Err(err).Msgf("unable to look up %s in vault", path)
+		} else {
+			resp.Body.Close()
+			exists = resp.StatusCode == http.StatusOK
+		}
+	}
+	v.mu.Lock()
+	v.paths[path] = exists
+	v.mu.Unlock()
+	return exists
+}
@@ -0,0 +1,79 @@
+package detect
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+func TestVaultAllowlist(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/secret/data/db" && r.Header.Get("X-Vault-Token") == "token" {
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	tests := map[string]struct {
+		finding report.Finding
+		allowed bool
+	}{
+		"consul template": {
+			finding: report.Finding{Line: `password = "{{ with secret "secret/data/db" }}{{ .Data.password }}{{ end }}"`, Secret: `secret/data/db`},
+			allowed: true,
+		},
+		"bank-vaults reference": {
+			finding: report.Finding{Line: `DB_PASSWORD=vault:secret/data/db#password`, Secret: `vault:secret/data/db#password`},
+			allowed: true,
+		},
+		"existing vault path": {
+			finding: report.Finding{Line: `password_path: secret/data/db`, Secret: `secret/data/db`},
+			allowed: true,
+		},
+		"missing vault path": {
+			finding: report.Finding{Line: `password_path: secret/data/other`, Secret: `secret/data/other`},
+			allowed: false,
+		},
+		"plain secret": {
+			finding: report.Finding{Line: `password = "8dyfuiRyq=vVc3RRr_edRk-fK__JItpZ"`, Secret: `8dyfuiRyq=vVc3RRr_edRk-fK__JItpZ`},
+			allowed: false,
+		},
+	}
+
+	v := NewVaultAllowlist(server.URL, "token")
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.allowed, v.Allowed(test.finding))
+		})
+	}
+}
+
+func TestVaultAllowlistConcurrentLookups(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/secret/data/slow" {
+			<-release
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	v := NewVaultAllowlist(server.URL, "token")
+	go v.pathExists("secret/data/slow")
+
+	// a slow lookup doesn't hold up lookups of other paths
+	done := make(chan bool)
+	go func() { done <- v.pathExists("secret/data/db") }()
+	select {
+	case exists := <-done:
+		assert.True(t, exists)
+	case <-time.After(5 * time.Second):
+		t.Fatal("lookup blocked by another lookup")
+	}
+}
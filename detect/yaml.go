@@ -0,0 +1,186 @@
+package detect
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+var (
+	kubernetesSecretRule = heuristicRule{
+		RuleID:      "kubernetes-secret",
+		Description: "Found a credential in the data of a Kubernetes Secret manifest.",
+		Tags:        []string{"kubernetes"},
+	}
+	helmValuesRule = heuristicRule{
+		RuleID:      "helm-values-secret",
+		Description: "Found a value assigned to a sensitive key in a Helm values file.",
+		Tags:        []string{"helm"},
+	}
+)
+
+// helmValuesPattern matches helm values files, ex: values.yaml,
+// values-prod.yaml, prod.values.yml
+var helmValuesPattern = regexp.MustCompile(`(?i)(^|/)(values[\w.-]*|[\w.-]+\.values)\.ya?ml$`)
+
+// detectYAML parses YAML fragments and looks inside Kubernetes Secret
+// manifests and Helm values files. findings are the findings already made
+// by the rules so the key heuristics don't report the same line twice.
+func (d *Detector) detectYAML(fragment Fragment, findings []report.Finding) []report.Finding {
+	if !d.YAMLAware {
+		return nil
+	}
+	ext := strings.ToLower(filepath.Ext(fragment.FilePath))
+	if ext != ".yaml" && ext != ".yml" {
+		return nil
+	}
+
+	fragment.newlineIndices = regexp.MustCompile("\n").FindAllStringIndex(fragment.Raw, -1)
+	covered := coveredLines(findings)
+
+	var yamlFindings []report.Finding
+	helmValues := helmValuesPattern.MatchString(filepath.ToSlash(fragment.FilePath))
+	decoder := yaml.NewDecoder(strings.NewReader(fragment.Raw))
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err != nil {
+			if !errors.Is(err, io.EOF) {
+				// helm templates and partial diffs are not valid yaml
+				log.Debug().Err(err).Msgf("skipping yaml detection for %s", fragment.FilePath)
+			}
+			break
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		root := doc.Content[0]
+		switch {
+		case isKubernetesSecret(root):
+			yamlFindings = append(yamlFindings, d.detectKubernetesSecret(fragment, root, covered)...)
+		case helmValues:
+			yamlFindings = append(yamlFindings, d.detectSensitiveKeys(fragment, root, helmValuesRule, covered)...)
+		}
+	}
+	return yamlFindings
+}
+
+// isKubernetesSecret returns true if node is a v1 Secret manifest.
+func isKubernetesSecret(node *yaml.Node) bool {
+	kind := mappingValue(node, "kind")
+	apiVersion := mappingValue(node, "apiVersion")
+	return kind != nil && kind.Value == "Secret" && apiVersion != nil && apiVersion.Value == "v1"
+}
+
+// detectKubernetesSecret runs the rules on the decoded values of a Secret's
+// data and flags sensitive keys in data and stringData.
+func (d *Detector) detectKubernetesSecret(fragment Fragment, secret *yaml.Node, covered map[int]bool) []report.Finding {
+	var findings []report.Finding
+	if data := mappingValue(secret, "data"); data != nil && data.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(data.Content); i += 2 {
+			key, value := data.Content[i], data.Content[i+1]
+			decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value.Value), ""))
+			if err != nil || len(decoded) == 0 {
+				continue
+			}
+
+			ruleFindings := d.detectRules(Fragment{
+				Raw:         string(decoded),
+				FilePath:    fragment.FilePath,
+				SymlinkFile: fragment.SymlinkFile,
+				CommitSHA:   fragment.CommitSHA,
+			})
+			for _, f := range ruleFindings {
+				findings = append(findings, d.relocate(fragment, f, value))
+			}
+			if len(ruleFindings) == 0 {
+				findings = append(findings, d.sensitiveKeyFinding(fragment, key, value, string(decoded), kubernetesSecretRule, covered)...)
+			}
+		}
+	}
+	if stringData := mappingValue(secret, "stringData"); stringData != nil {
+		findings = append(findings, d.detectSensitiveKeys(fragment, stringData, kubernetesSecretRule, covered)...)
+	}
+	return findings
+}
+
+// detectSensitiveKeys walks node and flags scalar values assigned to
+// sensitive key names.
+func (d *Detector) detectSensitiveKeys(fragment Fragment, node *yaml.Node, rule heuristicRule, covered map[int]bool) []report.Finding {
+	var findings []report.Finding
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if value.Kind == yaml.ScalarNode {
+				findings = append(findings, d.sensitiveKeyFinding(fragment, key, value, value.Value, rule, covered)...)
+				continue
+			}
+			findings = append(findings, d.detectSensitiveKeys(fragment, value, rule, covered)...)
+		}
+	case yaml.SequenceNode:
+		for _, n := range node.Content {
+			findings = append(findings, d.detectSensitiveKeys(fragment, n, rule, covered)...)
+		}
+	}
+	return findings
+}
+
+// sensitiveKeyFinding returns a finding if key is a sensitive key name and
+// secret is not a placeholder.
+func (d *Detector) sensitiveKeyFinding(fragment Fragment, key *yaml.Node, value *yaml.Node, secret string, rule heuristicRule, covered map[int]bool) []report.Finding {
+	if value.Tag != "!!str" && value.Tag != "!!binary" && value.Tag != "!!int" {
+		return nil
+	}
+	return d.keyValueFinding(fragment, nodeLocation(fragment, value), key.Value, secret, rule, covered)
+}
+
+// relocate moves a finding made on a decoded value to the location of the
+// encoded value in the manifest.
+func (d *Detector) relocate(fragment Fragment, f report.Finding, value *yaml.Node) report.Finding {
+	return d.relocateTo(fragment, f, nodeLocation(fragment, value))
+}
+
+// nodeLocation converts the 1-based line and column of a yaml node to a
+// Location in the fragment.
+func nodeLocation(fragment Fragment, node *yaml.Node) Location {
+	start := 0
+	if node.Line > 1 && node.Line-2 < len(fragment.newlineIndices) {
+		start = fragment.newlineIndices[node.Line-2][1]
+	}
+	start += node.Column - 1
+	if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		start++
+	}
+	if start > len(fragment.Raw) {
+		start = len(fragment.Raw)
+	}
+	end := start + len(node.Value)
+	if eol := strings.IndexByte(fragment.Raw[start:], '\n'); eol >= 0 && start+eol < end {
+		end = start + eol
+	}
+	if end > len(fragment.Raw) {
+		end = len(fragment.Raw)
+	}
+	return location(fragment, []int{start, end})
+}
+
+// mappingValue returns the value of key in a mapping node.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package issues
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// githubPageLimit is the number of issues requested per page, the maximum
+// GitHub allows.
+const githubPageLimit = 100
+
+// GitHub opens issues in a GitHub repository.
+type GitHub struct {
+	// URL is the API URL, https://api.github.com or that of a GitHub
+	// Enterprise Server.
+	URL   string
+	Repo  string
+	Token string
+
+	client *http.Client
+}
+
+// NewGitHub creates a GitHub tracker for repo, in the owner/name form.
+func NewGitHub(apiURL string, repo string, token string) (*GitHub, error) {
+	if parts := strings.Split(repo, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid GitHub repository %q, must be owner/name", repo)
+	}
+	return &GitHub{
+		URL:    strings.TrimSuffix(apiURL, "/"),
+		Repo:   repo,
+		Token:  token,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (g *GitHub) Fingerprints() (map[string]bool, error) {
+	fingerprints := make(map[string]bool)
+	for page := 1; ; page++ {
+		endpoint := fmt.Sprintf("%s/repos/%s/issues?labels=%s&state=all&per_page=%d&page=%d",
+			g.URL, g.Repo, url.QueryEscape(Label), githubPageLimit, page)
+		var issues []struct {
+			Body string `json:"body"`
+		}
+		if err := g.do(http.MethodGet, endpoint, nil, &issues); err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			parseFingerprints(issue.Body, fingerprints)
+		}
+		if len(issues) < githubPageLimit {
+			return fingerprints, nil
+		}
+	}
+}
+
+func (g *GitHub) Create(title string, body string) (string, error) {
+	issue := map[string]interface{}{
+		"title":  title,
+		"body":   body,
+		"labels": []string{Label},
+	}
+	var created struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := g.do(http.MethodPost, fmt.Sprintf("%s/repos/%s/issues", g.URL, g.Repo), issue, &created); err != nil {
+		return "", err
+	}
+	return created.HTMLURL, nil
+}
+
+func (g *GitHub) do(method string, endpoint string, body interface{}, v interface{}) error {
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, endpoint, &reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+	if g.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.Token)
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s/repos/%s/issues failed: %s", method, g.URL, g.Repo, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,139 @@
+// Package issues opens a tracking issue for every leaked secret on GitHub
+// Issues or Jira.
+package issues
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+// Label is added to every issue gitleaks opens so they can be found again.
+const Label = "gitleaks"
+
+// fingerprintPrefix marks the line of an issue body holding the fingerprint
+// of the secret the issue tracks.
+const fingerprintPrefix = "gitleaks-fingerprint: "
+
+var fingerprintPattern = regexp.MustCompile(`gitleaks-fingerprint: ([0-9a-f]{64})`)
+
+// Tracker is an issue tracker.
+type Tracker interface {
+	// Fingerprints returns the fingerprints found in the bodies of the
+	// issues labeled Label, whether they are open or closed.
+	Fingerprints() (map[string]bool, error)
+
+	// Create opens an issue labeled Label and returns a reference to it.
+	Create(title string, body string) (string, error)
+}
+
+// Open opens an issue for every secret in findings that doesn't have one
+// yet. A secret is identified by its Fingerprint, which is kept in the issue
+// body so closed issues aren't opened again. The secret itself is never
+// included. It returns the references of the issues it opened.
+func Open(tracker Tracker, findings []report.Finding, key []byte) ([]string, error) {
+	if len(key) == 0 {
+		return nil, errors.New("a key is required to fingerprint the secrets issues are opened for")
+	}
+	existing, err := tracker.Fingerprints()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list existing issues: %w", err)
+	}
+	var opened []string
+	for _, group := range report.GroupBySecret(findings) {
+		fingerprint := Fingerprint(group, key)
+		if existing[fingerprint] {
+			log.Debug().Msgf("an issue already tracks secret %s", fingerprint)
+			continue
+		}
+		ref, err := tracker.Create(Title(group), Body(group, findings, key))
+		if err != nil {
+			return opened, err
+		}
+		existing[fingerprint] = true
+		opened = append(opened, ref)
+		log.Info().Msgf("opened issue %s for secret %s", ref, fingerprint)
+	}
+	return opened, nil
+}
+
+// Fingerprint identifies the secret of group in issues, the HMAC-SHA256 of its
+// digest keyed with key. Issue bodies may be widely readable, and unlike a
+// plain hash it can't be brute-forced into the secret without the key.
+func Fingerprint(group report.SecretGroup, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(group.SecretHash))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Title is the title of the issue tracking the secret of group.
+func Title(group report.SecretGroup) string {
+	location := group.Locations[0]
+	title := fmt.Sprintf("Leaked %s in %s", strings.Join(group.RuleIDs, ", "), location.File)
+	if location.Repository != "" {
+		title = fmt.Sprintf("Leaked %s in %s/%s", strings.Join(group.RuleIDs, ", "), location.Repository, location.File)
+	}
+	switch {
+	case group.Count == 2:
+		title += " and 1 other location"
+	case group.Count > 2:
+		title += fmt.Sprintf(" and %d other locations", group.Count-1)
+	}
+	return title
+}
+
+// Body describes where the secret of group was found and ends with its
+// Fingerprint. Permalinks are taken from the findings.
+func Body(group report.SecretGroup, findings []report.Finding, key []byte) string {
+	links := make(map[string]string)
+	for _, f := range findings {
+		link := f.Link
+		if link == "" {
+			link = report.Permalink(f.RemoteURL, f.Commit, f.File, f.StartLine, f.EndLine)
+		}
+		links[f.Repository+"\x00"+f.Fingerprint] = link
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "gitleaks found a secret matching %s in %d locations. ", strings.Join(group.RuleIDs, ", "), group.Count)
+	sb.WriteString("Rotate the secret, then remove it from the code.\n\n")
+	for _, location := range group.Locations {
+		fmt.Fprintf(&sb, "- %s:%d", location.File, location.StartLine)
+		if location.Repository != "" {
+			fmt.Fprintf(&sb, " in %s", location.Repository)
+		}
+		if location.Commit != "" {
+			fmt.Fprintf(&sb, " commit %s", location.Commit)
+		}
+		if link := links[location.Repository+"\x00"+location.Fingerprint]; link != "" {
+			fmt.Fprintf(&sb, " %s", link)
+		}
+		sb.WriteString("\n")
+	}
+	if group.IntroducedIn != "" {
+		fmt.Fprintf(&sb, "\nIntroduced in commit %s.\n", group.IntroducedIn)
+	}
+	if group.RemovedIn != "" {
+		fmt.Fprintf(&sb, "Removed in commit %s after %s days.\n", group.RemovedIn,
+			strconv.FormatFloat(group.ExposureDays, 'f', -1, 64))
+	}
+	fmt.Fprintf(&sb, "\n%s%s\n", fingerprintPrefix, Fingerprint(group, key))
+	return sb.String()
+}
+
+// parseFingerprints adds the fingerprint in body, if there is one, to
+// fingerprints.
+func parseFingerprints(body string, fingerprints map[string]bool) {
+	for _, m := range fingerprintPattern.FindAllStringSubmatch(body, -1) {
+		fingerprints[m[1]] = true
+	}
+}
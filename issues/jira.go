@@ -0,0 +1,111 @@
+package issues
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// jiraPageLimit is the number of issues requested per search.
+const jiraPageLimit = 100
+
+// Jira opens issues in a Jira project.
+type Jira struct {
+	URL       string
+	Project   string
+	IssueType string
+	// User and Token authenticate with basic auth, an API token on Jira
+	// Cloud or a password on Jira Server.
+	User  string
+	Token string
+
+	client *http.Client
+}
+
+// NewJira creates a Jira tracker opening issues of issueType in project.
+func NewJira(baseURL string, project string, issueType string, user string, token string) *Jira {
+	return &Jira{
+		URL:       strings.TrimSuffix(baseURL, "/"),
+		Project:   project,
+		IssueType: issueType,
+		User:      user,
+		Token:     token,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (j *Jira) Fingerprints() (map[string]bool, error) {
+	fingerprints := make(map[string]bool)
+	jql := fmt.Sprintf("project = %q AND labels = %q", j.Project, Label)
+	for startAt := 0; ; startAt += jiraPageLimit {
+		endpoint := fmt.Sprintf("%s/rest/api/2/search?jql=%s&fields=description&startAt=%d&maxResults=%d",
+			j.URL, url.QueryEscape(jql), startAt, jiraPageLimit)
+		var result struct {
+			Total  int `json:"total"`
+			Issues []struct {
+				Fields struct {
+					Description string `json:"description"`
+				} `json:"fields"`
+			} `json:"issues"`
+		}
+		if err := j.do(http.MethodGet, endpoint, nil, &result); err != nil {
+			return nil, err
+		}
+		for _, issue := range result.Issues {
+			parseFingerprints(issue.Fields.Description, fingerprints)
+		}
+		if len(result.Issues) == 0 || startAt+len(result.Issues) >= result.Total {
+			return fingerprints, nil
+		}
+	}
+}
+
+func (j *Jira) Create(title string, body string) (string, error) {
+	issue := map[string]interface{}{
+		"fields": map[string]interface{}{
+			"project":     map[string]string{"key": j.Project},
+			"issuetype":   map[string]string{"name": j.IssueType},
+			"summary":     title,
+			"description": body,
+			"labels":      []string{Label},
+		},
+	}
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := j.do(http.MethodPost, j.URL+"/rest/api/2/issue", issue, &created); err != nil {
+		return "", err
+	}
+	return j.URL + "/browse/" + created.Key, nil
+}
+
+func (j *Jira) do(method string, endpoint string, body interface{}, v interface{}) error {
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, endpoint, &reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	if j.User != "" || j.Token != "" {
+		req.SetBasicAuth(j.User, j.Token)
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s failed: %s", method, strings.SplitN(endpoint, "?", 2)[0], resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
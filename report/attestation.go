@@ -0,0 +1,158 @@
+package report
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	inTotoStatementType = "https://in-toto.io/Statement/v1"
+	inTotoPayloadType   = "application/vnd.in-toto+json"
+
+	// ScanPredicateType identifies the predicate of scan attestations, the
+	// manifest of the scan and its number of findings.
+	ScanPredicateType = "https://gitleaks.io/attestation/scan/v1"
+)
+
+// Statement is an in-toto statement that its subjects, the report files,
+// were produced by the scan described by its predicate.
+type Statement struct {
+	Type          string          `json:"_type"`
+	Subject       []Subject       `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     ScanAttestation `json:"predicate"`
+}
+
+// Subject is a file an attestation is about.
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// ScanAttestation is the predicate of a scan attestation.
+type ScanAttestation struct {
+	Manifest
+	Findings int
+}
+
+// Envelope is a DSSE envelope holding a signed statement.
+type Envelope struct {
+	PayloadType string              `json:"payloadType"`
+	Payload     string              `json:"payload"`
+	Signatures  []EnvelopeSignature `json:"signatures"`
+}
+
+// EnvelopeSignature is a signature of an Envelope. KeyID is the hex encoded
+// SHA-256 hash of the public key.
+type EnvelopeSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// LoadSigningKey reads an ed25519 private key in PKCS #8 PEM form, as written
+// by openssl genpkey -algorithm ed25519.
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM encoded key", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	ed25519Key, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an ed25519 key", path)
+	}
+	return ed25519Key, nil
+}
+
+// SignFile writes the base64 encoded ed25519 signature of the file at path
+// to path+".sig".
+func SignFile(path string, key ed25519.PrivateKey) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(key, content))
+	return os.WriteFile(path+".sig", []byte(signature+"\n"), 0o644)
+}
+
+// WriteAttestation writes an in-toto statement that the files were produced
+// by the scan described by manifest to path. With a key the statement is
+// signed and written in a DSSE envelope.
+func WriteAttestation(path string, files []string, manifest Manifest, findings int, key ed25519.PrivateKey) error {
+	statement := Statement{
+		Type:          inTotoStatementType,
+		PredicateType: ScanPredicateType,
+		Predicate:     ScanAttestation{Manifest: manifest, Findings: findings},
+	}
+	for _, f := range files {
+		digest, err := fileDigest(f)
+		if err != nil {
+			return err
+		}
+		statement.Subject = append(statement.Subject, Subject{
+			Name:   filepath.Base(f),
+			Digest: map[string]string{"sha256": digest},
+		})
+	}
+
+	var v interface{} = statement
+	if key != nil {
+		payload, err := json.Marshal(statement)
+		if err != nil {
+			return err
+		}
+		keyID := sha256.Sum256(key.Public().(ed25519.PublicKey))
+		v = Envelope{
+			PayloadType: inTotoPayloadType,
+			Payload:     base64.StdEncoding.EncodeToString(payload),
+			Signatures: []EnvelopeSignature{{
+				KeyID: hex.EncodeToString(keyID[:]),
+				Sig:   base64.StdEncoding.EncodeToString(ed25519.Sign(key, pae(inTotoPayloadType, payload))),
+			}},
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", " ")
+	return encoder.Encode(v)
+}
+
+// pae is the DSSE pre-authentication encoding of a payload, what is signed
+// in an envelope.
+func pae(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+func fileDigest(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
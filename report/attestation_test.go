@@ -0,0 +1,79 @@
+package report
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignedAttestation(t *testing.T) {
+	dir := t.TempDir()
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	keyPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	key, err := LoadSigningKey(keyPath)
+	require.NoError(t, err)
+
+	reportPath := filepath.Join(dir, "report.json")
+	content := []byte(`[{"RuleID":"aws-access-key"}]`)
+	require.NoError(t, os.WriteFile(reportPath, content, 0o644))
+	require.NoError(t, SignFile(reportPath, key))
+	signature, err := os.ReadFile(reportPath + ".sig")
+	require.NoError(t, err)
+	sig, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature)))
+	require.NoError(t, err)
+	assert.True(t, ed25519.Verify(public, content, sig))
+
+	manifest := Manifest{
+		Version:    "v8.18.0",
+		ConfigHash: "c0ffee",
+		Targets:    []Target{{Name: "github.com/acme/api", Commit: "3f2a1b0c"}},
+	}
+	attestationPath := filepath.Join(dir, "attestation.json")
+	require.NoError(t, WriteAttestation(attestationPath, []string{reportPath}, manifest, 1, key))
+
+	data, err := os.ReadFile(attestationPath)
+	require.NoError(t, err)
+	var envelope Envelope
+	require.NoError(t, json.Unmarshal(data, &envelope))
+	assert.Equal(t, "application/vnd.in-toto+json", envelope.PayloadType)
+	require.Len(t, envelope.Signatures, 1)
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	require.NoError(t, err)
+	sig, err = base64.StdEncoding.DecodeString(envelope.Signatures[0].Sig)
+	require.NoError(t, err)
+	assert.True(t, ed25519.Verify(public, pae(envelope.PayloadType, payload), sig))
+
+	var statement Statement
+	require.NoError(t, json.Unmarshal(payload, &statement))
+	assert.Equal(t, "https://in-toto.io/Statement/v1", statement.Type)
+	assert.Equal(t, ScanPredicateType, statement.PredicateType)
+	digest := sha256.Sum256(content)
+	assert.Equal(t, []Subject{{Name: "report.json", Digest: map[string]string{"sha256": hex.EncodeToString(digest[:])}}}, statement.Subject)
+	assert.Equal(t, manifest.Targets, statement.Predicate.Targets)
+	assert.Equal(t, "c0ffee", statement.Predicate.ConfigHash)
+	assert.Equal(t, 1, statement.Predicate.Findings)
+
+	// without a key the statement is written as is
+	require.NoError(t, WriteAttestation(attestationPath, []string{reportPath}, manifest, 1, nil))
+	data, err = os.ReadFile(attestationPath)
+	require.NoError(t, err)
+	statement = Statement{}
+	require.NoError(t, json.Unmarshal(data, &statement))
+	assert.Equal(t, ScanPredicateType, statement.PredicateType)
+	assert.Len(t, statement.Subject, 1)
+}
@@ -30,6 +30,14 @@ func writeCsv(f []Finding, w io.WriteCloser) error {
 		"Email",
 		"Fingerprint",
 		"Tags",
+		"Committer",
+		"CommitterEmail",
+		"CommitDate",
+		"Repository",
+		"RemoteURL",
+		"Link",
+		"Severity",
+		"Confidence",
 	})
 	if err != nil {
 		return err
@@ -51,6 +59,14 @@ func writeCsv(f []Finding, w io.WriteCloser) error {
 			f.Email,
 			f.Fingerprint,
 			strings.Join(f.Tags, " "),
+			f.Committer,
+			f.CommitterEmail,
+			f.CommitDate,
+			f.Repository,
+			f.RemoteURL,
+			f.Link,
+			f.Severity,
+			f.Confidence,
 		})
 		if err != nil {
 			return err
@@ -0,0 +1,116 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// defectDojoReport is DefectDojo's "Generic Findings Import" JSON format.
+type defectDojoReport struct {
+	Findings []defectDojoFinding `json:"findings"`
+}
+
+type defectDojoFinding struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Severity    string `json:"severity"`
+	Mitigation  string `json:"mitigation"`
+	Impact      string `json:"impact"`
+	References  string `json:"references,omitempty"`
+	FilePath    string `json:"file_path"`
+	Line        int    `json:"line"`
+	CWE         int    `json:"cwe"`
+	Date        string `json:"date,omitempty"`
+	// UniqueIDFromTool is the dedup key, DefectDojo deduplicates on it when
+	// the test is configured to.
+	UniqueIDFromTool string `json:"unique_id_from_tool"`
+	VulnIDFromTool   string `json:"vuln_id_from_tool"`
+	Active           bool   `json:"active"`
+	Verified         bool   `json:"verified"`
+	StaticFinding    bool   `json:"static_finding"`
+	DynamicFinding   bool   `json:"dynamic_finding"`
+}
+
+// vulnerabilitySeverity maps the severity of a finding to the capitalized
+// severities of vulnerability managers. Leaked secrets without a severity
+// are high.
+func vulnerabilitySeverity(f Finding) string {
+	switch strings.ToLower(f.Severity) {
+	case LevelCritical:
+		return "Critical"
+	case LevelMedium:
+		return "Medium"
+	case LevelLow:
+		return "Low"
+	default:
+		return "High"
+	}
+}
+
+const secretMitigation = "Rotate the secret, then remove it from the code and its history."
+
+// dedupKey identifies a finding across scans. Fingerprints are only unique
+// within a repository, so the repository is included when known.
+func dedupKey(f Finding) string {
+	if f.Repository != "" {
+		return f.Repository + ":" + f.Fingerprint
+	}
+	return f.Fingerprint
+}
+
+func findingTitle(f Finding) string {
+	return fmt.Sprintf("%s in %s", f.RuleID, f.File)
+}
+
+func findingDescription(f Finding) string {
+	var sb strings.Builder
+	if f.Description != "" {
+		fmt.Fprintf(&sb, "%s\n\n", f.Description)
+	}
+	fmt.Fprintf(&sb, "Rule: %s\nMatch: %s\n", f.RuleID, f.Match)
+	if f.Commit != "" {
+		fmt.Fprintf(&sb, "Commit: %s\nAuthor: %s <%s>\nDate: %s\n", f.Commit, f.Author, f.Email, f.Date)
+	}
+	if f.Repository != "" {
+		fmt.Fprintf(&sb, "Repository: %s\n", f.Repository)
+	}
+	fmt.Fprintf(&sb, "Fingerprint: %s\n", f.Fingerprint)
+	return sb.String()
+}
+
+func writeDefectDojo(findings []Finding, w io.WriteCloser) error {
+	ddReport := defectDojoReport{Findings: []defectDojoFinding{}}
+	for _, f := range findings {
+		date := f.Date
+		if len(date) >= len("2006-01-02") {
+			date = date[:len("2006-01-02")]
+		}
+		link := f.Link
+		if link == "" {
+			link = Permalink(f.RemoteURL, f.Commit, f.File, f.StartLine, f.EndLine)
+		}
+		ddReport.Findings = append(ddReport.Findings, defectDojoFinding{
+			Title:            findingTitle(f),
+			Description:      findingDescription(f),
+			Severity:         vulnerabilitySeverity(f),
+			Mitigation:       secretMitigation,
+			Impact:           CWE_DESCRIPTION,
+			References:       link,
+			FilePath:         f.File,
+			Line:             f.StartLine,
+			CWE:              798,
+			Date:             date,
+			UniqueIDFromTool: dedupKey(f),
+			VulnIDFromTool:   f.RuleID,
+			Active:           true,
+			Verified:         f.Verified != nil && *f.Verified,
+			StaticFinding:    true,
+		})
+	}
+	defer w.Close()
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", " ")
+	return encoder.Encode(ddReport)
+}
@@ -0,0 +1,33 @@
+package report
+
+// ReportDiff is how the findings of two reports of the same target differ,
+// compared by fingerprint.
+type ReportDiff struct {
+	// New findings are only in the newer report, Resolved ones only in the
+	// older one and Persisting ones in both.
+	New        []Finding
+	Resolved   []Finding
+	Persisting []Finding
+}
+
+// Diff compares the findings of an older and a newer report. Findings keep
+// the order of the report they are taken from, persisting ones that of the
+// newer report.
+func Diff(older []Finding, newer []Finding) ReportDiff {
+	diff := ReportDiff{New: []Finding{}, Resolved: []Finding{}, Persisting: []Finding{}}
+	before := fingerprints(older)
+	after := fingerprints(newer)
+	for _, f := range newer {
+		if before[f.Fingerprint] {
+			diff.Persisting = append(diff.Persisting, f)
+		} else {
+			diff.New = append(diff.New, f)
+		}
+	}
+	for _, f := range older {
+		if !after[f.Fingerprint] {
+			diff.Resolved = append(diff.Resolved, f)
+		}
+	}
+	return diff
+}
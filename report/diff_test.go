@@ -0,0 +1,20 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	older := []Finding{{Fingerprint: "a"}, {Fingerprint: "b"}, {Fingerprint: "c"}}
+	newer := []Finding{{Fingerprint: "d"}, {Fingerprint: "c"}, {Fingerprint: "a"}}
+
+	diff := Diff(older, newer)
+	assert.Equal(t, []Finding{{Fingerprint: "d"}}, diff.New)
+	assert.Equal(t, []Finding{{Fingerprint: "b"}}, diff.Resolved)
+	assert.Equal(t, []Finding{{Fingerprint: "c"}, {Fingerprint: "a"}}, diff.Persisting)
+
+	diff = Diff(nil, nil)
+	assert.Equal(t, ReportDiff{New: []Finding{}, Resolved: []Finding{}, Persisting: []Finding{}}, diff)
+}
@@ -0,0 +1,185 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+// Events are written one per line (NDJSON), the format security data lakes
+// and log shippers ingest.
+
+// ocsfVersion is the version of the OCSF schema events are written in.
+const ocsfVersion = "1.1.0"
+
+// OCSF Detection Finding class, https://schema.ocsf.io/1.1.0/classes/detection_finding
+const (
+	ocsfCategoryFindings      = 2
+	ocsfClassDetectionFinding = 2004
+	ocsfActivityCreate        = 1
+	ocsfStatusNew             = 1
+)
+
+var ocsfSeverityIDs = map[string]int{
+	LevelLow:      2,
+	LevelMedium:   3,
+	LevelHigh:     4,
+	LevelCritical: 5,
+}
+
+// ecsSeverities are the numeric event severities of ECS, which leaves the
+// scale to the source. These follow the common 21/47/73/99 scale.
+var ecsSeverities = map[string]int{
+	LevelLow:      21,
+	LevelMedium:   47,
+	LevelHigh:     73,
+	LevelCritical: 99,
+}
+
+// eventTime is when the secret was committed, or when the scan started for
+// findings outside of git history.
+func eventTime(f Finding, manifest Manifest) time.Time {
+	if t, err := time.Parse(time.RFC3339, f.Date); err == nil {
+		return t.UTC()
+	}
+	if !manifest.StartTime.IsZero() {
+		return manifest.StartTime.UTC()
+	}
+	return time.Now().UTC()
+}
+
+// eventSeverity is the severity of a finding, high for rules without one.
+func eventSeverity(f Finding) string {
+	if ValidLevel(f.Severity) {
+		return strings.ToLower(f.Severity)
+	}
+	return LevelHigh
+}
+
+// gitDetails are the git fields of a finding, which neither schema has a
+// place for.
+func gitDetails(f Finding) map[string]interface{} {
+	details := map[string]interface{}{}
+	if f.Commit != "" {
+		details["commit"] = f.Commit
+		details["author"] = f.Author
+		details["email"] = f.Email
+		details["date"] = f.Date
+		details["message"] = f.Message
+	}
+	if f.Repository != "" {
+		details["repository"] = f.Repository
+	}
+	if f.RemoteURL != "" {
+		details["remote_url"] = f.RemoteURL
+	}
+	if f.Link != "" {
+		details["link"] = f.Link
+	}
+	return details
+}
+
+func ocsfEvent(f Finding, manifest Manifest) map[string]interface{} {
+	severity := eventSeverity(f)
+	event := map[string]interface{}{
+		"category_uid": ocsfCategoryFindings,
+		"class_uid":    ocsfClassDetectionFinding,
+		"activity_id":  ocsfActivityCreate,
+		"type_uid":     ocsfClassDetectionFinding*100 + ocsfActivityCreate,
+		"severity_id":  ocsfSeverityIDs[severity],
+		"severity":     vulnerabilitySeverity(f),
+		"status_id":    ocsfStatusNew,
+		"time":         eventTime(f, manifest).UnixMilli(),
+		"message":      f.Description,
+		"metadata": map[string]interface{}{
+			"version": ocsfVersion,
+			"product": map[string]interface{}{
+				"name":        driver,
+				"vendor_name": driver,
+				"version":     manifest.Version,
+			},
+		},
+		"finding_info": map[string]interface{}{
+			"uid":   f.Fingerprint,
+			"title": findingTitle(f),
+			"desc":  f.Description,
+			"types": []string{f.RuleID},
+			"analytic": map[string]interface{}{
+				"uid":     f.RuleID,
+				"name":    f.RuleID,
+				"type_id": 1, // rule
+			},
+		},
+		"resources": []map[string]interface{}{{
+			"type": "file",
+			"name": f.File,
+			"data": map[string]interface{}{
+				"start_line":   f.StartLine,
+				"end_line":     f.EndLine,
+				"start_column": f.StartColumn,
+				"end_column":   f.EndColumn,
+				"match":        f.Match,
+			},
+		}},
+	}
+	if details := gitDetails(f); len(details) > 0 {
+		event["unmapped"] = details
+	}
+	return event
+}
+
+func ecsEvent(f Finding, manifest Manifest) map[string]interface{} {
+	severity := eventSeverity(f)
+	event := map[string]interface{}{
+		"@timestamp": eventTime(f, manifest).Format(time.RFC3339),
+		"ecs":        map[string]string{"version": "8.11.0"},
+		"message":    f.Description,
+		"event": map[string]interface{}{
+			"kind":     "alert",
+			"category": []string{"intrusion_detection"},
+			"type":     []string{"info"},
+			"module":   driver,
+			"dataset":  driver + ".finding",
+			"id":       f.Fingerprint,
+			"severity": ecsSeverities[severity],
+		},
+		"observer": map[string]string{
+			"vendor":  driver,
+			"product": driver,
+			"version": manifest.Version,
+		},
+		"rule": map[string]string{
+			"id":          f.RuleID,
+			"name":        f.RuleID,
+			"description": f.Description,
+		},
+		"file": map[string]string{
+			"path": f.File,
+		},
+		"gitleaks": map[string]interface{}{
+			"severity":     severity,
+			"match":        f.Match,
+			"start_line":   f.StartLine,
+			"end_line":     f.EndLine,
+			"start_column": f.StartColumn,
+			"end_column":   f.EndColumn,
+			"git":          gitDetails(f),
+		},
+	}
+	if len(f.Tags) > 0 {
+		event["tags"] = f.Tags
+	}
+	return event
+}
+
+func writeEvents(findings []Finding, manifest Manifest, w io.WriteCloser, event func(Finding, Manifest) map[string]interface{}) error {
+	defer w.Close()
+	encoder := json.NewEncoder(w)
+	for _, f := range findings {
+		if err := encoder.Encode(event(f, manifest)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package report
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+)
+
+func readEvents(t *testing.T, format string, manifest Manifest) []map[string]interface{} {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	require.NoError(t, Write(vulnerabilityFindings, config.Config{}, format, path, manifest))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var events []map[string]interface{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func TestWriteOCSF(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	events := readEvents(t, "ocsf", Manifest{Version: "v8.18.0", StartTime: start})
+	require.Len(t, events, 2)
+
+	e := events[0]
+	assert.EqualValues(t, 2004, e["class_uid"])
+	assert.EqualValues(t, 200401, e["type_uid"])
+	assert.EqualValues(t, 5, e["severity_id"])
+	assert.Equal(t, "Critical", e["severity"])
+	assert.EqualValues(t, time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC).UnixMilli(), e["time"])
+	assert.Equal(t, "v8.18.0", e["metadata"].(map[string]interface{})["product"].(map[string]interface{})["version"])
+	assert.Equal(t, "b6b0bd6:main.tf:aws-access-key:3", e["finding_info"].(map[string]interface{})["uid"])
+	assert.Equal(t, "main.tf", e["resources"].([]interface{})[0].(map[string]interface{})["name"])
+	assert.Equal(t, "b6b0bd6", e["unmapped"].(map[string]interface{})["commit"])
+
+	// findings outside of git history use the scan's start and default to high
+	e = events[1]
+	assert.EqualValues(t, 4, e["severity_id"])
+	assert.EqualValues(t, start.UnixMilli(), e["time"])
+	assert.NotContains(t, e, "unmapped")
+}
+
+func TestWriteECS(t *testing.T) {
+	events := readEvents(t, "ecs", Manifest{})
+	require.Len(t, events, 2)
+
+	e := events[0]
+	assert.Equal(t, "2024-05-06T09:00:00Z", e["@timestamp"])
+	event := e["event"].(map[string]interface{})
+	assert.Equal(t, "alert", event["kind"])
+	assert.EqualValues(t, 99, event["severity"])
+	assert.Equal(t, "aws-access-key", e["rule"].(map[string]interface{})["id"])
+	assert.Equal(t, "main.tf", e["file"].(map[string]interface{})["path"])
+	assert.Equal(t, "infra", e["gitleaks"].(map[string]interface{})["git"].(map[string]interface{})["repository"])
+
+	assert.EqualValues(t, 73, events[1]["event"].(map[string]interface{})["severity"])
+}
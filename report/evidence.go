@@ -0,0 +1,84 @@
+package report
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+)
+
+// WriteEvidence writes a zip bundle proving a scan ran to path, for auditors
+// asking for evidence of periodic secret scanning. It holds the files, the
+// reports and anything written next to them, the manifest with the gitleaks
+// version and the scanned commits, the effective config and a SHA256SUMS of
+// all of them. The SHA-256 checksum of the bundle is written to
+// path+".sha256", both in the format of sha256sum.
+func WriteEvidence(path string, files []string, manifest Manifest, cfg config.Config) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	h := sha256.New()
+	archive := zip.NewWriter(io.MultiWriter(file, h))
+
+	var sums strings.Builder
+	add := func(name string, write func(w io.Writer) error) error {
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: manifest.EndTime}
+		w, err := archive.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		fileHash := sha256.New()
+		if err := write(io.MultiWriter(w, fileHash)); err != nil {
+			return fmt.Errorf("adding %s to evidence: %w", name, err)
+		}
+		fmt.Fprintf(&sums, "%x  %s\n", fileHash.Sum(nil), name)
+		return nil
+	}
+
+	for _, f := range files {
+		f := f
+		if err := add(filepath.Base(f), func(w io.Writer) error {
+			src, err := os.Open(f)
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+			_, err = io.Copy(w, src)
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+	if err := add("manifest.json", func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", " ")
+		return encoder.Encode(manifest)
+	}); err != nil {
+		return err
+	}
+	if err := add("config.json", cfg.Export); err != nil {
+		return err
+	}
+	w, err := archive.CreateHeader(&zip.FileHeader{Name: "SHA256SUMS", Method: zip.Deflate, Modified: manifest.EndTime})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, sums.String()); err != nil {
+		return err
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+
+	checksum := fmt.Sprintf("%s  %s\n", hex.EncodeToString(h.Sum(nil)), filepath.Base(path))
+	return os.WriteFile(path+".sha256", []byte(checksum), 0o644)
+}
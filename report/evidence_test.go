@@ -0,0 +1,60 @@
+package report
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+)
+
+func TestWriteEvidence(t *testing.T) {
+	dir := t.TempDir()
+	reportPath := filepath.Join(dir, "report.json")
+	require.NoError(t, os.WriteFile(reportPath, []byte("[]\n"), 0o644))
+	manifest := Manifest{
+		Version: "v8.18.0",
+		EndTime: time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC),
+		Targets: []Target{{Name: "github.com/acme/api", Commit: "3f2a1b0c"}},
+	}
+
+	bundlePath := filepath.Join(dir, "evidence.zip")
+	require.NoError(t, WriteEvidence(bundlePath, []string{reportPath}, manifest, config.Config{}))
+
+	archive, err := zip.OpenReader(bundlePath)
+	require.NoError(t, err)
+	defer archive.Close()
+	contents := make(map[string]string)
+	for _, f := range archive.File {
+		assert.True(t, manifest.EndTime.Equal(f.Modified), f.Name)
+		r, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		contents[f.Name] = string(data)
+	}
+	assert.Equal(t, "[]\n", contents["report.json"])
+	assert.Contains(t, contents["manifest.json"], `"Commit": "3f2a1b0c"`)
+	assert.Contains(t, contents["config.json"], `"rules": []`)
+
+	var sums []string
+	for _, name := range []string{"report.json", "manifest.json", "config.json"} {
+		sums = append(sums, fmt.Sprintf("%x  %s", sha256.Sum256([]byte(contents[name])), name))
+	}
+	assert.Equal(t, strings.Join(sums, "\n")+"\n", contents["SHA256SUMS"])
+
+	bundle, err := os.ReadFile(bundlePath)
+	require.NoError(t, err)
+	checksum, err := os.ReadFile(bundlePath + ".sha256")
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%x  evidence.zip\n", sha256.Sum256(bundle)), string(checksum))
+}
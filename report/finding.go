@@ -1,6 +1,9 @@
 package report
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"math"
 	"strings"
 )
@@ -14,14 +17,32 @@ type Finding struct {
 	StartColumn int
 	EndColumn   int
 
+	// StartOffset and EndOffset are the byte offsets of the start and end of
+	// the secret in the file, or the blob at the commit for git scans, so it
+	// can be masked without searching for it. They are not set, EndOffset is
+	// 0, when the secret can't be located, ex: decoded secrets.
+	StartOffset int `json:",omitempty"`
+	EndOffset   int `json:",omitempty"`
+
 	Line string `json:"-"`
 
+	// ContextBefore and ContextAfter are the lines surrounding the match,
+	// populated when context lines are requested.
+	ContextBefore []string `json:",omitempty"`
+	ContextAfter  []string `json:",omitempty"`
+
 	Match string
 
 	// Secret contains the full content of what is matched in
 	// the tree-sitter query.
 	Secret string
 
+	// SecretHash is the SHA-256 of the secret. It is only set once the
+	// secret has been redacted so findings can still be correlated during
+	// the scan. It is never written to reports, where the unsalted hash of a
+	// short or low entropy secret could be brute-forced.
+	SecretHash string `json:"-"`
+
 	// File is the name of the file containing the finding
 	File        string
 	SymlinkFile string
@@ -34,7 +55,93 @@ type Finding struct {
 	Email   string
 	Date    string
 	Message string
-	Tags    []string
+
+	// Committer, CommitterEmail and CommitDate describe who committed the
+	// change, which differs from the author on rebased or cherry-picked commits.
+	Committer      string `json:",omitempty"`
+	CommitterEmail string `json:",omitempty"`
+	CommitDate     string `json:",omitempty"`
+
+	// SignatureStatus is the result of verifying the signature of the commit
+	// that introduced the secret: good, bad, untrusted, expired, expired-key,
+	// revoked-key, unknown-key or none when it isn't signed. Signer and
+	// SigningKey identify who signed it.
+	SignatureStatus string `json:",omitempty"`
+	Signer          string `json:",omitempty"`
+	SigningKey      string `json:",omitempty"`
+
+	// Repository and RemoteURL identify which repository the finding
+	// was discovered in.
+	Repository string `json:",omitempty"`
+	RemoteURL  string `json:",omitempty"`
+
+	// RepositoryVisibility (public, private or internal), RepositoryTopics,
+	// DefaultBranch and RepositoryArchived describe the repository as its
+	// hosting platform does when it was listed through the platform's API,
+	// so leaks in public repositories can be prioritized.
+	RepositoryVisibility string   `json:",omitempty"`
+	RepositoryTopics     []string `json:",omitempty"`
+	DefaultBranch        string   `json:",omitempty"`
+	RepositoryArchived   bool     `json:",omitempty"`
+
+	// PubliclyExposed reports whether the commit of the finding is on a
+	// branch of PublicRemote, a remote of the repository anyone can read.
+	PubliclyExposed bool   `json:",omitempty"`
+	PublicRemote    string `json:",omitempty"`
+
+	// Link is a permalink to the lines of the file at the commit when the
+	// remote is hosted on GitHub, GitLab or Bitbucket.
+	Link string `json:",omitempty"`
+
+	Tags []string
+
+	// Component is the part of the repository the finding belongs to when
+	// findings are partitioned, e.g. the top-level directory.
+	Component string `json:",omitempty"`
+
+	// Owners are the teams or users owning the file according to the
+	// repository's CODEOWNERS file.
+	Owners []string `json:",omitempty"`
+
+	// Severity is the impact of the secret leaking, taken from the rule.
+	// Confidence is how likely it is the finding is a real secret.
+	Severity   string `json:",omitempty"`
+	Confidence string `json:",omitempty"`
+
+	// IntroducedIn is the earliest commit containing the secret and
+	// PresentAtHead reports whether the secret still exists at HEAD. This
+	// separates live exposure from leaks that only exist in history.
+	IntroducedIn  string `json:",omitempty"`
+	PresentAtHead *bool  `json:",omitempty"`
+	// HeadLine is the line the secret is on in the file at HEAD, counting
+	// from 1, and HeadLineContent that line. They are only set if the file
+	// still holds the secret, the line can have moved since Commit.
+	HeadLine        int    `json:",omitempty"`
+	HeadLineContent string `json:",omitempty"`
+
+	// RemovedIn is the commit the secret was last deleted in, on the way to
+	// HEAD, and RemovedDate when it was committed. ExposureDays is how long
+	// the secret was in the repository, from IntroducedIn until RemovedIn or,
+	// for secrets still present at HEAD, until the scan.
+	RemovedIn    string  `json:",omitempty"`
+	RemovedDate  string  `json:",omitempty"`
+	ExposureDays float64 `json:",omitempty"`
+
+	// BlameCommit, BlameAuthor, BlameEmail and BlameDate attribute the line
+	// holding the secret at HEAD to the commit that last changed it, which
+	// can differ from Commit when the finding comes from a later refactor.
+	BlameCommit string `json:",omitempty"`
+	BlameAuthor string `json:",omitempty"`
+	BlameEmail  string `json:",omitempty"`
+	BlameDate   string `json:",omitempty"`
+
+	// Encodings lists the encodings that were removed to find the secret,
+	// outermost first, ex: ["base64", "hex"].
+	Encodings []string `json:",omitempty"`
+
+	// Verified reports whether the secret was confirmed to be active with
+	// its provider. It is only set for rules that support verification.
+	Verified *bool `json:",omitempty"`
 
 	// Rule is the name of the rule that was matched
 	RuleID string
@@ -45,13 +152,51 @@ type Finding struct {
 
 // Redact removes sensitive information from a finding.
 func (f *Finding) Redact(percent uint) {
+	if f.SecretHash == "" {
+		f.SecretHash = hashSecret(f.Secret)
+	}
 	secret := maskSecret(f.Secret, percent)
 	if percent >= 100 {
 		secret = "REDACTED"
 	}
-	f.Line = strings.Replace(f.Line, f.Secret, secret, -1)
-	f.Match = strings.Replace(f.Match, f.Secret, secret, -1)
-	f.Secret = secret
+	f.replaceSecret(secret)
+}
+
+// Pseudonymize replaces the secret with "hmac:" followed by its HMAC-SHA256
+// keyed with key, which also becomes the SecretHash. The same secret gets the
+// same pseudonym wherever the key is used, so findings can be correlated
+// across repositories and scans without the secret being stored, and unlike
+// a plain hash the pseudonym can't be brute-forced without the key.
+func (f *Finding) Pseudonymize(key []byte) {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(f.Secret))
+	f.SecretHash = hex.EncodeToString(mac.Sum(nil))
+	f.replaceSecret("hmac:" + f.SecretHash)
+}
+
+// replaceSecret replaces the secret, and every occurrence of it in the
+// matched and surrounding lines, with replacement.
+func (f *Finding) replaceSecret(replacement string) {
+	f.Match = strings.Replace(f.Match, f.Secret, replacement, -1)
+	f.RedactContext(f.Secret, replacement)
+	f.Secret = replacement
+}
+
+// RedactContext replaces every occurrence of secret in the line and the
+// surrounding lines of the finding with replacement. The lines around a
+// secret may hold other secrets, which have to be redacted like the
+// finding's own.
+func (f *Finding) RedactContext(secret string, replacement string) {
+	if secret == "" || secret == replacement {
+		return
+	}
+	f.Line = strings.Replace(f.Line, secret, replacement, -1)
+	for i := range f.ContextBefore {
+		f.ContextBefore[i] = strings.Replace(f.ContextBefore[i], secret, replacement, -1)
+	}
+	for i := range f.ContextAfter {
+		f.ContextAfter[i] = strings.Replace(f.ContextAfter[i], secret, replacement, -1)
+	}
 }
 
 func maskSecret(secret string, percent uint) string {
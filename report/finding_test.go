@@ -29,6 +29,29 @@ func TestRedact(t *testing.T) {
 	}
 }
 
+func TestPseudonymize(t *testing.T) {
+	pseudonymize := func(key string, secret string) Finding {
+		f := Finding{
+			Match:         "token = " + secret,
+			Secret:        secret,
+			ContextBefore: []string{"# " + secret},
+		}
+		f.Pseudonymize([]byte(key))
+		return f
+	}
+
+	f := pseudonymize("k3y", "secret")
+	assert.Equal(t, "hmac:"+f.SecretHash, f.Secret)
+	assert.Equal(t, "token = "+f.Secret, f.Match)
+	assert.Equal(t, []string{"# " + f.Secret}, f.ContextBefore)
+	assert.Equal(t, f.SecretHash, f.SecretDigest())
+	assert.NotEqual(t, hashSecret("secret"), f.SecretHash)
+
+	assert.Equal(t, f.Secret, pseudonymize("k3y", "secret").Secret)
+	assert.NotEqual(t, f.Secret, pseudonymize("other", "secret").Secret)
+	assert.NotEqual(t, f.Secret, pseudonymize("k3y", "other").Secret)
+}
+
 func TestMask(t *testing.T) {
 
 	tests := map[string]struct {
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// GHASAlert mirrors a GitHub secret scanning alert as returned by the
+// /repos/{owner}/{repo}/secret-scanning/alerts API, with its locations
+// inlined, so tools consuming those alerts can read gitleaks findings too.
+type GHASAlert struct {
+	Number                int            `json:"number"`
+	CreatedAt             string         `json:"created_at,omitempty"`
+	State                 string         `json:"state"`
+	Resolution            *string        `json:"resolution"`
+	SecretType            string         `json:"secret_type"`
+	SecretTypeDisplayName string         `json:"secret_type_display_name"`
+	Secret                string         `json:"secret"`
+	Validity              string         `json:"validity"`
+	Repository            string         `json:"repository,omitempty"`
+	Locations             []GHASLocation `json:"locations"`
+}
+
+// GHASLocation is where the secret of an alert was found.
+type GHASLocation struct {
+	Type    string              `json:"type"`
+	Details GHASLocationDetails `json:"details"`
+}
+
+// GHASLocationDetails are the details of a commit or file location.
+type GHASLocationDetails struct {
+	Path        string `json:"path"`
+	StartLine   int    `json:"start_line"`
+	EndLine     int    `json:"end_line"`
+	StartColumn int    `json:"start_column"`
+	EndColumn   int    `json:"end_column"`
+	CommitSHA   string `json:"commit_sha,omitempty"`
+	BlobURL     string `json:"blob_url,omitempty"`
+}
+
+// GHASAlerts converts findings to GitHub secret scanning alerts. Like GitHub,
+// there is one alert per secret and repository with every location it was
+// found in.
+func GHASAlerts(findings []Finding) []GHASAlert {
+	alerts := []GHASAlert{}
+	index := make(map[string]int)
+	for _, f := range findings {
+		key := f.Repository + "\x00" + f.SecretDigest()
+		i, ok := index[key]
+		if !ok {
+			i = len(alerts)
+			index[key] = i
+			validity := "unknown"
+			if f.Verified != nil {
+				validity = "inactive"
+				if *f.Verified {
+					validity = "active"
+				}
+			}
+			alerts = append(alerts, GHASAlert{
+				Number:                i + 1,
+				CreatedAt:             f.Date,
+				State:                 "open",
+				SecretType:            strings.ReplaceAll(f.RuleID, "-", "_"),
+				SecretTypeDisplayName: f.Description,
+				Secret:                f.Secret,
+				Validity:              validity,
+				Repository:            f.Repository,
+			})
+		}
+		locationType := "commit"
+		if f.Commit == "" {
+			locationType = "file"
+		}
+		link := f.Link
+		if link == "" {
+			link = Permalink(f.RemoteURL, f.Commit, f.File, f.StartLine, f.EndLine)
+		}
+		alerts[i].Locations = append(alerts[i].Locations, GHASLocation{
+			Type: locationType,
+			Details: GHASLocationDetails{
+				Path:        f.File,
+				StartLine:   f.StartLine,
+				EndLine:     f.EndLine,
+				StartColumn: f.StartColumn,
+				EndColumn:   f.EndColumn,
+				CommitSHA:   f.Commit,
+				BlobURL:     link,
+			},
+		})
+	}
+	return alerts
+}
+
+func writeGHAS(findings []Finding, w io.WriteCloser) error {
+	defer w.Close()
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", " ")
+	return encoder.Encode(GHASAlerts(findings))
+}
@@ -0,0 +1,117 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// SecretGroup contains every location a single secret was found in. Responders
+// rotate credentials, not individual findings, so grouping by the secret shows
+// the full blast radius of a single leaked credential.
+type SecretGroup struct {
+	// SecretHash is the hex encoded SHA-256 of the secret. Like the
+	// SecretHash of findings it is not written to reports.
+	SecretHash string `json:"-"`
+	Secret     string
+	RuleIDs    []string
+	Count      int
+
+	// IntroducedIn, PresentAtHead, RemovedIn and ExposureDays are only set
+	// when history analysis is enabled for git scans.
+	IntroducedIn  string  `json:",omitempty"`
+	PresentAtHead *bool   `json:",omitempty"`
+	RemovedIn     string  `json:",omitempty"`
+	ExposureDays  float64 `json:",omitempty"`
+
+	Locations []SecretLocation
+}
+
+// SecretLocation is a single occurrence of a secret.
+type SecretLocation struct {
+	File        string
+	StartLine   int
+	Commit      string `json:",omitempty"`
+	Author      string `json:",omitempty"`
+	Date        string `json:",omitempty"`
+	Repository  string `json:",omitempty"`
+	Fingerprint string
+}
+
+// hashSecret returns the hex encoded SHA-256 of a secret.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// SecretDigest returns the SHA-256 identifying the secret of a finding. Redacted
+// findings carry the digest of the original secret.
+func (f Finding) SecretDigest() string {
+	if f.SecretHash != "" {
+		return f.SecretHash
+	}
+	return hashSecret(f.Secret)
+}
+
+// GroupBySecret groups findings by their secret. Groups are ordered by the
+// number of locations the secret was found in, most first.
+func GroupBySecret(findings []Finding) []SecretGroup {
+	var groups []SecretGroup
+	index := make(map[string]int)
+	for _, f := range findings {
+		hash := f.SecretDigest()
+		i, ok := index[hash]
+		if !ok {
+			i = len(groups)
+			index[hash] = i
+			groups = append(groups, SecretGroup{
+				SecretHash:    hash,
+				Secret:        f.Secret,
+				IntroducedIn:  f.IntroducedIn,
+				PresentAtHead: f.PresentAtHead,
+				RemovedIn:     f.RemovedIn,
+				ExposureDays:  f.ExposureDays,
+			})
+		}
+		g := &groups[i]
+		if !contains(g.RuleIDs, f.RuleID) {
+			g.RuleIDs = append(g.RuleIDs, f.RuleID)
+		}
+		g.Locations = append(g.Locations, SecretLocation{
+			File:        f.File,
+			StartLine:   f.StartLine,
+			Commit:      f.Commit,
+			Author:      f.Author,
+			Date:        f.Date,
+			Repository:  f.Repository,
+			Fingerprint: f.Fingerprint,
+		})
+		g.Count++
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Count > groups[j].Count
+	})
+	return groups
+}
+
+func writeGrouped(findings []Finding, w io.WriteCloser) error {
+	groups := GroupBySecret(findings)
+	if groups == nil {
+		groups = []SecretGroup{}
+	}
+	defer w.Close()
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", " ")
+	return encoder.Encode(groups)
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package report
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 )
 
@@ -15,3 +16,39 @@ func writeJson(findings []Finding, w io.WriteCloser) error {
 	encoder.SetIndent("", " ")
 	return encoder.Encode(findings)
 }
+
+// jsonEnvelope is the json-envelope report, the findings of the json report
+// along with the manifest of the scan.
+type jsonEnvelope struct {
+	Manifest Manifest
+	Findings []Finding
+}
+
+// ReadJSON returns the findings of a json or json-envelope report.
+func ReadJSON(content []byte) ([]Finding, error) {
+	var findings []Finding
+	if err := json.Unmarshal(content, &findings); err == nil {
+		return findings, nil
+	}
+	var envelope struct {
+		Findings *[]Finding
+	}
+	if err := json.Unmarshal(content, &envelope); err != nil {
+		return nil, err
+	}
+	if envelope.Findings == nil {
+		return nil, errors.New("not a json or json-envelope report")
+	}
+	return *envelope.Findings, nil
+}
+
+func writeJsonEnvelope(findings []Finding, manifest Manifest, w io.WriteCloser) error {
+	if len(findings) == 0 {
+		findings = []Finding{}
+	}
+	defer w.Close()
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", " ")
+	return encoder.Encode(jsonEnvelope{Manifest: manifest, Findings: findings})
+}
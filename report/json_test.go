@@ -1,12 +1,15 @@
 package report
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/zricethezav/gitleaks/v8/config"
 )
 
 func TestWriteJSON(t *testing.T) {
@@ -66,3 +69,43 @@ func TestWriteJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteJSONEnvelope(t *testing.T) {
+	manifest := Manifest{Version: "v8.18.0", ConfigHash: "abc123", Targets: []Target{{Name: "repo", Commit: "0a1b2c"}}}
+	path := filepath.Join(t.TempDir(), "report.json")
+	findings := []Finding{{RuleID: "test-rule", File: "auth.py", Secret: "a secret"}}
+	require.NoError(t, Write(findings, config.Config{}, "json-envelope", path, manifest))
+	assert.Empty(t, ManifestPath(path, "json-envelope"))
+	assert.NoFileExists(t, path+".manifest.json")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var got jsonEnvelope
+	require.NoError(t, json.Unmarshal(content, &got))
+	assert.Equal(t, manifest, got.Manifest)
+	assert.Equal(t, findings, got.Findings)
+	read, err := ReadJSON(content)
+	require.NoError(t, err)
+	assert.Equal(t, findings, read)
+
+	// no findings are an empty list like in the json report
+	require.NoError(t, Write(nil, config.Config{}, "json-envelope", path, manifest))
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"Findings": []`)
+}
+
+func TestReadJSON(t *testing.T) {
+	findings, err := ReadJSON([]byte(`[{"RuleID": "test-rule"}]`))
+	require.NoError(t, err)
+	assert.Equal(t, []Finding{{RuleID: "test-rule"}}, findings)
+
+	findings, err = ReadJSON([]byte(`{"Manifest": {"Version": "v8.18.0"}, "Findings": []}`))
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+
+	_, err = ReadJSON([]byte(`{"version": "2.1.0", "runs": []}`))
+	assert.Error(t, err)
+	_, err = ReadJSON([]byte(`RuleID,File`))
+	assert.Error(t, err)
+}
@@ -8,10 +8,15 @@ import (
 	"strconv"
 )
 
-func writeJunit(findings []Finding, w io.WriteCloser) error {
+func writeJunit(findings []Finding, manifest Manifest, w io.WriteCloser) error {
 	testSuites := TestSuites{
 		TestSuites: getTestSuites(findings),
 	}
+	if !manifest.IsZero() {
+		testSuites.TestSuites[0].Properties = getProperties(manifest)
+		testSuites.TestSuites[0].Timestamp = manifest.StartTime.UTC().Format("2006-01-02T15:04:05")
+		testSuites.TestSuites[0].Time = strconv.FormatFloat(manifest.EndTime.Sub(manifest.StartTime).Seconds(), 'f', 3, 64)
+	}
 
 	io.WriteString(w, xml.Header)
 	encoder := xml.NewEncoder(w)
@@ -31,6 +36,17 @@ func getTestSuites(findings []Finding) []TestSuite {
 	}
 }
 
+func getProperties(m Manifest) *JunitProperties {
+	return &JunitProperties{
+		Properties: []Property{
+			{Name: "gitleaks.version", Value: m.Version},
+			{Name: "gitleaks.commit", Value: m.Commit},
+			{Name: "gitleaks.configHash", Value: m.ConfigHash},
+			{Name: "gitleaks.commandLine", Value: m.CommandLine},
+		},
+	}
+}
+
 func getTestCases(findings []Finding) []TestCase {
 	testCases := []TestCase{}
 	for _, f := range findings {
@@ -77,12 +93,23 @@ type TestSuites struct {
 }
 
 type TestSuite struct {
-	XMLName   xml.Name   `xml:"testsuite"`
-	Failures  string     `xml:"failures,attr"`
-	Name      string     `xml:"name,attr"`
-	Tests     string     `xml:"tests,attr"`
-	TestCases []TestCase `xml:"testcase"`
-	Time      string     `xml:"time,attr"`
+	XMLName    xml.Name         `xml:"testsuite"`
+	Failures   string           `xml:"failures,attr"`
+	Name       string           `xml:"name,attr"`
+	Tests      string           `xml:"tests,attr"`
+	Timestamp  string           `xml:"timestamp,attr,omitempty"`
+	Properties *JunitProperties `xml:"properties,omitempty"`
+	TestCases  []TestCase       `xml:"testcase"`
+	Time       string           `xml:"time,attr"`
+}
+
+type JunitProperties struct {
+	Properties []Property `xml:"property"`
+}
+
+type Property struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
 }
 
 type TestCase struct {
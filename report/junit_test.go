@@ -68,7 +68,7 @@ func TestWriteJunit(t *testing.T) {
 	for _, test := range tests {
 		tmpfile, err := os.Create(filepath.Join(t.TempDir(), test.testReportName+".xml"))
 		require.NoError(t, err)
-		err = writeJunit(test.findings, tmpfile)
+		err = writeJunit(test.findings, Manifest{}, tmpfile)
 		require.NoError(t, err)
 		assert.FileExists(t, tmpfile.Name())
 		got, err := os.ReadFile(tmpfile.Name())
@@ -0,0 +1,81 @@
+package report
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// webURL converts a git remote such as git@github.com:org/repo.git or
+// ssh://git@gitlab.com/org/repo.git into the https URL of the repository.
+func webURL(remote string) (*url.URL, bool) {
+	remote = strings.TrimSuffix(strings.TrimSpace(remote), "/")
+	remote = strings.TrimSuffix(remote, ".git")
+	if remote == "" {
+		return nil, false
+	}
+	// scp-like syntax, user@host:path
+	if !strings.Contains(remote, "://") {
+		at := strings.Index(remote, "@")
+		colon := strings.Index(remote, ":")
+		if colon == -1 || colon < at {
+			return nil, false
+		}
+		remote = "https://" + remote[at+1:colon] + "/" + strings.TrimPrefix(remote[colon+1:], "/")
+	}
+	u, err := url.Parse(remote)
+	if err != nil || u.Host == "" {
+		return nil, false
+	}
+	u.Scheme = "https"
+	u.User = nil
+	// ssh remotes may include a port that the web interface doesn't use
+	u.Host = u.Hostname()
+	return u, true
+}
+
+// Permalink returns a URL to the lines of the file at the commit on GitHub,
+// GitLab or Bitbucket. An empty string is returned for other hosts.
+func Permalink(remote string, commit string, file string, startLine int, endLine int) string {
+	if commit == "" || file == "" {
+		return ""
+	}
+	u, ok := webURL(remote)
+	if !ok {
+		return ""
+	}
+	file = strings.TrimPrefix(strings.ReplaceAll(file, "\\", "/"), "/")
+	// escape each segment, file names may contain spaces, # or ?
+	segments := strings.Split(file, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	file = strings.Join(segments, "/")
+	if endLine < startLine {
+		endLine = startLine
+	}
+	base := strings.TrimSuffix(u.String(), "/")
+	host := strings.ToLower(u.Host)
+
+	switch {
+	case strings.Contains(host, "github"):
+		link := fmt.Sprintf("%s/blob/%s/%s#L%d", base, commit, file, startLine)
+		if endLine != startLine {
+			link += fmt.Sprintf("-L%d", endLine)
+		}
+		return link
+	case strings.Contains(host, "gitlab"):
+		link := fmt.Sprintf("%s/-/blob/%s/%s#L%d", base, commit, file, startLine)
+		if endLine != startLine {
+			link += fmt.Sprintf("-%d", endLine)
+		}
+		return link
+	case strings.Contains(host, "bitbucket"):
+		link := fmt.Sprintf("%s/src/%s/%s#lines-%d", base, commit, file, startLine)
+		if endLine != startLine {
+			link += fmt.Sprintf(":%d", endLine)
+		}
+		return link
+	}
+	return ""
+}
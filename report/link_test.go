@@ -0,0 +1,56 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermalink(t *testing.T) {
+	tests := []struct {
+		remote    string
+		startLine int
+		endLine   int
+		want      string
+	}{
+		{
+			remote:    "https://github.com/org/repo.git",
+			startLine: 3, endLine: 3,
+			want: "https://github.com/org/repo/blob/abc123/dir/file.go#L3",
+		},
+		{
+			remote:    "git@github.com:org/repo.git",
+			startLine: 3, endLine: 5,
+			want: "https://github.com/org/repo/blob/abc123/dir/file.go#L3-L5",
+		},
+		{
+			remote:    "ssh://git@gitlab.example.com:2222/group/sub/repo.git",
+			startLine: 3, endLine: 5,
+			want: "https://gitlab.example.com/group/sub/repo/-/blob/abc123/dir/file.go#L3-5",
+		},
+		{
+			remote:    "https://bitbucket.org/team/repo",
+			startLine: 7, endLine: 7,
+			want: "https://bitbucket.org/team/repo/src/abc123/dir/file.go#lines-7",
+		},
+		{
+			remote:    "https://git.example.com/org/repo.git",
+			startLine: 1, endLine: 1,
+			want: "",
+		},
+		{
+			remote: "",
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Permalink(tt.remote, "abc123", "dir/file.go", tt.startLine, tt.endLine), tt.remote)
+	}
+}
+
+func TestPermalinkEscapesPath(t *testing.T) {
+	assert.Equal(t, "https://github.com/org/repo/blob/abc123/my%20dir/a%23b%3F.go#L1",
+		Permalink("git@github.com:org/repo.git", "abc123", "my dir/a#b?.go", 1, 1))
+	assert.Equal(t, "https://gitlab.com/org/repo/-/blob/abc123/docs/100%25.md#L2-3",
+		Permalink("https://gitlab.com/org/repo", "abc123", `docs\100%.md`, 2, 3))
+}
@@ -0,0 +1,77 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+)
+
+// Manifest describes the scan that produced a report so the results are
+// reproducible and auditable.
+type Manifest struct {
+	// Version and Commit identify the build of gitleaks that ran the scan.
+	Version string
+	Commit  string `json:",omitempty"`
+
+	// ConfigHash is the hash of the effective config used for the scan.
+	ConfigHash string
+
+	CommandLine string
+	StartTime   time.Time
+	EndTime     time.Time
+
+	// Targets are what was scanned, with the commit repositories were at.
+	Targets []Target `json:",omitempty"`
+
+	// Failures are the repositories of a multi-repository scan that couldn't
+	// be scanned, the findings are missing theirs.
+	Failures []ScanFailure `json:",omitempty"`
+}
+
+// Target is a repository, directory or stream that was scanned.
+type Target struct {
+	Name string
+	// Commit is the commit HEAD of a repository pointed to when it was
+	// scanned.
+	Commit string `json:",omitempty"`
+}
+
+// ScanFailure is a repository that couldn't be scanned.
+type ScanFailure struct {
+	Repository string
+	// Stage is "clone" if the repository couldn't be cloned or fetched and
+	// "scan" if it couldn't be scanned.
+	Stage string
+	Error string
+	// Attempts is how often cloning was attempted.
+	Attempts int `json:",omitempty"`
+}
+
+// IsZero returns true if the manifest has not been filled in.
+func (m Manifest) IsZero() bool {
+	return m.Version == "" && m.Commit == "" && m.ConfigHash == "" && m.CommandLine == "" &&
+		m.StartTime.IsZero() && m.EndTime.IsZero() && len(m.Targets) == 0 && len(m.Failures) == 0
+}
+
+// ManifestPath returns the path Write writes the manifest of a report in the
+// format given by ext to, or an empty string if the format embeds it.
+func ManifestPath(reportPath string, ext string) string {
+	switch strings.ToLower(ext) {
+	case ".xml", "junit", ".sarif", "sarif", "json-envelope":
+		return ""
+	}
+	return reportPath + ".manifest.json"
+}
+
+// writeManifest writes the manifest as JSON to the path.
+func writeManifest(m Manifest, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", " ")
+	return encoder.Encode(m)
+}
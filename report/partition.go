@@ -0,0 +1,107 @@
+package report
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RootComponent is the component of files at the top of the scanned source.
+const RootComponent = "(root)"
+
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// UnownedComponent is the component of files without CODEOWNERS owners.
+const UnownedComponent = "(unowned)"
+
+// RelativePath returns the slash separated path of file relative to source.
+// Files from git scans are already relative to the repository root.
+func RelativePath(file string, source string) string {
+	file = filepath.ToSlash(file)
+	if source != "" {
+		if rel, err := filepath.Rel(source, filepath.FromSlash(file)); err == nil && !strings.HasPrefix(rel, "..") {
+			file = filepath.ToSlash(rel)
+		}
+	}
+	return strings.TrimPrefix(file, "./")
+}
+
+// TopLevelDirectory returns the first directory of file relative to source,
+// which monorepos commonly use to separate components.
+func TopLevelDirectory(file string, source string) string {
+	file = RelativePath(file, source)
+	i := strings.Index(file, "/")
+	if i <= 0 {
+		return RootComponent
+	}
+	return file[:i]
+}
+
+// OwnerComponent returns the owners of the finding as a component name.
+func OwnerComponent(f Finding) string {
+	if len(f.Owners) == 0 {
+		return UnownedComponent
+	}
+	return strings.Join(f.Owners, " ")
+}
+
+// Partition groups findings by the component returned by key.
+func Partition(findings []Finding, key func(Finding) string) map[string][]Finding {
+	partitions := make(map[string][]Finding)
+	for _, f := range findings {
+		k := key(f)
+		partitions[k] = append(partitions[k], f)
+	}
+	return partitions
+}
+
+// Components returns the sorted component names of the partitions.
+func Components(partitions map[string][]Finding) []string {
+	var components []string
+	for c := range partitions {
+		components = append(components, c)
+	}
+	sort.Strings(components)
+	return components
+}
+
+// PartitionPath returns the report path for a component by inserting the
+// component name before the extension, e.g. report.json becomes
+// report.infra.json. Unsafe characters are replaced with underscores and
+// trimmed from the ends, so the _root and _unowned names of RootComponent
+// and UnownedComponent don't collide with directories or owners.
+func PartitionPath(reportPath string, component string) string {
+	var name string
+	switch component {
+	case RootComponent:
+		name = "_root"
+	case UnownedComponent:
+		name = "_unowned"
+	default:
+		name = strings.Trim(unsafeFilenameChars.ReplaceAllString(component, "_"), "_")
+		if name == "" {
+			name = "_"
+		}
+	}
+	ext := filepath.Ext(reportPath)
+	return strings.TrimSuffix(reportPath, ext) + "." + name + ext
+}
+
+// PartitionPaths returns the report path of each component. Components whose
+// names only differ in unsafe characters, like "@org/team" and "org_team",
+// would overwrite each other's report and are an error.
+func PartitionPaths(reportPath string, components []string) (map[string]string, error) {
+	paths := make(map[string]string, len(components))
+	seen := make(map[string]string, len(components))
+	for _, component := range components {
+		path := PartitionPath(reportPath, component)
+		if other, ok := seen[path]; ok {
+			return nil, fmt.Errorf("components %q and %q would both be written to %s", other, component, path)
+		}
+		seen[path] = component
+		paths[component] = path
+	}
+	return paths, nil
+}
@@ -0,0 +1,56 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopLevelDirectory(t *testing.T) {
+	assert.Equal(t, "infra", TopLevelDirectory("infra/aws/main.tf", "."))
+	assert.Equal(t, "infra", TopLevelDirectory("repo/infra/aws/main.tf", "repo"))
+	assert.Equal(t, "charts", TopLevelDirectory("./charts/values.yaml", ""))
+	assert.Equal(t, RootComponent, TopLevelDirectory("main.go", "."))
+	assert.Equal(t, RootComponent, TopLevelDirectory("repo/main.go", "repo"))
+}
+
+func TestPartition(t *testing.T) {
+	findings := []Finding{
+		{File: "infra/main.tf", RuleID: "a"},
+		{File: "charts/values.yaml", RuleID: "b"},
+		{File: "infra/vars.tf", RuleID: "c"},
+		{File: "README.md", RuleID: "d"},
+	}
+	partitions := Partition(findings, func(f Finding) string {
+		return TopLevelDirectory(f.File, ".")
+	})
+	assert.Equal(t, []string{RootComponent, "charts", "infra"}, Components(partitions))
+	assert.Len(t, partitions["infra"], 2)
+	assert.Len(t, partitions["charts"], 1)
+	assert.Len(t, partitions[RootComponent], 1)
+}
+
+func TestPartitionPath(t *testing.T) {
+	assert.Equal(t, "out/report.infra.json", PartitionPath("out/report.json", "infra"))
+	assert.Equal(t, "report._root.sarif", PartitionPath("report.sarif", RootComponent))
+	assert.Equal(t, "report._unowned.json", PartitionPath("report.json", UnownedComponent))
+	assert.Equal(t, "report.root.json", PartitionPath("report.json", "root"))
+	assert.Equal(t, "report.org_team", PartitionPath("report", "@org/team"))
+	assert.Equal(t, "report._.json", PartitionPath("report.json", "@@"))
+}
+
+func TestPartitionPaths(t *testing.T) {
+	paths, err := PartitionPaths("report.json", []string{RootComponent, "root", UnownedComponent, "unowned"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		RootComponent:    "report._root.json",
+		"root":           "report.root.json",
+		UnownedComponent: "report._unowned.json",
+		"unowned":        "report.unowned.json",
+	}, paths)
+
+	_, err = PartitionPaths("report.json", []string{"@org/team", "org_team"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `components "@org/team" and "org_team" would both be written to report.org_team.json`)
+}
@@ -0,0 +1,124 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const remediationPlaceholder = "***REMOVED***"
+
+// WriteRemediation writes a remediation plan for the findings. The plan
+// contains a patch removing each secret that is still present in the working
+// tree, followed by the commands needed to purge every secret from history
+// using either git filter-repo or BFG. Findings from git history are only
+// patched when AnalyzeHistory found them in their file at HEAD.
+func WriteRemediation(findings []Finding, w io.WriteCloser) error {
+	defer w.Close()
+
+	var sb strings.Builder
+	sb.WriteString("# Gitleaks remediation plan\n\n")
+	sb.WriteString("Rotate every secret listed below before rewriting history. Removing a secret\n")
+	sb.WriteString("from a repository does not revoke it.\n\n")
+
+	sb.WriteString("## 1. Remove secrets from the current tree\n\n")
+	patches := remediationPatches(findings)
+	if len(patches) == 0 {
+		sb.WriteString("No secrets are present in the current tree.\n\n")
+	}
+	for _, p := range patches {
+		sb.WriteString("```diff\n")
+		sb.WriteString(p)
+		sb.WriteString("```\n\n")
+	}
+
+	sb.WriteString("## 2. Purge secrets from history\n\n")
+	sb.WriteString("Save the following to `replacements.txt`:\n\n```\n")
+	redacted := false
+	for _, g := range GroupBySecret(findings) {
+		if redactedSecret(g.Secret) {
+			redacted = true
+			fmt.Fprintf(&sb, "# %s: secret redacted in report (%s)\n", strings.Join(g.RuleIDs, ","), g.Locations[0].Fingerprint)
+			continue
+		}
+		fmt.Fprintf(&sb, "%s==>%s\n", g.Secret, remediationPlaceholder)
+	}
+	sb.WriteString("```\n\n")
+	if redacted {
+		sb.WriteString("Some secrets were redacted, re-run gitleaks without `--redact` or `--hmac-key-file` or fill them in manually.\n\n")
+	}
+	sb.WriteString("Using [git filter-repo](https://github.com/newren/git-filter-repo):\n\n```sh\n")
+	sb.WriteString("git filter-repo --replace-text replacements.txt\n")
+	sb.WriteString("git push --force --all\ngit push --force --tags\n```\n\n")
+	sb.WriteString("Or using [BFG](https://rtyley.github.io/bfg-repo-cleaner/):\n\n```sh\n")
+	sb.WriteString("bfg --replace-text replacements.txt\n")
+	sb.WriteString("git reflog expire --expire=now --all && git gc --prune=now --aggressive\n")
+	sb.WriteString("git push --force --all\ngit push --force --tags\n```\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// remediationPatches returns a unified diff for every line containing a secret
+// that is still present in the working tree. Findings without a commit were
+// found in the working tree, those of a commit are patched at the line
+// AnalyzeHistory found them on at HEAD.
+func remediationPatches(findings []Finding) []string {
+	type lineKey struct {
+		file string
+		line int
+	}
+	type patchedLine struct {
+		content string
+		secrets []string
+	}
+	patched := make(map[lineKey]patchedLine)
+	for _, f := range findings {
+		// the line of a redacted secret isn't the line in the file
+		if f.File == "" || strings.HasPrefix(f.Match, "file detected") || redactedSecret(f.Secret) {
+			continue
+		}
+		key, content := lineKey{f.File, f.StartLine}, f.Line
+		if f.Commit != "" {
+			key.line, content = f.HeadLine, f.HeadLineContent
+		}
+		if key.line == 0 || content == "" {
+			continue
+		}
+		p := patched[key]
+		p.content = content
+		p.secrets = append(p.secrets, f.Secret)
+		patched[key] = p
+	}
+
+	var keys []lineKey
+	for k := range patched {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].file != keys[j].file {
+			return keys[i].file < keys[j].file
+		}
+		return keys[i].line < keys[j].line
+	})
+
+	var patches []string
+	for _, k := range keys {
+		p := patched[k]
+		line := strings.Trim(p.content, "\r\n")
+		fixed := line
+		for _, secret := range p.secrets {
+			fixed = strings.Replace(fixed, secret, remediationPlaceholder, -1)
+		}
+		patches = append(patches, fmt.Sprintf("--- a/%s\n+++ b/%s\n@@ -%d +%d @@\n-%s\n+%s\n",
+			k.file, k.file, k.line, k.line, line, fixed))
+	}
+	return patches
+}
+
+// redactedSecret returns true if secret was redacted by --redact or
+// pseudonymized by --hmac-key-file, it is not the secret in the repository.
+func redactedSecret(secret string) bool {
+	return secret == "REDACTED" || strings.HasSuffix(secret, "...") || strings.HasPrefix(secret, "hmac:")
+}
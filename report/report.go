@@ -13,7 +13,12 @@ const (
 	CWE_DESCRIPTION = "Use of Hard-coded Credentials"
 )
 
-func Write(findings []Finding, cfg config.Config, ext string, reportPath string) error {
+// Write writes the findings to reportPath in the format given by ext. The
+// manifest is embedded in formats that support metadata (sarif, junit and
+// json-envelope). Other formats are left untouched for compatibility and the
+// manifest is written next to the report as <reportPath>.manifest.json
+// instead.
+func Write(findings []Finding, cfg config.Config, ext string, reportPath string, manifest Manifest) error {
 	file, err := os.Create(reportPath)
 	if err != nil {
 		return err
@@ -22,13 +27,32 @@ func Write(findings []Finding, cfg config.Config, ext string, reportPath string)
 	switch ext {
 	case ".json", "json":
 		err = writeJson(findings, file)
+	case "json-envelope":
+		return writeJsonEnvelope(findings, manifest, file)
 	case ".csv", "csv":
 		err = writeCsv(findings, file)
 	case ".xml", "junit":
-		err = writeJunit(findings, file)
+		return writeJunit(findings, manifest, file)
 	case ".sarif", "sarif":
-		err = writeSarif(cfg, findings, file)
+		return writeSarif(cfg, findings, manifest, file)
+	case "grouped":
+		err = writeGrouped(findings, file)
+	case "summary":
+		err = writeSummary(findings, file)
+	case "ghas":
+		err = writeGHAS(findings, file)
+	case "defectdojo":
+		err = writeDefectDojo(findings, file)
+	case "threadfix":
+		err = writeThreadFix(findings, manifest, file)
+	case "ocsf":
+		err = writeEvents(findings, manifest, file, ocsfEvent)
+	case "ecs":
+		err = writeEvents(findings, manifest, file, ecsEvent)
+	}
+	if err != nil || manifest.IsZero() {
+		return err
 	}
 
-	return err
+	return writeManifest(manifest, ManifestPath(reportPath, ext))
 }
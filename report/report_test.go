@@ -101,7 +101,7 @@ func TestReport(t *testing.T) {
 		t.Run(test.ext, func(t *testing.T) {
 			tmpfile, err := os.Create(filepath.Join(t.TempDir(), strconv.Itoa(i)+test.ext))
 			require.NoError(t, err)
-			err = Write(test.findings, config.Config{}, test.ext, tmpfile.Name())
+			err = Write(test.findings, config.Config{}, test.ext, tmpfile.Name(), Manifest{})
 			require.NoError(t, err)
 			got, err := os.ReadFile(tmpfile.Name())
 			require.NoError(t, err)
@@ -0,0 +1,247 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// rollupTopRepositories is the number of repositories listed in the
+// Markdown rollup, the rest are only counted.
+const rollupTopRepositories = 20
+
+// UnknownRepository is the repository of findings from scans of files
+// outside of a repository.
+const UnknownRepository = "(unknown)"
+
+// rollupSeverities are the severity columns of the Markdown rollup.
+var rollupSeverities = []string{"Critical", "High", "Medium", "Low"}
+
+// Rollup summarizes the findings of a scan of many repositories per
+// repository and per team for security reports, optionally compared to the
+// findings of a previous scan.
+type Rollup struct {
+	Findings     int
+	Trend        *Trend `json:",omitempty"`
+	Repositories []RepositoryRollup
+	Teams        []TeamRollup
+	Rules        map[string]int
+}
+
+// RepositoryRollup counts the findings of a repository by rule and severity.
+type RepositoryRollup struct {
+	Repository string
+	Findings   int
+	Trend      *Trend `json:",omitempty"`
+	Rules      map[string]int
+	Severities map[string]int
+}
+
+// TeamRollup counts the findings owned by a team according to CODEOWNERS.
+type TeamRollup struct {
+	Team         string
+	Findings     int
+	Repositories []string
+}
+
+// Trend compares findings to a previous scan by fingerprint. New findings
+// weren't in the previous scan and resolved ones aren't in this one.
+type Trend struct {
+	Previous int
+	New      int
+	Resolved int
+}
+
+// NewRollup summarizes findings per repository, team and rule. Repositories
+// and teams are ordered by their number of findings, most first. If
+// previous is not nil, the findings are compared to it, repositories whose
+// findings were all resolved are kept with none.
+func NewRollup(findings []Finding, previous []Finding) Rollup {
+	rollup := Rollup{
+		Findings:     len(findings),
+		Repositories: []RepositoryRollup{},
+		Teams:        []TeamRollup{},
+		Rules:        make(map[string]int),
+	}
+	repositories := make(map[string]int)
+	repository := func(name string) *RepositoryRollup {
+		i, ok := repositories[name]
+		if !ok {
+			i = len(rollup.Repositories)
+			repositories[name] = i
+			rollup.Repositories = append(rollup.Repositories, RepositoryRollup{
+				Repository: name,
+				Rules:      make(map[string]int),
+				Severities: make(map[string]int),
+			})
+		}
+		return &rollup.Repositories[i]
+	}
+	teams := make(map[string]int)
+	for _, f := range findings {
+		r := repository(rollupRepository(f))
+		r.Findings++
+		r.Rules[f.RuleID]++
+		r.Severities[vulnerabilitySeverity(f)]++
+		rollup.Rules[f.RuleID]++
+
+		team := OwnerComponent(f)
+		i, ok := teams[team]
+		if !ok {
+			i = len(rollup.Teams)
+			teams[team] = i
+			rollup.Teams = append(rollup.Teams, TeamRollup{Team: team})
+		}
+		t := &rollup.Teams[i]
+		t.Findings++
+		if !contains(t.Repositories, r.Repository) {
+			t.Repositories = append(t.Repositories, r.Repository)
+		}
+	}
+
+	if previous != nil {
+		current := fingerprints(findings)
+		before := fingerprints(previous)
+		rollup.Trend = &Trend{Previous: len(previous)}
+		for _, r := range rollup.Repositories {
+			repository(r.Repository).Trend = &Trend{}
+		}
+		for _, f := range previous {
+			r := repository(rollupRepository(f))
+			if r.Trend == nil {
+				r.Trend = &Trend{}
+			}
+			r.Trend.Previous++
+			if !current[f.Fingerprint] {
+				r.Trend.Resolved++
+				rollup.Trend.Resolved++
+			}
+		}
+		for _, f := range findings {
+			if !before[f.Fingerprint] {
+				repository(rollupRepository(f)).Trend.New++
+				rollup.Trend.New++
+			}
+		}
+	}
+
+	sort.SliceStable(rollup.Repositories, func(i, j int) bool {
+		return rollup.Repositories[i].Findings > rollup.Repositories[j].Findings
+	})
+	sort.SliceStable(rollup.Teams, func(i, j int) bool {
+		return rollup.Teams[i].Findings > rollup.Teams[j].Findings
+	})
+	return rollup
+}
+
+func rollupRepository(f Finding) string {
+	if f.Repository == "" {
+		return UnknownRepository
+	}
+	return f.Repository
+}
+
+func fingerprints(findings []Finding) map[string]bool {
+	set := make(map[string]bool)
+	for _, f := range findings {
+		set[f.Fingerprint] = true
+	}
+	return set
+}
+
+// WriteJSON writes the rollup as JSON.
+func (r Rollup) WriteJSON(w io.WriteCloser) error {
+	defer w.Close()
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", " ")
+	return encoder.Encode(r)
+}
+
+// WriteMarkdown writes the rollup as Markdown tables that can be pasted into
+// a security report.
+func (r Rollup) WriteMarkdown(w io.WriteCloser) error {
+	defer w.Close()
+
+	var sb strings.Builder
+	sb.WriteString("# Secret scanning rollup\n\n")
+	repositories := 0
+	for _, repo := range r.Repositories {
+		if repo.Findings > 0 {
+			repositories++
+		}
+	}
+	fmt.Fprintf(&sb, "%d findings in %d repositories", r.Findings, repositories)
+	if r.Trend != nil {
+		fmt.Fprintf(&sb, ", %d new and %d resolved since the previous scan (%s)",
+			r.Trend.New, r.Trend.Resolved, signed(r.Findings-r.Trend.Previous))
+	}
+	sb.WriteString(".\n\n")
+
+	sb.WriteString("## Top repositories\n\n")
+	sb.WriteString("| Repository | Findings |")
+	for _, severity := range rollupSeverities {
+		fmt.Fprintf(&sb, " %s |", severity)
+	}
+	if r.Trend != nil {
+		sb.WriteString(" New | Resolved |")
+	}
+	sb.WriteString("\n|---|---:|")
+	for range rollupSeverities {
+		sb.WriteString("---:|")
+	}
+	if r.Trend != nil {
+		sb.WriteString("---:|---:|")
+	}
+	sb.WriteString("\n")
+	for i, repo := range r.Repositories {
+		if i == rollupTopRepositories {
+			fmt.Fprintf(&sb, "\nand %d more repositories.\n", len(r.Repositories)-rollupTopRepositories)
+			break
+		}
+		fmt.Fprintf(&sb, "| %s | %d |", markdownCell(repo.Repository), repo.Findings)
+		for _, severity := range rollupSeverities {
+			fmt.Fprintf(&sb, " %d |", repo.Severities[severity])
+		}
+		if repo.Trend != nil {
+			fmt.Fprintf(&sb, " %d | %d |", repo.Trend.New, repo.Trend.Resolved)
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n## Teams\n\n| Team | Findings | Repositories |\n|---|---:|---:|\n")
+	for _, t := range r.Teams {
+		fmt.Fprintf(&sb, "| %s | %d | %d |\n", markdownCell(t.Team), t.Findings, len(t.Repositories))
+	}
+
+	sb.WriteString("\n## Rules\n\n| Rule | Findings |\n|---|---:|\n")
+	var rules []string
+	for rule := range r.Rules {
+		rules = append(rules, rule)
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		if r.Rules[rules[i]] != r.Rules[rules[j]] {
+			return r.Rules[rules[i]] > r.Rules[rules[j]]
+		}
+		return rules[i] < rules[j]
+	})
+	for _, rule := range rules {
+		fmt.Fprintf(&sb, "| %s | %d |\n", markdownCell(rule), r.Rules[rule])
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func signed(n int) string {
+	if n > 0 {
+		return fmt.Sprintf("+%d", n)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// markdownCell escapes the pipes that would end a table cell.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,67 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollup(t *testing.T) {
+	findings := []Finding{
+		{Repository: "api", RuleID: "aws-access-key", Severity: "critical", Fingerprint: "1", Owners: []string{"@acme/platform"}},
+		{Repository: "api", RuleID: "generic-api-key", Fingerprint: "2", Owners: []string{"@acme/platform"}},
+		{Repository: "web", RuleID: "generic-api-key", Severity: "low", Fingerprint: "3", Owners: []string{"@acme/platform"}},
+		{Repository: "api", RuleID: "generic-api-key", Fingerprint: "4"},
+	}
+	previous := []Finding{
+		{Repository: "api", RuleID: "aws-access-key", Fingerprint: "1"},
+		{Repository: "legacy", RuleID: "aws-access-key", Fingerprint: "5"},
+	}
+
+	rollup := NewRollup(findings, previous)
+	assert.Equal(t, 4, rollup.Findings)
+	assert.Equal(t, &Trend{Previous: 2, New: 3, Resolved: 1}, rollup.Trend)
+	assert.Equal(t, map[string]int{"aws-access-key": 1, "generic-api-key": 3}, rollup.Rules)
+	assert.Equal(t, []RepositoryRollup{
+		{
+			Repository: "api",
+			Findings:   3,
+			Trend:      &Trend{Previous: 1, New: 2},
+			Rules:      map[string]int{"aws-access-key": 1, "generic-api-key": 2},
+			Severities: map[string]int{"Critical": 1, "High": 2},
+		},
+		{
+			Repository: "web",
+			Findings:   1,
+			Trend:      &Trend{New: 1},
+			Rules:      map[string]int{"generic-api-key": 1},
+			Severities: map[string]int{"Low": 1},
+		},
+		{
+			Repository: "legacy",
+			Trend:      &Trend{Previous: 1, Resolved: 1},
+			Rules:      map[string]int{},
+			Severities: map[string]int{},
+		},
+	}, rollup.Repositories)
+	assert.Equal(t, []TeamRollup{
+		{Team: "@acme/platform", Findings: 3, Repositories: []string{"api", "web"}},
+		{Team: UnownedComponent, Findings: 1, Repositories: []string{"api"}},
+	}, rollup.Teams)
+
+	path := filepath.Join(t.TempDir(), "rollup.md")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, rollup.WriteMarkdown(file))
+	markdown, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(markdown), "4 findings in 2 repositories, 3 new and 1 resolved since the previous scan (+2).\n")
+	assert.Contains(t, string(markdown), "| api | 3 | 1 | 2 | 0 | 0 | 2 | 0 |\n")
+	assert.Contains(t, string(markdown), "| legacy | 0 | 0 | 0 | 0 | 0 | 0 | 1 |\n")
+	assert.Contains(t, string(markdown), "| generic-api-key | 3 |\n| aws-access-key | 1 |\n")
+
+	assert.Nil(t, NewRollup(findings, nil).Trend)
+}
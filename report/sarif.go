@@ -4,16 +4,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/zricethezav/gitleaks/v8/config"
 )
 
-func writeSarif(cfg config.Config, findings []Finding, w io.WriteCloser) error {
+func writeSarif(cfg config.Config, findings []Finding, manifest Manifest, w io.WriteCloser) error {
 	sarif := Sarif{
 		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
 		Version: "2.1.0",
 		Runs:    getRuns(cfg, findings),
 	}
+	if !manifest.IsZero() {
+		addManifest(&sarif.Runs[0], manifest)
+	}
 	defer w.Close()
 
 	encoder := json.NewEncoder(w)
@@ -30,6 +35,30 @@ func getRuns(cfg config.Config, findings []Finding) []Runs {
 	}
 }
 
+// addManifest records the manifest as the invocation of the run.
+func addManifest(run *Runs, m Manifest) {
+	if m.Version != "" {
+		run.Tool.Driver.SemanticVersion = m.Version
+	}
+	invocation := Invocation{
+		CommandLine:         m.CommandLine,
+		StartTimeUtc:        m.StartTime.UTC().Format(time.RFC3339),
+		EndTimeUtc:          m.EndTime.UTC().Format(time.RFC3339),
+		ExecutionSuccessful: len(m.Failures) == 0,
+	}
+	for _, failure := range m.Failures {
+		invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, ToolNotification{
+			Level:   "error",
+			Message: Message{Text: fmt.Sprintf("%s: %s failed: %s", failure.Repository, failure.Stage, failure.Error)},
+		})
+	}
+	run.Invocations = []Invocation{invocation}
+	run.Properties = &RunProperties{
+		Commit:     m.Commit,
+		ConfigHash: m.ConfigHash,
+	}
+}
+
 func getTool(cfg config.Config) Tool {
 	tool := Tool{
 		Driver: Driver{
@@ -94,6 +123,7 @@ func getResults(findings []Finding) []Results {
 				Text: messageText(f),
 			},
 			RuleId:    f.RuleID,
+			Level:     sarifLevel(f.Severity),
 			Locations: getLocation(f),
 			// This information goes in partial fingerprings until revision
 			// data can be added somewhere else
@@ -113,6 +143,19 @@ func getResults(findings []Finding) []Results {
 	return results
 }
 
+// sarifLevel maps the severity of a finding to a sarif result level.
+func sarifLevel(severity string) string {
+	switch severity {
+	case LevelCritical, LevelHigh:
+		return "error"
+	case LevelMedium:
+		return "warning"
+	case LevelLow:
+		return "note"
+	}
+	return ""
+}
+
 func getLocation(f Finding) []Locations {
 	uri := f.File
 	if f.SymlinkFile != "" {
@@ -124,6 +167,7 @@ func getLocation(f Finding) []Locations {
 				ArtifactLocation: ArtifactLocation{
 					URI: uri,
 				},
+				ContextRegion: getContextRegion(f),
 				Region: Region{
 					StartLine:   f.StartLine,
 					EndLine:     f.EndLine,
@@ -138,6 +182,24 @@ func getLocation(f Finding) []Locations {
 	}
 }
 
+// getContextRegion returns the lines surrounding the finding, or nil if the
+// finding has no context.
+func getContextRegion(f Finding) *ContextRegion {
+	if len(f.ContextBefore) == 0 && len(f.ContextAfter) == 0 {
+		return nil
+	}
+	lines := append([]string{}, f.ContextBefore...)
+	lines = append(lines, strings.Trim(f.Line, "\r\n"))
+	lines = append(lines, f.ContextAfter...)
+	return &ContextRegion{
+		StartLine: f.StartLine - len(f.ContextBefore),
+		EndLine:   f.EndLine + len(f.ContextAfter),
+		Snippet: Snippet{
+			Text: strings.Join(lines, "\n"),
+		},
+	}
+}
+
 type PartialFingerPrints struct {
 	CommitSha     string `json:"commitSha"`
 	Email         string `json:"email"`
@@ -200,6 +262,13 @@ type Snippet struct {
 type PhysicalLocation struct {
 	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
 	Region           Region           `json:"region"`
+	ContextRegion    *ContextRegion   `json:"contextRegion,omitempty"`
+}
+
+type ContextRegion struct {
+	StartLine int     `json:"startLine"`
+	EndLine   int     `json:"endLine"`
+	Snippet   Snippet `json:"snippet"`
 }
 
 type Locations struct {
@@ -213,12 +282,36 @@ type Properties struct {
 type Results struct {
 	Message             Message     `json:"message"`
 	RuleId              string      `json:"ruleId"`
+	Level               string      `json:"level,omitempty"`
 	Locations           []Locations `json:"locations"`
 	PartialFingerPrints `json:"partialFingerprints"`
 	Properties          Properties `json:"properties"`
 }
 
 type Runs struct {
-	Tool    Tool      `json:"tool"`
-	Results []Results `json:"results"`
+	Tool        Tool           `json:"tool"`
+	Invocations []Invocation   `json:"invocations,omitempty"`
+	Results     []Results      `json:"results"`
+	Properties  *RunProperties `json:"properties,omitempty"`
+}
+
+type Invocation struct {
+	CommandLine         string `json:"commandLine"`
+	StartTimeUtc        string `json:"startTimeUtc"`
+	EndTimeUtc          string `json:"endTimeUtc"`
+	ExecutionSuccessful bool   `json:"executionSuccessful"`
+
+	ToolExecutionNotifications []ToolNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+// ToolNotification reports a problem running the scan, such as a repository
+// that couldn't be scanned.
+type ToolNotification struct {
+	Level   string  `json:"level"`
+	Message Message `json:"message"`
+}
+
+type RunProperties struct {
+	Commit     string `json:"gitleaksCommit,omitempty"`
+	ConfigHash string `json:"configHash"`
 }
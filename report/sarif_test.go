@@ -65,7 +65,7 @@ func TestWriteSarif(t *testing.T) {
 
 			cfg, err := vc.Translate()
 			require.NoError(t, err)
-			err = writeSarif(cfg, test.findings, tmpfile)
+			err = writeSarif(cfg, test.findings, Manifest{}, tmpfile)
 			require.NoError(t, err)
 			assert.FileExists(t, tmpfile.Name())
 			got, err := os.ReadFile(tmpfile.Name())
@@ -80,3 +80,33 @@ func TestWriteSarif(t *testing.T) {
 		})
 	}
 }
+
+func TestGetContextRegion(t *testing.T) {
+	assert.Nil(t, getContextRegion(Finding{StartLine: 3, EndLine: 3}))
+
+	region := getContextRegion(Finding{
+		StartLine:     3,
+		EndLine:       3,
+		Line:          "\nsecret=abc",
+		ContextBefore: []string{"one", "two"},
+		ContextAfter:  []string{"four"},
+	})
+	require.NotNil(t, region)
+	assert.Equal(t, 1, region.StartLine)
+	assert.Equal(t, 4, region.EndLine)
+	assert.Equal(t, "one\ntwo\nsecret=abc\nfour", region.Snippet.Text)
+}
+
+func TestAddManifestFailures(t *testing.T) {
+	var run Runs
+	addManifest(&run, Manifest{Version: "v8.18.0"})
+	require.Len(t, run.Invocations, 1)
+	assert.True(t, run.Invocations[0].ExecutionSuccessful)
+	assert.Empty(t, run.Invocations[0].ToolExecutionNotifications)
+
+	addManifest(&run, Manifest{Failures: []ScanFailure{{Repository: "https://example.com/a.git", Stage: "clone", Error: "repository not found"}}})
+	assert.False(t, run.Invocations[0].ExecutionSuccessful)
+	require.Len(t, run.Invocations[0].ToolExecutionNotifications, 1)
+	assert.Equal(t, "error", run.Invocations[0].ToolExecutionNotifications[0].Level)
+	assert.Equal(t, "https://example.com/a.git: clone failed: repository not found", run.Invocations[0].ToolExecutionNotifications[0].Message.Text)
+}
@@ -0,0 +1,37 @@
+package report
+
+import "strings"
+
+// Levels used to describe both the severity and the confidence of a finding,
+// ordered from lowest to highest.
+const (
+	LevelLow      = "low"
+	LevelMedium   = "medium"
+	LevelHigh     = "high"
+	LevelCritical = "critical"
+)
+
+var levelRanks = map[string]int{
+	LevelLow:      1,
+	LevelMedium:   2,
+	LevelHigh:     3,
+	LevelCritical: 4,
+}
+
+// LevelRank returns the rank of a level so levels can be compared. Unknown
+// levels have a rank of 0.
+func LevelRank(level string) int {
+	return levelRanks[strings.ToLower(level)]
+}
+
+// ValidLevel returns true if level is one of the known levels.
+func ValidLevel(level string) bool {
+	return LevelRank(level) > 0
+}
+
+// ValidConfidence returns true if level is a confidence a finding can have.
+// Findings are never classified with critical confidence.
+func ValidConfidence(level string) bool {
+	rank := LevelRank(level)
+	return rank > 0 && rank < levelRanks[LevelCritical]
+}
@@ -0,0 +1,18 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidLevel(t *testing.T) {
+	for _, level := range []string{"low", "Medium", "HIGH", "critical"} {
+		assert.True(t, ValidLevel(level), level)
+		assert.Equal(t, level != "critical", ValidConfidence(level), level)
+	}
+	for _, level := range []string{"", "info", "severe"} {
+		assert.False(t, ValidLevel(level), level)
+		assert.False(t, ValidConfidence(level), level)
+	}
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// summaryExamples is the number of locations kept as examples of each rule.
+const summaryExamples = 3
+
+// Summary counts findings per rule and per file instead of listing every
+// match. A vendored or generated file can produce tens of thousands of
+// matches which make a full report unusable, a summary shows where they
+// come from.
+type Summary struct {
+	Findings int
+	Rules    []RuleSummary
+	Files    []FileSummary
+}
+
+// RuleSummary is the number of findings of a rule, the number of files they
+// are in and a few of their locations as examples.
+type RuleSummary struct {
+	RuleID   string
+	Findings int
+	Files    int
+	Examples []SecretLocation
+}
+
+// FileSummary is the number of findings in a file and the rules that
+// matched it.
+type FileSummary struct {
+	File       string
+	Repository string `json:",omitempty"`
+	Findings   int
+	RuleIDs    []string
+}
+
+// Summarize counts the findings per rule and per file. Rules and files are
+// ordered by their number of findings, most first.
+func Summarize(findings []Finding) Summary {
+	summary := Summary{Findings: len(findings), Rules: []RuleSummary{}, Files: []FileSummary{}}
+	type fileKey struct{ repository, file string }
+	rules := make(map[string]int)
+	files := make(map[fileKey]int)
+	ruleFiles := make(map[string]map[fileKey]bool)
+	for _, f := range findings {
+		key := fileKey{f.Repository, f.File}
+		i, ok := rules[f.RuleID]
+		if !ok {
+			i = len(summary.Rules)
+			rules[f.RuleID] = i
+			summary.Rules = append(summary.Rules, RuleSummary{RuleID: f.RuleID})
+			ruleFiles[f.RuleID] = make(map[fileKey]bool)
+		}
+		r := &summary.Rules[i]
+		r.Findings++
+		if !ruleFiles[f.RuleID][key] {
+			ruleFiles[f.RuleID][key] = true
+			r.Files++
+		}
+		if len(r.Examples) < summaryExamples {
+			r.Examples = append(r.Examples, SecretLocation{
+				File:        f.File,
+				StartLine:   f.StartLine,
+				Commit:      f.Commit,
+				Author:      f.Author,
+				Date:        f.Date,
+				Repository:  f.Repository,
+				Fingerprint: f.Fingerprint,
+			})
+		}
+
+		j, ok := files[key]
+		if !ok {
+			j = len(summary.Files)
+			files[key] = j
+			summary.Files = append(summary.Files, FileSummary{File: f.File, Repository: f.Repository})
+		}
+		file := &summary.Files[j]
+		file.Findings++
+		if !contains(file.RuleIDs, f.RuleID) {
+			file.RuleIDs = append(file.RuleIDs, f.RuleID)
+		}
+	}
+	sort.SliceStable(summary.Rules, func(i, j int) bool {
+		return summary.Rules[i].Findings > summary.Rules[j].Findings
+	})
+	sort.SliceStable(summary.Files, func(i, j int) bool {
+		return summary.Files[i].Findings > summary.Files[j].Findings
+	})
+	return summary
+}
+
+func writeSummary(findings []Finding, w io.WriteCloser) error {
+	defer w.Close()
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", " ")
+	return encoder.Encode(Summarize(findings))
+}
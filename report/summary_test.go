@@ -0,0 +1,38 @@
+package report
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	var findings []Finding
+	for i := 0; i < 5; i++ {
+		findings = append(findings, Finding{RuleID: "generic-api-key", File: "vendor/bundle.js", StartLine: i + 1})
+	}
+	findings = append(findings,
+		Finding{RuleID: "aws-access-key", File: "main.go", StartLine: 3},
+		Finding{RuleID: "generic-api-key", File: "main.go", StartLine: 4},
+		Finding{RuleID: "generic-api-key", File: "main.go", StartLine: 4, Repository: "other"},
+	)
+
+	summary := Summarize(findings)
+	assert.Equal(t, 8, summary.Findings)
+	assert.Equal(t, []FileSummary{
+		{File: "vendor/bundle.js", Findings: 5, RuleIDs: []string{"generic-api-key"}},
+		{File: "main.go", Findings: 2, RuleIDs: []string{"aws-access-key", "generic-api-key"}},
+		{File: "main.go", Repository: "other", Findings: 1, RuleIDs: []string{"generic-api-key"}},
+	}, summary.Files)
+
+	var rules []string
+	for _, r := range summary.Rules {
+		rules = append(rules, fmt.Sprintf("%s %d %d %d", r.RuleID, r.Findings, r.Files, len(r.Examples)))
+	}
+	assert.Equal(t, []string{"generic-api-key 7 3 3", "aws-access-key 1 1 1"}, rules)
+	assert.Equal(t, 1, summary.Rules[0].Examples[0].StartLine)
+
+	empty := Summarize(nil)
+	assert.Equal(t, Summary{Rules: []RuleSummary{}, Files: []FileSummary{}}, empty)
+}
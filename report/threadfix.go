@@ -0,0 +1,88 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// threadFixReport is ThreadFix's generic .threadfix JSON import format.
+type threadFixReport struct {
+	Created        string             `json:"created,omitempty"`
+	Exported       string             `json:"exported,omitempty"`
+	CollectionType string             `json:"collectionType"`
+	Source         string             `json:"source"`
+	Findings       []threadFixFinding `json:"findings"`
+}
+
+type threadFixFinding struct {
+	// NativeID is the dedup key, ThreadFix merges findings with the same
+	// native id across scans.
+	NativeID              string                 `json:"nativeId"`
+	Severity              string                 `json:"severity"`
+	NativeSeverity        string                 `json:"nativeSeverity"`
+	Summary               string                 `json:"summary"`
+	Description           string                 `json:"description"`
+	ScannerDetail         string                 `json:"scannerDetail"`
+	ScannerRecommendation string                 `json:"scannerRecommendation"`
+	Mappings              []threadFixMapping     `json:"mappings"`
+	StaticDetails         threadFixStaticDetails `json:"staticDetails"`
+}
+
+type threadFixMapping struct {
+	MappingType string `json:"mappingType"`
+	Value       string `json:"value"`
+	Primary     bool   `json:"primary"`
+}
+
+type threadFixStaticDetails struct {
+	File             string                 `json:"file"`
+	CodeSnippet      string                 `json:"codeSnippet,omitempty"`
+	DataFlowElements []threadFixFlowElement `json:"dataFlowElements"`
+}
+
+type threadFixFlowElement struct {
+	File         string `json:"file"`
+	LineNumber   int    `json:"lineNumber"`
+	ColumnNumber int    `json:"columnNumber"`
+	Text         string `json:"text,omitempty"`
+	Sequence     int    `json:"sequence"`
+}
+
+func writeThreadFix(findings []Finding, manifest Manifest, w io.WriteCloser) error {
+	tfReport := threadFixReport{
+		CollectionType: "SAST",
+		Source:         driver,
+		Findings:       []threadFixFinding{},
+	}
+	if !manifest.IsZero() {
+		tfReport.Created = manifest.StartTime.UTC().Format("2006-01-02T15:04:05Z")
+		tfReport.Exported = manifest.EndTime.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	for _, f := range findings {
+		tfReport.Findings = append(tfReport.Findings, threadFixFinding{
+			NativeID:              dedupKey(f),
+			Severity:              vulnerabilitySeverity(f),
+			NativeSeverity:        f.Severity,
+			Summary:               findingTitle(f),
+			Description:           findingDescription(f),
+			ScannerDetail:         f.RuleID,
+			ScannerRecommendation: secretMitigation,
+			Mappings:              []threadFixMapping{{MappingType: "CWE", Value: "798", Primary: true}},
+			StaticDetails: threadFixStaticDetails{
+				File:        f.File,
+				CodeSnippet: f.Match,
+				DataFlowElements: []threadFixFlowElement{{
+					File:         f.File,
+					LineNumber:   f.StartLine,
+					ColumnNumber: f.StartColumn,
+					Text:         f.Match,
+					Sequence:     1,
+				}},
+			},
+		})
+	}
+	defer w.Close()
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", " ")
+	return encoder.Encode(tfReport)
+}
@@ -0,0 +1,37 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zricethezav/gitleaks/v8/config"
+)
+
+func TestWriteThreadFix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gitleaks.threadfix")
+	start := time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC)
+	manifest := Manifest{Version: "v8.18.0", StartTime: start, EndTime: start.Add(time.Minute)}
+	require.NoError(t, Write(vulnerabilityFindings, config.Config{}, "threadfix", path, manifest))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var tfReport threadFixReport
+	require.NoError(t, json.Unmarshal(data, &tfReport))
+	assert.Equal(t, "2024-05-06T09:00:00Z", tfReport.Created)
+	assert.Equal(t, "SAST", tfReport.CollectionType)
+	require.Len(t, tfReport.Findings, 2)
+
+	f := tfReport.Findings[0]
+	assert.Equal(t, "infra:b6b0bd6:main.tf:aws-access-key:3", f.NativeID)
+	assert.Equal(t, "Critical", f.Severity)
+	assert.Equal(t, []threadFixMapping{{MappingType: "CWE", Value: "798", Primary: true}}, f.Mappings)
+	require.Len(t, f.StaticDetails.DataFlowElements, 1)
+	assert.Equal(t, 3, f.StaticDetails.DataFlowElements[0].LineNumber)
+	assert.Equal(t, 11, f.StaticDetails.DataFlowElements[0].ColumnNumber)
+}
@@ -0,0 +1,174 @@
+package report
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Triage decisions, what a reviewer concluded about a finding.
+const (
+	DecisionFalsePositive = "false-positive"
+	DecisionAccepted      = "accepted"
+	DecisionNeedsRotation = "needs-rotation"
+)
+
+// triagePrompt lists the commands Triage reads.
+const triagePrompt = "[f]alse positive, [a]ccepted, needs [r]otation, [s]kip, [b]ack, [c]ontext, [q]uit > "
+
+// Triage steps through findings, printing each to out and reading a command
+// for it from in, and returns the decisions made by fingerprint. context
+// returns the lines around a finding for the context command, it may be nil.
+// Triage ends after the last finding, on quit or at the end of in.
+func Triage(findings []Finding, in io.Reader, out io.Writer, context func(Finding) []string) map[string]string {
+	decisions := make(map[string]string)
+	scanner := bufio.NewScanner(in)
+	for i := 0; i < len(findings); {
+		f := findings[i]
+		printTriageFinding(out, f, i, len(findings), decisions[f.Fingerprint])
+		fmt.Fprint(out, triagePrompt)
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return decisions
+		}
+
+		switch strings.ToLower(strings.TrimSpace(scanner.Text())) {
+		case "f":
+			decisions[f.Fingerprint] = DecisionFalsePositive
+			i++
+		case "a":
+			decisions[f.Fingerprint] = DecisionAccepted
+			i++
+		case "r":
+			decisions[f.Fingerprint] = DecisionNeedsRotation
+			i++
+		case "s", "":
+			i++
+		case "b":
+			if i > 0 {
+				i--
+			}
+		case "c":
+			var lines []string
+			if context != nil {
+				lines = context(f)
+			}
+			if len(lines) == 0 {
+				fmt.Fprintln(out, "no context available")
+			}
+			for _, line := range lines {
+				fmt.Fprintln(out, line)
+			}
+		case "q":
+			return decisions
+		default:
+			fmt.Fprintln(out, "unknown command")
+		}
+	}
+	return decisions
+}
+
+func printTriageFinding(out io.Writer, f Finding, i int, total int, decision string) {
+	location := fmt.Sprintf("%s:%d", f.File, f.StartLine)
+	if f.Commit != "" {
+		location += " " + f.Commit
+	}
+	fmt.Fprintf(out, "\n[%d/%d] %s %s\n", i+1, total, f.RuleID, location)
+	fmt.Fprintf(out, "%-12s %s\n", "Secret:", f.Secret)
+	fmt.Fprintf(out, "%-12s %s\n", "Match:", f.Match)
+	if f.Author != "" {
+		fmt.Fprintf(out, "%-12s %s <%s> %s\n", "Author:", f.Author, f.Email, f.Date)
+	}
+	if decision != "" {
+		fmt.Fprintf(out, "%-12s %s\n", "Decision:", decision)
+	}
+	for _, line := range f.ContextBefore {
+		fmt.Fprintf(out, "  %s\n", line)
+	}
+	if len(f.ContextBefore) > 0 || len(f.ContextAfter) > 0 {
+		fmt.Fprintf(out, "> %s\n", f.Match)
+	}
+	for _, line := range f.ContextAfter {
+		fmt.Fprintf(out, "  %s\n", line)
+	}
+}
+
+// Decided returns the findings with one of the decisions, in order.
+func Decided(findings []Finding, decisions map[string]string, decision ...string) []Finding {
+	var decided []Finding
+	for _, f := range findings {
+		for _, d := range decision {
+			if decisions[f.Fingerprint] == d {
+				decided = append(decided, f)
+				break
+			}
+		}
+	}
+	return decided
+}
+
+// AppendGitleaksIgnore adds the fingerprints of findings to the
+// .gitleaksignore file at path, skipping those it already has.
+func AppendGitleaksIgnore(path string, findings []Finding) error {
+	existing := make(map[string]bool)
+	var lines strings.Builder
+	if content, err := os.ReadFile(path); err == nil {
+		for _, line := range strings.Split(string(content), "\n") {
+			if i := strings.LastIndex(line, " until:"); i != -1 {
+				line = line[:i]
+			}
+			existing[strings.TrimSpace(line)] = true
+		}
+		if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+			lines.WriteString("\n")
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	for _, f := range findings {
+		if existing[f.Fingerprint] {
+			continue
+		}
+		existing[f.Fingerprint] = true
+		lines.WriteString(f.Fingerprint + "\n")
+	}
+	if _, err := file.WriteString(lines.String()); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// MergeBaseline adds findings to the baseline at path, a JSON report,
+// skipping those it already has by fingerprint.
+func MergeBaseline(path string, findings []Finding) error {
+	var baseline []Finding
+	if content, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(content, &baseline); err != nil {
+			return fmt.Errorf("the format of the baseline %s is not supported", path)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	existing := fingerprints(baseline)
+	for _, f := range findings {
+		if !existing[f.Fingerprint] {
+			existing[f.Fingerprint] = true
+			baseline = append(baseline, f)
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	return writeJson(baseline, file)
+}
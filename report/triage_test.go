@@ -0,0 +1,83 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTriage(t *testing.T) {
+	findings := []Finding{{Fingerprint: "a"}, {Fingerprint: "b"}, {Fingerprint: "c"}, {Fingerprint: "d"}}
+
+	tests := map[string]struct {
+		input    string
+		expected map[string]string
+	}{
+		"every finding": {
+			input:    "f\na\nr\ns\n",
+			expected: map[string]string{"a": DecisionFalsePositive, "b": DecisionAccepted, "c": DecisionNeedsRotation},
+		},
+		"back changes a decision": {
+			input:    "f\nb\na\n",
+			expected: map[string]string{"a": DecisionAccepted},
+		},
+		"quit": {
+			input:    "r\nq\nf\n",
+			expected: map[string]string{"a": DecisionNeedsRotation},
+		},
+		"end of input": {
+			input:    "x\n\nF\n",
+			expected: map[string]string{"b": DecisionFalsePositive},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			decisions := Triage(findings, strings.NewReader(tt.input), &out, nil)
+			assert.Equal(t, tt.expected, decisions)
+		})
+	}
+}
+
+func TestTriageContext(t *testing.T) {
+	var out bytes.Buffer
+	context := func(f Finding) []string { return []string{"19 func main() {", "20 key := \"secret\""} }
+	Triage([]Finding{{Fingerprint: "a", File: "main.go", StartLine: 20}}, strings.NewReader("c\nq\n"), &out, context)
+	assert.Contains(t, out.String(), "[1/1]  main.go:20")
+	assert.Contains(t, out.String(), "20 key := \"secret\"")
+}
+
+func TestDecided(t *testing.T) {
+	findings := []Finding{{Fingerprint: "a"}, {Fingerprint: "b"}, {Fingerprint: "c"}}
+	decisions := map[string]string{"a": DecisionAccepted, "b": DecisionNeedsRotation, "c": DecisionFalsePositive}
+	assert.Equal(t, []Finding{{Fingerprint: "a"}, {Fingerprint: "c"}}, Decided(findings, decisions, DecisionFalsePositive, DecisionAccepted))
+}
+
+func TestAppendGitleaksIgnore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".gitleaksignore")
+	require.NoError(t, os.WriteFile(path, []byte("a\nb until:2999-01-01"), 0o644))
+	require.NoError(t, AppendGitleaksIgnore(path, []Finding{{Fingerprint: "b"}, {Fingerprint: "c"}, {Fingerprint: "c"}}))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "a\nb until:2999-01-01\nc\n", string(content))
+}
+
+func TestMergeBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	require.NoError(t, MergeBaseline(path, []Finding{{Fingerprint: "a"}}))
+	require.NoError(t, MergeBaseline(path, []Finding{{Fingerprint: "a"}, {Fingerprint: "b"}}))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var baseline []Finding
+	require.NoError(t, json.Unmarshal(content, &baseline))
+	assert.Equal(t, []Finding{{Fingerprint: "a"}, {Fingerprint: "b"}}, baseline)
+}
@@ -0,0 +1,45 @@
+// sign writes the base64 encoded ed25519 signature of a file to the file
+// with a .sig suffix, the release checksums `gitleaks update` verifies. The
+// private key, or its seed, is read base64 encoded from
+// GITLEAKS_RELEASE_SIGNING_KEY.
+//
+//	go run ./scripts/sign dist/gitleaks_8.18.0_checksums.txt
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"os"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: sign <file>")
+		os.Exit(2)
+	}
+	key, err := base64.StdEncoding.DecodeString(os.Getenv("GITLEAKS_RELEASE_SIGNING_KEY"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "GITLEAKS_RELEASE_SIGNING_KEY is not base64 encoded")
+		os.Exit(1)
+	}
+	switch len(key) {
+	case ed25519.SeedSize:
+		key = ed25519.NewKeyFromSeed(key)
+	case ed25519.PrivateKeySize:
+	default:
+		fmt.Fprintln(os.Stderr, "GITLEAKS_RELEASE_SIGNING_KEY is not an ed25519 private key")
+		os.Exit(1)
+	}
+
+	content, err := os.ReadFile(os.Args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	signature := ed25519.Sign(ed25519.PrivateKey(key), content)
+	if err := os.WriteFile(os.Args[1]+".sig", []byte(base64.StdEncoding.EncodeToString(signature)+"\n"), 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
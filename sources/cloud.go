@@ -0,0 +1,86 @@
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// CodeCommitRepositories returns the URLs of the AWS CodeCommit repositories
+// in region. Repositories are listed with the aws CLI and the URLs use the
+// codecommit:: scheme of git-remote-codecommit, which has to be installed to
+// clone them. Both use the credentials the aws CLI is configured with.
+func CodeCommitRepositories(region string) ([]string, error) {
+	out, err := cloudCLI("aws", "codecommit", "list-repositories", "--region", region, "--output", "json")
+	if err != nil {
+		return nil, err
+	}
+	return parseCodeCommitRepositories(out, region)
+}
+
+// GCSRRepositories returns the clone URLs of the Google Cloud Source
+// Repositories in project. Repositories are listed with the gcloud CLI. Cloning
+// them requires git to use gcloud as its credential helper for
+// source.developers.google.com.
+func GCSRRepositories(project string) ([]string, error) {
+	out, err := cloudCLI("gcloud", "source", "repos", "list", "--project", project, "--format", "json")
+	if err != nil {
+		return nil, err
+	}
+	return parseGCSRRepositories(out)
+}
+
+// cloudCLI runs a cloud provider's CLI and returns its output.
+func cloudCLI(name string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("the %s CLI is required to list repositories: %w", name, err)
+	}
+	cmd := exec.Command(name, args...)
+	log.Debug().Msgf("executing: %s", cmd.String())
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// parseCodeCommitRepositories converts the output of
+// `aws codecommit list-repositories` to codecommit:: URLs.
+func parseCodeCommitRepositories(out []byte, region string) ([]string, error) {
+	var list struct {
+		Repositories []struct {
+			RepositoryName string `json:"repositoryName"`
+		} `json:"repositories"`
+	}
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf("unable to parse CodeCommit repositories: %w", err)
+	}
+	var urls []string
+	for _, repo := range list.Repositories {
+		urls = append(urls, fmt.Sprintf("codecommit::%s://%s", region, repo.RepositoryName))
+	}
+	return urls, nil
+}
+
+// parseGCSRRepositories returns the clone URLs in the output of
+// `gcloud source repos list`.
+func parseGCSRRepositories(out []byte) ([]string, error) {
+	var list []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	}
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf("unable to parse Cloud Source Repositories: %w", err)
+	}
+	var urls []string
+	for _, repo := range list {
+		urls = append(urls, repo.URL)
+	}
+	return urls, nil
+}
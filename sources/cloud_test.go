@@ -0,0 +1,33 @@
+package sources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCodeCommitRepositories(t *testing.T) {
+	out := []byte(`{"repositories": [
+		{"repositoryName": "payments", "repositoryId": "f7579e13-b83e-4027-aaef-650c0EXAMPLE"},
+		{"repositoryName": "infra", "repositoryId": "cfc29ac4-b0cb-44dc-9990-f6f51EXAMPLE"}
+	]}`)
+	urls, err := parseCodeCommitRepositories(out, "eu-west-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"codecommit::eu-west-1://payments", "codecommit::eu-west-1://infra"}, urls)
+	assert.True(t, RemoteRepository(urls[0]))
+	assert.Equal(t, "payments", RemoteRepositoryName(urls[0]))
+
+	_, err = parseCodeCommitRepositories([]byte("not json"), "eu-west-1")
+	assert.Error(t, err)
+}
+
+func TestParseGCSRRepositories(t *testing.T) {
+	out := []byte(`[
+		{"name": "projects/acme/repos/api", "size": "1024", "url": "https://source.developers.google.com/p/acme/r/api"}
+	]`)
+	urls, err := parseGCSRRepositories(out)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://source.developers.google.com/p/acme/r/api"}, urls)
+	assert.Equal(t, "api", RemoteRepositoryName(urls[0]))
+}
@@ -0,0 +1,90 @@
+package sources
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// codeownersLocations are the paths GitHub and GitLab look for a
+// CODEOWNERS file in, in order of precedence.
+var codeownersLocations = []string{
+	".github/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+	".gitlab/CODEOWNERS",
+}
+
+type codeownersRule struct {
+	pattern gitignorePattern
+	owners  []string
+
+	// unlike .gitignore, a pattern like docs/* only matches files directly
+	// in the directory, not those in subdirectories
+	directChildren bool
+}
+
+// Codeowners maps files in a repository to the teams or users owning them.
+type Codeowners struct {
+	rules []codeownersRule
+}
+
+// LoadCodeowners reads the CODEOWNERS file of the repository at source. A nil
+// Codeowners is returned if the repository does not have one.
+func LoadCodeowners(source string) (*Codeowners, error) {
+	for _, loc := range codeownersLocations {
+		f, err := os.Open(filepath.Join(source, filepath.FromSlash(loc)))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		c := &Codeowners{}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			// gitlab sections, e.g. [Documentation]
+			if strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+				continue
+			}
+			if i := strings.Index(line, " #"); i != -1 {
+				line = line[:i]
+			}
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			p, ok := parseGitignorePattern(fields[0])
+			if !ok || p.negate {
+				continue
+			}
+			c.rules = append(c.rules, codeownersRule{
+				pattern:        p,
+				owners:         fields[1:],
+				directChildren: strings.HasSuffix(fields[0], "/*"),
+			})
+		}
+		return c, scanner.Err()
+	}
+	return nil, nil
+}
+
+// Owners returns the owners of the file at path, relative to the root of
+// the repository. Later rules take precedence, so the last matching rule
+// determines the owners. A rule without owners leaves the file unowned.
+func (c *Codeowners) Owners(path string) []string {
+	if c == nil {
+		return nil
+	}
+	path = strings.TrimPrefix(filepath.ToSlash(path), "/")
+	var owners []string
+	for _, r := range c.rules {
+		if r.directChildren && r.pattern.re.MatchString(path) || !r.directChildren && r.pattern.matches(path) {
+			owners = r.owners
+		}
+	}
+	return owners
+}
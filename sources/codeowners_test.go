@@ -0,0 +1,38 @@
+package sources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeowners(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".github"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".github", "CODEOWNERS"), []byte(`# default owners
+*                   @org/everyone
+*.tf                @org/infra
+/charts/            @org/platform @alice
+docs/*              @org/docs # only top-level docs
+/charts/unowned.yaml
+`), 0o644))
+
+	c, err := LoadCodeowners(dir)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+
+	assert.Equal(t, []string{"@org/everyone"}, c.Owners("main.go"))
+	assert.Equal(t, []string{"@org/infra"}, c.Owners("infra/aws/main.tf"))
+	assert.Equal(t, []string{"@org/platform", "@alice"}, c.Owners("charts/app/values.yaml"))
+	assert.Equal(t, []string{"@org/docs"}, c.Owners("docs/setup.md"))
+	assert.Equal(t, []string{"@org/everyone"}, c.Owners("docs/api/setup.md"))
+	assert.Empty(t, c.Owners("charts/unowned.yaml"))
+
+	missing, err := LoadCodeowners(t.TempDir())
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+	assert.Nil(t, missing.Owners("main.go"))
+}
@@ -0,0 +1,55 @@
+package sources
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ReadTokenFile returns the token stored in file. Only the first line is read
+// so a trailing newline or comment doesn't end up in the token.
+func ReadTokenFile(file string) (string, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(strings.SplitN(string(content), "\n", 2)[0])
+	if token == "" {
+		return "", fmt.Errorf("%s does not contain a token", file)
+	}
+	return token, nil
+}
+
+// CredentialHelperToken asks git's credential helpers for the password stored
+// for rawURL. Helpers backed by the OS keychain, like osxkeychain, manager and
+// libsecret, are the usual place tokens for git hosts are kept. git never
+// prompts for a missing credential, an error is returned instead.
+func CredentialHelperToken(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("%s is not a URL", rawURL)
+	}
+	input := fmt.Sprintf("protocol=%s\nhost=%s\n\n", u.Scheme, u.Host)
+	cmd := exec.Command("git", "credential", "fill")
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("no credentials stored for %s: %w", u.Host, err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "password=") && len(line) > len("password=") {
+			return strings.TrimPrefix(line, "password="), nil
+		}
+	}
+	return "", fmt.Errorf("no credentials stored for %s", u.Host)
+}
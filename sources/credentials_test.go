@@ -0,0 +1,47 @@
+package sources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTokenFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(file, []byte("  abc123\n# rotated monthly\n"), 0o600))
+	token, err := ReadTokenFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", token)
+
+	empty := filepath.Join(dir, "empty")
+	require.NoError(t, os.WriteFile(empty, []byte("\n"), 0o600))
+	_, err = ReadTokenFile(empty)
+	assert.Error(t, err)
+}
+
+func TestCredentialHelperToken(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("credential helper test needs a shell")
+	}
+	// a helper that only knows git.example.com stands in for a keychain
+	gitconfig := filepath.Join(t.TempDir(), "gitconfig")
+	helper := "[credential]\n\thelper = \"!f() { test \\\"$1\\\" = get || exit 0; " +
+		"if grep -q host=git.example.com; then echo username=ci; echo password=keychain-token; fi; }; f\"\n"
+	require.NoError(t, os.WriteFile(gitconfig, []byte(helper), 0o644))
+	t.Setenv("GIT_CONFIG_GLOBAL", gitconfig)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	token, err := CredentialHelperToken("https://git.example.com/api")
+	require.NoError(t, err)
+	assert.Equal(t, "keychain-token", token)
+
+	_, err = CredentialHelperToken("https://other.example.com")
+	assert.Error(t, err)
+
+	_, err = CredentialHelperToken("not a url")
+	assert.Error(t, err)
+}
@@ -9,33 +9,131 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// vendoredDirs are directories that contain third party or generated code.
+var vendoredDirs = map[string]bool{
+	"node_modules":     true,
+	"bower_components": true,
+	"vendor":           true,
+	"third_party":      true,
+	"dist":             true,
+	"Pods":             true,
+	".venv":            true,
+	"venv":             true,
+	"__pycache__":      true,
+}
+
 type ScanTarget struct {
 	Path    string
 	Symlink string
 }
 
+// DirectoryOptions control which files in a directory are scanned.
+type DirectoryOptions struct {
+	// FollowSymlinks scans the files symlinks point to.
+	FollowSymlinks bool
+
+	// RespectGitignore skips files ignored by .gitignore files.
+	RespectGitignore bool
+
+	// SkipVendored skips well known vendored and generated directories
+	// such as node_modules and vendor.
+	SkipVendored bool
+
+	// MaxFileSize skips files larger than this many bytes without opening
+	// them. Zero means there is no limit.
+	MaxFileSize int64
+
+	// GitleaksIgnore is called with the path of every .gitleaksignore file
+	// found below source. It is called before any file in the same directory
+	// is sent so the ignored fingerprints apply to all of them.
+	GitleaksIgnore func(path string) error
+}
+
 func DirectoryTargets(source string, s *semgroup.Group, followSymlinks bool) (<-chan ScanTarget, error) {
+	return DirectoryTargetsWithOptions(source, s, DirectoryOptions{FollowSymlinks: followSymlinks})
+}
+
+// DirectoryTargetsWithOptions walks source and sends every file that should be
+// scanned on the returned channel. The walk runs in s and sends on an
+// unbuffered channel, so it never gets further ahead of the scan than the
+// concurrency of s allows.
+func DirectoryTargetsWithOptions(source string, s *semgroup.Group, opts DirectoryOptions) (<-chan ScanTarget, error) {
 	paths := make(chan ScanTarget)
 	s.Go(func() error {
 		defer close(paths)
-		return filepath.Walk(source,
-			func(path string, fInfo os.FileInfo, err error) error {
+		var ignores gitignores
+		return filepath.WalkDir(source,
+			func(path string, entry fs.DirEntry, err error) error {
 				if err != nil {
-					return err
+					// a directory that can't be read shouldn't stop the
+					// rest of the scan
+					if path == source {
+						return err
+					}
+					log.Debug().Err(err).Msgf("skipping unreadable path: %s", path)
+					if entry != nil && entry.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
 				}
-				if fInfo.Name() == ".git" && fInfo.IsDir() {
-					return filepath.SkipDir
+				if entry.Name() == ".git" {
+					// a linked worktree or submodule has a .git file
+					// pointing to its git directory instead
+					if entry.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if entry.IsDir() {
+					if path != source && opts.SkipVendored && vendoredDirs[entry.Name()] {
+						log.Debug().Msgf("skipping vendored directory: %s", path)
+						return filepath.SkipDir
+					}
+					if opts.RespectGitignore {
+						if path != source && ignores.ignored(path, true) {
+							log.Trace().Msgf("skipping ignored directory: %s", path)
+							return filepath.SkipDir
+						}
+						g, err := loadGitignore(path)
+						if err != nil {
+							log.Debug().Err(err).Msgf("unable to read .gitignore in %s", path)
+						} else if g != nil {
+							ignores = append(ignores, g)
+						}
+					}
+					if path != source && opts.GitleaksIgnore != nil {
+						ignorePath := filepath.Join(path, ".gitleaksignore")
+						if _, err := os.Stat(ignorePath); err == nil {
+							if err := opts.GitleaksIgnore(ignorePath); err != nil {
+								log.Debug().Err(err).Msgf("unable to read %s", ignorePath)
+							}
+						}
+					}
+					return nil
 				}
-				if fInfo.Size() == 0 {
+				if opts.RespectGitignore && ignores.ignored(path, false) {
+					log.Trace().Msgf("skipping ignored file: %s", path)
 					return nil
 				}
-				if fInfo.Mode().IsRegular() {
+				if entry.Type().IsRegular() {
+					fInfo, err := entry.Info()
+					if err != nil {
+						log.Debug().Err(err).Msgf("skipping unreadable file: %s", path)
+						return nil
+					}
+					if fInfo.Size() == 0 {
+						return nil
+					}
+					if opts.MaxFileSize > 0 && fInfo.Size() > opts.MaxFileSize {
+						log.Debug().Msgf("skipping file: %s due to size: %d", path, fInfo.Size())
+						return nil
+					}
 					paths <- ScanTarget{
 						Path:    path,
 						Symlink: "",
 					}
 				}
-				if fInfo.Mode().Type() == fs.ModeSymlink && followSymlinks {
+				if entry.Type() == fs.ModeSymlink && opts.FollowSymlinks {
 					realPath, err := filepath.EvalSymlinks(path)
 					if err != nil {
 						return err
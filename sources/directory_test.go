@@ -0,0 +1,57 @@
+package sources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/semgroup"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectoryTargetsOptions(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"small.txt":             "small",
+		"large.txt":             strings.Repeat("x", 100),
+		".gitleaksignore":       "small.txt:rule:1\n",
+		"sub/.gitleaksignore":   "a.txt:rule:1\n",
+		"sub/a.txt":             "a",
+		"sub/.git":              "gitdir: ../.git/modules/sub\n",
+		"sub/deeper/b.txt":      "b",
+		"other/no-ignore.txt":   "c",
+		"sub/deeper/empty.txt":  "",
+		"sub/deeper/larger.txt": strings.Repeat("y", 51),
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+
+	var ignoreFiles []string
+	s := semgroup.NewGroup(context.Background(), 4)
+	paths, err := DirectoryTargetsWithOptions(dir, s, DirectoryOptions{
+		MaxFileSize: 50,
+		GitleaksIgnore: func(path string) error {
+			ignoreFiles = append(ignoreFiles, path)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	var found []string
+	for p := range paths {
+		rel, _ := filepath.Rel(dir, p.Path)
+		found = append(found, filepath.ToSlash(rel))
+	}
+	require.NoError(t, s.Wait())
+
+	assert.ElementsMatch(t, []string{
+		"small.txt", ".gitleaksignore", "sub/.gitleaksignore", "sub/a.txt", "sub/deeper/b.txt", "other/no-ignore.txt",
+	}, found)
+	// the .gitleaksignore at the root is loaded up front, not by the walk
+	assert.Equal(t, []string{filepath.Join(dir, "sub", ".gitleaksignore")}, ignoreFiles)
+}
@@ -0,0 +1,18 @@
+package sources
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreeDiskSpace(t *testing.T) {
+	free, err := FreeDiskSpace(t.TempDir())
+	require.NoError(t, err)
+	assert.Greater(t, free, int64(0))
+
+	_, err = FreeDiskSpace(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
@@ -2,12 +2,17 @@ package sources
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
+	"fmt"
 	"io"
+	"net/url"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gitleaks/go-gitdiff/gitdiff"
 	"github.com/rs/zerolog/log"
@@ -17,23 +22,76 @@ var quotedOptPattern = regexp.MustCompile(`^(?:"[^"]+"|'[^']+')$`)
 
 // GitCmd helps to work with Git's output.
 type GitCmd struct {
+	// dir and cmd are empty for a diff read with NewDiffReader.
+	dir         string
 	cmd         *exec.Cmd
 	diffFilesCh <-chan *gitdiff.File
 	errCh       <-chan error
 }
 
+// MergeCommits selects how the changes of merge commits are scanned.
+type MergeCommits string
+
+const (
+	// MergeCommitsDefault scans the commits merge commits merge but not
+	// the merge commits themselves, like git log -p.
+	MergeCommitsDefault MergeCommits = ""
+	// MergeCommitsFirstParent scans merge commits against their first
+	// parent, everything the merge brought into the branch.
+	MergeCommitsFirstParent MergeCommits = "first-parent"
+	// MergeCommitsAll scans merge commits against each of their parents.
+	MergeCommitsAll MergeCommits = "all"
+	// MergeCommitsSkip leaves merge commits out of the scan entirely.
+	MergeCommitsSkip MergeCommits = "skip"
+)
+
+// ParseMergeCommits returns the MergeCommits named s.
+func ParseMergeCommits(s string) (MergeCommits, error) {
+	switch m := MergeCommits(s); m {
+	case MergeCommitsDefault, MergeCommitsFirstParent, MergeCommitsAll, MergeCommitsSkip:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown merge commit handling %q, expected first-parent, all or skip", s)
+}
+
+// logArgs returns the git log arguments selecting the merge commit handling.
+func (m MergeCommits) logArgs() []string {
+	switch m {
+	case MergeCommitsFirstParent:
+		return []string{"--diff-merges=first-parent"}
+	case MergeCommitsAll:
+		return []string{"--diff-merges=separate"}
+	case MergeCommitsSkip:
+		return []string{"--no-merges"}
+	}
+	return nil
+}
+
 // NewGitLogCmd returns `*DiffFilesCmd` with two channels: `<-chan *gitdiff.File` and `<-chan error`.
 // Caller should read everything from channels until receiving a signal about their closure and call
 // the `func (*DiffFilesCmd) Wait()` error in order to release resources.
-func NewGitLogCmd(source string, logOpts string) (*GitCmd, error) {
+// If paths are given, only changes to files matching the pathspecs are scanned.
+func NewGitLogCmd(source string, logOpts string, paths ...string) (*GitCmd, error) {
+	return NewGitHistoryCmd(source, logOpts, MergeCommitsDefault, paths...)
+}
+
+// NewGitHistoryCmd is NewGitLogCmd with the changes of merge commits scanned
+// as merges selects.
+func NewGitHistoryCmd(source string, logOpts string, merges MergeCommits, paths ...string) (*GitCmd, error) {
 	sourceClean := filepath.Clean(source)
 	var cmd *exec.Cmd
 	if logOpts != "" {
-		args := []string{"-C", sourceClean, "log", "-p", "-U0"}
-
 		// Ensure that the user-provided |logOpts| aren't wrapped in quotes.
 		// https://github.com/gitleaks/gitleaks/issues/1153
 		userArgs := strings.Split(logOpts, " ")
+		if arg := formatArg(userArgs); arg != "" {
+			return nil, fmt.Errorf("--log-opts can't change the commit format, gitleaks reads commits with --pretty=fuller: %s", arg)
+		}
+		if merges == MergeCommitsDefault && !diffsMerges(userArgs) {
+			merges = MergeCommitsSkip
+		}
+		args := append([]string{"-C", sourceClean, "log", "-p", "-U0", "--pretty=fuller"}, merges.logArgs()...)
+
 		var quotedOpts []string
 		for _, element := range userArgs {
 			if quotedOptPattern.MatchString(element) {
@@ -44,53 +102,142 @@ func NewGitLogCmd(source string, logOpts string) (*GitCmd, error) {
 			log.Warn().Msgf("the following `--log-opts` values may not work as expected: %v\n\tsee https://github.com/gitleaks/gitleaks/issues/1153 for more information", quotedOpts)
 		}
 
-		args = append(args, userArgs...)
+		args, err := appendPathspecs(append(args, userArgs...), paths)
+		if err != nil {
+			return nil, err
+		}
 		cmd = exec.Command("git", args...)
 	} else {
-		cmd = exec.Command("git", "-C", sourceClean, "log", "-p", "-U0",
-			"--full-history", "--all")
+		args := []string{"-C", sourceClean, "log", "-p", "-U0",
+			"--pretty=fuller", "--full-history", "--all"}
+		if merges == MergeCommitsDefault {
+			merges = MergeCommitsSkip
+		}
+		args, err := appendPathspecs(append(args, merges.logArgs()...), paths)
+		if err != nil {
+			return nil, err
+		}
+		cmd = exec.Command("git", args...)
 	}
 
-	log.Debug().Msgf("executing: %s", cmd.String())
+	return startGitCmd(sourceClean, cmd)
+}
 
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return nil, err
+// NewGitDiffCmd returns `*DiffFilesCmd` with two channels: `<-chan *gitdiff.File` and `<-chan error`.
+// Caller should read everything from channels until receiving a signal about their closure and call
+// the `func (*DiffFilesCmd) Wait()` error in order to release resources.
+func NewGitDiffCmd(source string, staged bool) (*GitCmd, error) {
+	sourceClean := filepath.Clean(source)
+	var cmd *exec.Cmd
+	cmd = exec.Command("git", "-C", sourceClean, "diff", "-U0", "--no-ext-diff", ".")
+	if staged {
+		cmd = exec.Command("git", "-C", sourceClean, "diff", "-U0", "--no-ext-diff",
+			"--staged", ".")
 	}
-	stderr, err := cmd.StderrPipe()
+	return startGitCmd(sourceClean, cmd)
+}
+
+// NewGitDiffHeadCmd returns a `*GitCmd` for the staged and unstaged changes in
+// the working tree compared to HEAD. In a repository without any commits the
+// staged changes are returned instead.
+func NewGitDiffHeadCmd(source string) (*GitCmd, error) {
+	sourceClean := filepath.Clean(source)
+	cmd := exec.Command("git", "-C", sourceClean, "diff", "-U0", "--no-ext-diff", "HEAD", ".")
+	if err := exec.Command("git", "-C", sourceClean, "rev-parse", "--verify", "--quiet", "HEAD").Run(); err != nil {
+		cmd = exec.Command("git", "-C", sourceClean, "diff", "-U0", "--no-ext-diff",
+			"--staged", ".")
+	}
+	return startGitCmd(sourceClean, cmd)
+}
+
+// NewGitDiffRefsCmd returns a `*GitCmd` for the changes made on to since it
+// diverged from from, the final diff of merging to into from. If paths are
+// given, only changes to files matching the pathspecs are returned.
+func NewGitDiffRefsCmd(source string, from string, to string, paths ...string) (*GitCmd, error) {
+	sourceClean := filepath.Clean(source)
+	args, err := appendPathspecs([]string{"-C", sourceClean, "diff", "-U0", "--no-ext-diff", from + "..." + to}, paths)
 	if err != nil {
 		return nil, err
 	}
-	if err := cmd.Start(); err != nil {
+	return startGitCmd(sourceClean, exec.Command("git", args...))
+}
+
+// NewDiffReader returns a `*GitCmd` for a unified diff read from r instead of
+// git's output, e.g. a patch exported from a code review system. Diffs made
+// with git diff, git log -p or git format-patch keep their commit and
+// author, plain unified diffs are attributed to no commit.
+func NewDiffReader(r io.Reader) (*GitCmd, error) {
+	diffFiles, err := gitdiff.Parse(r)
+	if err != nil {
 		return nil, err
 	}
-
 	errCh := make(chan error)
-	go listenForStdErr(stderr, errCh)
+	close(errCh)
+	return &GitCmd{diffFilesCh: diffFiles, errCh: errCh}, nil
+}
 
-	gitdiffFiles, err := gitdiff.Parse(stdout)
+// ResolveCommit returns the commit ref points to in the repository located
+// at source.
+func ResolveCommit(source string, ref string) (string, error) {
+	out, err := exec.Command("git", "-C", filepath.Clean(source), "rev-parse", "--verify", "--quiet",
+		"--end-of-options", ref+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("%s is not a commit in %s", ref, source)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// UntrackedFiles returns the files in the working tree that are neither
+// tracked nor ignored by git. Paths are joined with source.
+func UntrackedFiles(source string) ([]ScanTarget, error) {
+	sourceClean := filepath.Clean(source)
+	out, err := exec.Command("git", "-C", sourceClean, "ls-files", "--others",
+		"--exclude-standard", "-z", ".").Output()
 	if err != nil {
 		return nil, err
 	}
+	var targets []ScanTarget
+	for _, path := range strings.Split(string(out), "\x00") {
+		if path == "" {
+			continue
+		}
+		targets = append(targets, ScanTarget{Path: filepath.Join(sourceClean, path)})
+	}
+	return targets, nil
+}
 
-	return &GitCmd{
-		cmd:         cmd,
-		diffFilesCh: gitdiffFiles,
-		errCh:       errCh,
-	}, nil
+// diffsMerges reports whether the git log arguments select merge commits or
+// make git log show a diff for them. Without a diff git log shows only the
+// header of a merge commit, and the parser would attribute the changes of
+// the commit after it, like the root commit of an orphan branch, to the
+// merge commit, so they're skipped instead.
+func diffsMerges(args []string) bool {
+	for _, arg := range args {
+		switch {
+		case arg == "-m", arg == "-c", arg == "--cc", arg == "--dd", arg == "--merges",
+			arg == "--remerge-diff", strings.HasPrefix(arg, "--diff-merges"), strings.HasPrefix(arg, "--min-parents"):
+			return true
+		}
+	}
+	return false
 }
 
-// NewGitDiffCmd returns `*DiffFilesCmd` with two channels: `<-chan *gitdiff.File` and `<-chan error`.
-// Caller should read everything from channels until receiving a signal about their closure and call
-// the `func (*DiffFilesCmd) Wait()` error in order to release resources.
-func NewGitDiffCmd(source string, staged bool) (*GitCmd, error) {
-	sourceClean := filepath.Clean(source)
-	var cmd *exec.Cmd
-	cmd = exec.Command("git", "-C", sourceClean, "diff", "-U0", "--no-ext-diff", ".")
-	if staged {
-		cmd = exec.Command("git", "-C", sourceClean, "diff", "-U0", "--no-ext-diff",
-			"--staged", ".")
+// formatArg returns the first of args that changes the commit format of git
+// log, which would override the --pretty=fuller the committer and commit date
+// of findings are read from.
+func formatArg(args []string) string {
+	for _, arg := range args {
+		switch {
+		case arg == "--oneline", arg == "--pretty", strings.HasPrefix(arg, "--pretty="), strings.HasPrefix(arg, "--format="):
+			return arg
+		}
 	}
+	return ""
+}
+
+// startGitCmd starts cmd, run in the repository at dir, and parses its
+// output as a diff.
+func startGitCmd(dir string, cmd *exec.Cmd) (*GitCmd, error) {
 	log.Debug().Msgf("executing: %s", cmd.String())
 
 	stdout, err := cmd.StdoutPipe()
@@ -114,12 +261,242 @@ func NewGitDiffCmd(source string, staged bool) (*GitCmd, error) {
 	}
 
 	return &GitCmd{
+		dir:         dir,
 		cmd:         cmd,
 		diffFilesCh: gitdiffFiles,
 		errCh:       errCh,
 	}, nil
 }
 
+// RepositoryName returns the name of the repository located at source. This is
+// the base name of the repository's top level directory. For a linked
+// worktree, whose .git is a file pointing into the main repository, it's
+// the name of the main repository so every worktree of a repository shares
+// its name, baseline and history in the store.
+func RepositoryName(source string) string {
+	sourceClean := filepath.Clean(source)
+	out, err := exec.Command("git", "-C", sourceClean, "rev-parse", "--show-toplevel", "--git-common-dir").Output()
+	if lines := strings.Split(strings.TrimSpace(string(out)), "\n"); err == nil && len(lines) == 2 && lines[0] != "" {
+		commonDir := lines[1]
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(sourceClean, commonDir)
+		}
+		if filepath.Base(commonDir) == ".git" {
+			return filepath.Base(filepath.Dir(commonDir))
+		}
+		return filepath.Base(lines[0])
+	}
+	abs, err := filepath.Abs(source)
+	if err != nil {
+		return filepath.Base(source)
+	}
+	return filepath.Base(abs)
+}
+
+// RepositoryRoot returns the top level directory of the git repository
+// containing source, or source itself if it isn't in a repository.
+func RepositoryRoot(source string) string {
+	out, err := exec.Command("git", "-C", filepath.Clean(source), "rev-parse", "--show-toplevel").Output()
+	if err == nil && len(bytes.TrimSpace(out)) > 0 {
+		return string(bytes.TrimSpace(out))
+	}
+	return source
+}
+
+// HeadCommit returns the commit HEAD of the repository located at source
+// points to, or an empty string if it has none.
+func HeadCommit(source string) string {
+	out, err := exec.Command("git", "-C", filepath.Clean(source), "rev-parse", "--verify", "--quiet", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// BlameLine is the commit that last changed a line and its author.
+type BlameLine struct {
+	Commit string
+	Author string
+	Email  string
+	Date   time.Time
+}
+
+// Blame returns the commit that last changed line, counting from 1, of the
+// file at path as of ref in the repository located at source. Whitespace
+// changes and lines moved or copied from other files don't count as
+// changes, so reformatting and refactoring commits are seen through.
+func Blame(source string, ref string, path string, line int) (BlameLine, error) {
+	out, err := exec.Command("git", "-C", filepath.Clean(source), "blame", "--porcelain", "-w", "-M", "-C",
+		"-L", fmt.Sprintf("%d,%d", line, line), ref, "--", path).Output()
+	if err != nil {
+		return BlameLine{}, fmt.Errorf("blaming %s:%d: %w", path, line, err)
+	}
+	var blame BlameLine
+	for i, l := range strings.Split(string(out), "\n") {
+		if i == 0 {
+			blame.Commit, _, _ = strings.Cut(l, " ")
+			continue
+		}
+		key, value, _ := strings.Cut(l, " ")
+		switch key {
+		case "author":
+			blame.Author = value
+		case "author-mail":
+			blame.Email = strings.Trim(value, "<>")
+		case "author-time":
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			if err == nil {
+				blame.Date = time.Unix(seconds, 0).UTC()
+			}
+		}
+		// the line itself follows the headers, prefixed with a tab
+		if strings.HasPrefix(l, "\t") {
+			break
+		}
+	}
+	return blame, nil
+}
+
+// Signature statuses of commits, see CommitSignature.
+const (
+	SignatureGood       = "good"
+	SignatureBad        = "bad"
+	SignatureUntrusted  = "untrusted"
+	SignatureExpired    = "expired"
+	SignatureExpiredKey = "expired-key"
+	SignatureRevokedKey = "revoked-key"
+	SignatureUnknownKey = "unknown-key"
+	SignatureNone       = "none"
+)
+
+// signatureStatuses maps the %G? placeholder of git log to signature statuses.
+var signatureStatuses = map[string]string{
+	"G": SignatureGood,
+	"B": SignatureBad,
+	"U": SignatureUntrusted,
+	"X": SignatureExpired,
+	"Y": SignatureExpiredKey,
+	"R": SignatureRevokedKey,
+	"E": SignatureUnknownKey,
+	"N": SignatureNone,
+}
+
+// CommitSignature is the result of verifying the GPG, SSH or X.509 signature
+// of a commit with the keys git is configured to trust.
+type CommitSignature struct {
+	Status string
+	Signer string
+	Key    string
+}
+
+// VerifyCommitSignature verifies the signature of commit in the repository at
+// source.
+func VerifyCommitSignature(source string, commit string) (CommitSignature, error) {
+	out, err := exec.Command("git", "-C", filepath.Clean(source), "log", "-1", "--format=%G?%x00%GS%x00%GK", commit).Output()
+	if err != nil {
+		return CommitSignature{}, fmt.Errorf("verifying the signature of %s: %w", commit, err)
+	}
+	fields := strings.Split(strings.TrimRight(string(out), "\n"), "\x00")
+	if len(fields) != 3 {
+		return CommitSignature{}, fmt.Errorf("verifying the signature of %s: unexpected output %q", commit, out)
+	}
+	status, ok := signatureStatuses[fields[0]]
+	if !ok {
+		return CommitSignature{}, fmt.Errorf("verifying the signature of %s: unknown status %q", commit, fields[0])
+	}
+	return CommitSignature{Status: status, Signer: fields[1], Key: fields[2]}, nil
+}
+
+// FileCommit is a commit that changed a file.
+type FileCommit struct {
+	Commit string
+	Date   time.Time
+}
+
+// FileHistory returns the commits on the first-parent history of ref since
+// the commit since that changed the file at path, oldest first. Changes made
+// on merged branches show up as the merge commit bringing them in.
+func FileHistory(source string, since string, ref string, path string) ([]FileCommit, error) {
+	out, err := exec.Command("git", "-C", filepath.Clean(source), "log", "--first-parent", "--reverse",
+		"--format=%H %ct", since+".."+ref, "--", path).Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing the commits changing %s: %w", path, err)
+	}
+	var commits []FileCommit
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		commit, date, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		seconds, err := strconv.ParseInt(date, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("listing the commits changing %s: %w", path, err)
+		}
+		commits = append(commits, FileCommit{Commit: commit, Date: time.Unix(seconds, 0).UTC()})
+	}
+	return commits, nil
+}
+
+// RemoteURL returns the fetch URL of the `origin` remote of the repository
+// located at source. Any credentials embedded in the URL are stripped so they
+// don't end up in reports. An empty string is returned if there is no remote.
+func RemoteURL(source string) string {
+	out, err := exec.Command("git", "-C", filepath.Clean(source), "config", "--get", "remote.origin.url").Output()
+	if err != nil {
+		return ""
+	}
+	return RedactURL(strings.TrimSpace(string(out)))
+}
+
+// RedactURL strips any credentials embedded in a repository URL so they
+// don't end up in logs and reports.
+func RedactURL(remote string) string {
+	if u, err := url.Parse(remote); err == nil && u.User != nil && u.Scheme != "" {
+		u.User = nil
+		return u.String()
+	}
+	return remote
+}
+
+// FileAtRef returns the contents of the file at path as of the git ref.
+func FileAtRef(source string, ref string, path string) (string, error) {
+	out, err := exec.Command("git", "-C", filepath.Clean(source), "show", ref+":"+path).Output()
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// appendPathspecs adds the paths to the git arguments. Paths containing
+// "**" use the glob magic so they match across directories. Arguments that
+// already end in "-- <path>..." are rejected, git would scan the files
+// matching either list rather than only those matching both.
+func appendPathspecs(args []string, paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return args, nil
+	}
+	for _, arg := range args {
+		if arg == "--" {
+			return nil, errors.New("paths can't be combined with log options that already limit the paths with --, use one or the other")
+		}
+	}
+	args = append(args, "--")
+	for _, p := range paths {
+		p = filepath.ToSlash(p)
+		if strings.Contains(p, "**") && !strings.HasPrefix(p, ":") {
+			p = ":(glob)" + p
+		}
+		args = append(args, p)
+	}
+	return args, nil
+}
+
+// Dir returns the repository the diff comes from, or an empty string for a
+// diff read with NewDiffReader.
+func (c *GitCmd) Dir() string {
+	return c.dir
+}
+
 // DiffFilesCh returns a channel with *gitdiff.File.
 func (c *GitCmd) DiffFilesCh() <-chan *gitdiff.File {
 	return c.diffFilesCh
@@ -135,9 +512,29 @@ func (c *GitCmd) ErrCh() <-chan error {
 //
 // Wait also closes underlying stdout and stderr.
 func (c *GitCmd) Wait() (err error) {
+	if c.cmd == nil {
+		return nil
+	}
 	return c.cmd.Wait()
 }
 
+// Kill stops git before it has written all of its output, when the rest of
+// it isn't needed. The channels are drained so the goroutines parsing the
+// output and stderr exit. Wait must still be called.
+func (c *GitCmd) Kill() {
+	if c.cmd != nil && c.cmd.Process != nil {
+		_ = c.cmd.Process.Kill()
+	}
+	go func() {
+		for range c.diffFilesCh {
+		}
+	}()
+	go func() {
+		for range c.errCh {
+		}
+	}()
+}
+
 // listenForStdErr listens for stderr output from git, prints it to stdout,
 // sends to errCh and closes it.
 func listenForStdErr(stderr io.ReadCloser, errCh chan<- error) {
@@ -1,5 +1,159 @@
 package sources
 
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gitleaks/go-gitdiff/gitdiff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zricethezav/gitleaks/v8/sources/gittest"
+)
+
+func TestAppendPathspecs(t *testing.T) {
+	args := []string{"log", "-p"}
+	got, err := appendPathspecs(args, nil)
+	require.NoError(t, err)
+	assert.Equal(t, args, got)
+
+	got, err = appendPathspecs([]string{"log", "-p"}, []string{"infra/", "charts/**/values.yaml", ":(exclude)docs"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"log", "-p", "--", "infra/", ":(glob)charts/**/values.yaml", ":(exclude)docs"}, got)
+
+	// log options that already end in pathspecs are left alone without
+	// paths and rejected with them
+	args = []string{"log", "-p", "main", "--", "src/"}
+	got, err = appendPathspecs(args, nil)
+	require.NoError(t, err)
+	assert.Equal(t, args, got)
+	_, err = appendPathspecs(args, []string{"infra/"})
+	assert.Error(t, err)
+}
+
+func TestGitLogFormatOpts(t *testing.T) {
+	for _, logOpts := range []string{"--pretty=oneline", "--all --format=%H", "--oneline", "--pretty"} {
+		_, err := NewGitLogCmd(".", logOpts)
+		require.Error(t, err, logOpts)
+		assert.Contains(t, err.Error(), "--log-opts can't change the commit format", logOpts)
+	}
+	assert.Empty(t, formatArg([]string{"--all", "--since=2023-01-01", "main..dev"}))
+}
+
+func TestGitDiffHead(t *testing.T) {
+	diffFiles := func(gitCmd *GitCmd) []string {
+		var names []string
+		for f := range gitCmd.DiffFilesCh() {
+			names = append(names, f.NewName)
+		}
+		require.NoError(t, gitCmd.Wait())
+		sort.Strings(names)
+		return names
+	}
+
+	repo := gittest.New(t)
+	repo.WriteFile("staged.txt", "first")
+	repo.Git("add", "staged.txt")
+
+	// without any commits only the staged changes can be diffed
+	assert.Empty(t, HeadCommit(repo.Dir))
+	gitCmd, err := NewGitDiffHeadCmd(repo.Dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"staged.txt"}, diffFiles(gitCmd))
+
+	repo.WriteFile("unstaged.txt", "first")
+	repo.WriteFile(".gitignore", "ignored.txt\n")
+	head := repo.Commit("initial")
+	assert.Equal(t, head, HeadCommit(repo.Dir))
+	repo.WriteFile("staged.txt", "second")
+	repo.Git("add", "staged.txt")
+	repo.WriteFile("unstaged.txt", "second")
+	repo.WriteFile("untracked.txt", "first")
+	repo.WriteFile("ignored.txt", "first")
+
+	gitCmd, err = NewGitDiffHeadCmd(repo.Dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"staged.txt", "unstaged.txt"}, diffFiles(gitCmd))
+
+	untracked, err := UntrackedFiles(repo.Dir)
+	require.NoError(t, err)
+	assert.Equal(t, []ScanTarget{{Path: filepath.Join(repo.Dir, "untracked.txt")}}, untracked)
+}
+
+func TestGitDiffRefs(t *testing.T) {
+	repo := gittest.New(t)
+	repo.WriteFile("main.txt", "first")
+	base := repo.Commit("initial")
+	repo.Branch("feature")
+	repo.WriteFile("feature.txt", "first")
+	repo.Commit("add feature")
+	repo.WriteFile("feature.txt", "second")
+	repo.WriteFile("docs/feature.md", "first")
+	feature := repo.Commit("change feature")
+	repo.Checkout(base)
+	repo.WriteFile("main.txt", "second")
+	repo.Commit("change main")
+
+	resolved, err := ResolveCommit(repo.Dir, "feature")
+	require.NoError(t, err)
+	assert.Equal(t, feature, resolved)
+	_, err = ResolveCommit(repo.Dir, "missing")
+	assert.Error(t, err)
+	_, err = ResolveCommit(repo.Dir, "--all")
+	assert.Error(t, err)
+
+	// only the changes of feature, not those made on main since it diverged
+	diffFiles := func(paths ...string) []string {
+		gitCmd, err := NewGitDiffRefsCmd(repo.Dir, "HEAD", "feature", paths...)
+		require.NoError(t, err)
+		var names []string
+		for f := range gitCmd.DiffFilesCh() {
+			names = append(names, f.NewName)
+		}
+		require.NoError(t, gitCmd.Wait())
+		sort.Strings(names)
+		return names
+	}
+	assert.Equal(t, []string{"docs/feature.md", "feature.txt"}, diffFiles())
+	assert.Equal(t, []string{"docs/feature.md"}, diffFiles("docs/"))
+}
+
+func TestDiffReader(t *testing.T) {
+	diff := `diff --git a/config.env b/config.env
+new file mode 100644
+--- /dev/null
++++ b/config.env
+@@ -0,0 +1,2 @@
++USER=admin
++PASSWORD=hunter2
+diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -3 +3 @@
+-const token = ""
++const token = "abc"
+`
+	gitCmd, err := NewDiffReader(strings.NewReader(diff))
+	require.NoError(t, err)
+	var names []string
+	var added []string
+	for f := range gitCmd.DiffFilesCh() {
+		names = append(names, f.NewName)
+		for _, fragment := range f.TextFragments {
+			added = append(added, fragment.Raw(gitdiff.OpAdd))
+		}
+	}
+	for range gitCmd.ErrCh() {
+	}
+	require.NoError(t, gitCmd.Wait())
+	assert.Equal(t, []string{"config.env", "main.go"}, names)
+	assert.Equal(t, []string{"USER=admin\nPASSWORD=hunter2\n", "const token = \"abc\"\n"}, added)
+}
+
 // TODO: commenting out this test for now because it's flaky. Alternatives to consider to get this working:
 // -- use `git stash` instead of `restore()`
 
@@ -156,3 +310,184 @@ package sources
 // 	}
 // 	return nil
 // }
+
+func TestGitHistoryMergeCommits(t *testing.T) {
+	repo := gittest.New(t)
+	repo.WriteFile("main.txt", "first")
+	repo.Commit("initial")
+	repo.Branch("feature")
+	repo.WriteFile("feature.txt", "first")
+	feature := repo.Commit("add feature")
+	repo.Checkout("main")
+	repo.WriteFile("main.txt", "second")
+	repo.Commit("change main")
+
+	// a merge commit with changes of its own, as when resolving conflicts
+	repo.Git("merge", "--quiet", "--no-ff", "--no-commit", "feature")
+	repo.WriteFile("resolved.txt", "first")
+	evil := repo.Commit("merge feature")
+
+	changes := func(merges MergeCommits) []string {
+		gitCmd, err := NewGitHistoryCmd(repo.Dir, "", merges)
+		require.NoError(t, err)
+		var changes []string
+		for f := range gitCmd.DiffFilesCh() {
+			if f.PatchHeader.SHA == evil || f.PatchHeader.SHA == feature {
+				changes = append(changes, f.PatchHeader.SHA[:7]+" "+f.NewName)
+			}
+		}
+		require.NoError(t, gitCmd.Wait())
+		sort.Strings(changes)
+		return changes
+	}
+	assert.Equal(t, []string{feature[:7] + " feature.txt"}, changes(MergeCommitsDefault))
+	assert.Equal(t, []string{feature[:7] + " feature.txt"}, changes(MergeCommitsSkip))
+	assert.ElementsMatch(t, []string{feature[:7] + " feature.txt", evil[:7] + " feature.txt", evil[:7] + " resolved.txt"},
+		changes(MergeCommitsFirstParent))
+	assert.ElementsMatch(t, []string{feature[:7] + " feature.txt", evil[:7] + " feature.txt", evil[:7] + " resolved.txt",
+		evil[:7] + " main.txt", evil[:7] + " resolved.txt"}, changes(MergeCommitsAll))
+
+	_, err := ParseMergeCommits("second-parent")
+	assert.Error(t, err)
+}
+
+func TestGitHistoryOctopusAndOrphans(t *testing.T) {
+	repo := gittest.New(t)
+	repo.WriteFile("README.md", "readme")
+	initial := repo.Commit("initial")
+	commits := map[string]string{}
+	for _, branch := range []string{"a", "b"} {
+		repo.Checkout(initial)
+		repo.Branch(branch)
+		repo.WriteFile(branch+".txt", branch)
+		commits[branch] = repo.Commit("add " + branch)
+	}
+	repo.Checkout("main")
+	repo.WriteFile("main.txt", "main")
+	commits["main"] = repo.Commit("change main")
+	octopus := repo.Octopus("merge a and b", "a", "b")
+
+	// the root commit of an orphan branch holds its whole tree
+	repo.Orphan("gh-pages")
+	repo.WriteFile("index.html", "pages")
+	repo.WriteFile("assets/site.js", "pages")
+	pages := repo.Commit("publish pages")
+	repo.Checkout("main")
+
+	changes := func(logOpts string, merges MergeCommits) []string {
+		gitCmd, err := NewGitHistoryCmd(repo.Dir, logOpts, merges)
+		require.NoError(t, err)
+		var changes []string
+		for f := range gitCmd.DiffFilesCh() {
+			changes = append(changes, f.PatchHeader.SHA[:7]+" "+f.NewName)
+		}
+		require.NoError(t, gitCmd.Wait())
+		sort.Strings(changes)
+		return changes
+	}
+	change := func(commit string, name string) string {
+		return commit[:7] + " " + name
+	}
+	commitChanges := []string{
+		change(initial, "README.md"), change(commits["a"], "a.txt"), change(commits["b"], "b.txt"),
+		change(commits["main"], "main.txt"), change(pages, "assets/site.js"), change(pages, "index.html"),
+	}
+	sort.Strings(commitChanges)
+	assert.Equal(t, commitChanges, changes("", MergeCommitsDefault))
+	assert.Equal(t, commitChanges, changes("", MergeCommitsSkip))
+	assert.Equal(t, commitChanges, changes("--all", MergeCommitsDefault))
+
+	// the octopus merge against its first parent brings in both branches,
+	// against each parent the changes of the other two
+	firstParent := append([]string{change(octopus, "a.txt"), change(octopus, "b.txt")}, commitChanges...)
+	assert.ElementsMatch(t, firstParent, changes("", MergeCommitsFirstParent))
+	assert.ElementsMatch(t, firstParent, changes("--all --diff-merges=first-parent", MergeCommitsDefault))
+	all := append([]string{change(octopus, "a.txt"), change(octopus, "a.txt"), change(octopus, "b.txt"),
+		change(octopus, "b.txt"), change(octopus, "main.txt"), change(octopus, "main.txt")}, commitChanges...)
+	assert.ElementsMatch(t, all, changes("", MergeCommitsAll))
+	assert.ElementsMatch(t, all, changes("--all -m", MergeCommitsDefault))
+}
+
+func TestGitWorktree(t *testing.T) {
+	repo := gittest.New(t)
+	repo.WriteFile("main.txt", "first")
+	repo.Commit("initial")
+	repo.Branch("feature")
+	repo.WriteFile("feature.txt", "first")
+	feature := repo.Commit("add feature")
+	repo.Checkout("main")
+
+	worktree := filepath.Join(t.TempDir(), "feature-checkout")
+	repo.Worktree(worktree, "feature")
+	require.NoError(t, os.MkdirAll(filepath.Join(worktree, "docs"), 0o755))
+
+	name := filepath.Base(repo.Dir)
+	assert.Equal(t, name, RepositoryName(repo.Dir))
+	assert.Equal(t, name, RepositoryName(worktree))
+	assert.Equal(t, name, RepositoryName(filepath.Join(worktree, "docs")))
+	assert.Equal(t, worktree, RepositoryRoot(filepath.Join(worktree, "docs")))
+	assert.Equal(t, feature, HeadCommit(worktree))
+
+	gitCmd, err := NewGitLogCmd(worktree, "")
+	require.NoError(t, err)
+	var names []string
+	for f := range gitCmd.DiffFilesCh() {
+		names = append(names, f.NewName)
+	}
+	require.NoError(t, gitCmd.Wait())
+	sort.Strings(names)
+	assert.Equal(t, []string{"feature.txt", "main.txt"}, names)
+}
+
+func TestGitCmdKill(t *testing.T) {
+	repo := gittest.New(t)
+	for i := 0; i < 50; i++ {
+		repo.WriteFile("file.txt", strings.Repeat("line\n", i+1))
+		repo.Commit("change file")
+	}
+
+	gitCmd, err := NewGitLogCmd(repo.Dir, "")
+	require.NoError(t, err)
+	<-gitCmd.DiffFilesCh()
+	gitCmd.Kill()
+	// the rest of the output is discarded instead of blocking git, which
+	// would keep Wait from returning
+	_ = gitCmd.Wait()
+}
+
+func TestBlame(t *testing.T) {
+	repo := gittest.New(t)
+	repo.WriteFile("config.txt", "a\nb\n")
+	first := repo.Commit("initial")
+	repo.Git("config", "user.name", "refactorer")
+	repo.WriteFile("config.txt", "a\nb\nc\n")
+	second := repo.Commit("add c")
+
+	blame, err := Blame(repo.Dir, "HEAD", "config.txt", 2)
+	require.NoError(t, err)
+	assert.Equal(t, first, blame.Commit)
+	assert.Equal(t, "gitleaks", blame.Author)
+	assert.Equal(t, "gitleaks@example.com", blame.Email)
+	assert.Equal(t, "2023-01-01T00:00:00Z", blame.Date.Format(time.RFC3339))
+
+	blame, err = Blame(repo.Dir, "HEAD", "config.txt", 3)
+	require.NoError(t, err)
+	assert.Equal(t, second, blame.Commit)
+	assert.Equal(t, "refactorer", blame.Author)
+
+	_, err = Blame(repo.Dir, "HEAD", "config.txt", 10)
+	assert.Error(t, err)
+}
+
+func TestVerifyCommitSignature(t *testing.T) {
+	repo := gittest.New(t)
+	repo.WriteFile("config.txt", "a\n")
+	commit := repo.Commit("initial")
+
+	signature, err := VerifyCommitSignature(repo.Dir, commit)
+	require.NoError(t, err)
+	assert.Equal(t, CommitSignature{Status: SignatureNone}, signature)
+
+	_, err = VerifyCommitSignature(repo.Dir, "0000000000000000000000000000000000000000")
+	assert.Error(t, err)
+}
@@ -0,0 +1,144 @@
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// giteaPageLimit is the number of repositories requested per page. It is the
+// default maximum of Gitea servers.
+const giteaPageLimit = 50
+
+// GiteaClient lists repositories on a Gitea or Gogs server.
+type GiteaClient struct {
+	URL   string
+	Token string
+
+	client *http.Client
+}
+
+// giteaRepository is the part of the Gitea and Gogs repository API object
+// needed to clone it.
+type giteaRepository struct {
+	FullName string `json:"full_name"`
+	CloneURL string `json:"clone_url"`
+	// Size is in kilobytes.
+	Size int64 `json:"size"`
+	// UpdatedAt is the closest Gitea has to the time of the last push.
+	UpdatedAt     time.Time `json:"updated_at"`
+	Private       bool      `json:"private"`
+	Internal      bool      `json:"internal"`
+	Archived      bool      `json:"archived"`
+	DefaultBranch string    `json:"default_branch"`
+	Topics        []string  `json:"topics"`
+}
+
+// visibility returns whether repo is public, private or internal.
+func (repo giteaRepository) visibility() string {
+	switch {
+	case repo.Internal:
+		return "internal"
+	case repo.Private:
+		return "private"
+	}
+	return "public"
+}
+
+// NewGiteaClient creates a GiteaClient for the server at baseURL. The token is
+// optional, without it only public repositories are listed.
+func NewGiteaClient(baseURL string, token string) *GiteaClient {
+	return &GiteaClient{
+		URL:    strings.TrimSuffix(baseURL, "/"),
+		Token:  token,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// UserRepositories returns the repositories owned by user.
+func (c *GiteaClient) UserRepositories(user string) ([]ListedRepository, error) {
+	return c.repositories("/api/v1/users/" + url.PathEscape(user) + "/repos")
+}
+
+// OrgRepositories returns the repositories owned by org.
+func (c *GiteaClient) OrgRepositories(org string) ([]ListedRepository, error) {
+	return c.repositories("/api/v1/orgs/" + url.PathEscape(org) + "/repos")
+}
+
+// repositories pages through the repositories at path. Gogs doesn't paginate
+// and returns every repository for each page, so paging stops as soon as a
+// page has nothing new.
+func (c *GiteaClient) repositories(path string) ([]ListedRepository, error) {
+	var listed []ListedRepository
+	seen := make(map[string]bool)
+	for page := 1; ; page++ {
+		repos, err := c.page(path, page)
+		if err != nil {
+			return nil, err
+		}
+		added := 0
+		for _, repo := range repos {
+			if seen[repo.CloneURL] {
+				continue
+			}
+			seen[repo.CloneURL] = true
+			added++
+			listed = append(listed, ListedRepository{
+				Name:          repo.FullName,
+				CloneURL:      c.authenticated(repo.CloneURL),
+				Size:          repo.Size * 1024,
+				PushedAt:      repo.UpdatedAt,
+				Visibility:    repo.visibility(),
+				Topics:        repo.Topics,
+				DefaultBranch: repo.DefaultBranch,
+				Archived:      repo.Archived,
+			})
+		}
+		if added == 0 || len(repos) < giteaPageLimit {
+			return listed, nil
+		}
+	}
+}
+
+// page requests a single page of repositories.
+func (c *GiteaClient) page(path string, page int) ([]giteaRepository, error) {
+	endpoint := fmt.Sprintf("%s%s?page=%d&limit=%d", c.URL, path, page, giteaPageLimit)
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "token "+c.Token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("listing repositories at %s%s failed: %s", c.URL, path, resp.Status)
+	}
+	var repos []giteaRepository
+	if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil {
+		return nil, fmt.Errorf("listing repositories at %s%s: %w", c.URL, path, err)
+	}
+	return repos, nil
+}
+
+// authenticated adds the token to an http clone URL so private repositories
+// can be cloned. Gitea and Gogs accept a token as the username.
+func (c *GiteaClient) authenticated(cloneURL string) string {
+	if c.Token == "" {
+		return cloneURL
+	}
+	u, err := url.Parse(cloneURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return cloneURL
+	}
+	u.User = url.User(c.Token)
+	return u.String()
+}
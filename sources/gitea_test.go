@@ -0,0 +1,79 @@
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGiteaRepositories(t *testing.T) {
+	var server *httptest.Server
+	repos := func(owner string, n int) []giteaRepository {
+		var r []giteaRepository
+		for i := 0; i < n; i++ {
+			r = append(r, giteaRepository{
+				FullName: fmt.Sprintf("%s/repo-%d", owner, i),
+				CloneURL: fmt.Sprintf("%s/%s/repo-%d.git", server.URL, owner, i),
+				Size:     int64(i),
+				Private:  i == 1,
+				Archived: i == 2,
+				Topics:   []string{"infra"},
+			})
+		}
+		return r
+	}
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		switch r.URL.Path {
+		case "/api/v1/users/alice/repos":
+			// gitea pages, 60 repositories take two pages
+			all := repos("alice", 60)
+			end := page * giteaPageLimit
+			if end > len(all) {
+				end = len(all)
+			}
+			_ = json.NewEncoder(w).Encode(all[(page-1)*giteaPageLimit : end])
+		case "/api/v1/orgs/infra/repos":
+			// gogs ignores the page and returns everything every time
+			if r.Header.Get("Authorization") != "token s3cret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(repos("infra", 70))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewGiteaClient(server.URL+"/", "")
+	userRepos, err := client.UserRepositories("alice")
+	require.NoError(t, err)
+	assert.Len(t, userRepos, 60)
+	assert.Equal(t, server.URL+"/alice/repo-59.git", userRepos[59].CloneURL)
+	assert.Equal(t, "alice/repo-59", userRepos[59].Name)
+	assert.Equal(t, int64(59*1024), userRepos[59].Size)
+	assert.Equal(t, "public", userRepos[0].Visibility)
+	assert.Equal(t, "private", userRepos[1].Visibility)
+	assert.True(t, userRepos[2].Archived)
+	assert.Equal(t, []string{"infra"}, userRepos[0].Topics)
+
+	_, err = client.OrgRepositories("infra")
+	assert.Error(t, err)
+
+	client = NewGiteaClient(server.URL, "s3cret")
+	orgRepos, err := client.OrgRepositories("infra")
+	require.NoError(t, err)
+	assert.Len(t, orgRepos, 70)
+	assert.Equal(t, "http://s3cret@"+server.Listener.Addr().String()+"/infra/repo-0.git", orgRepos[0].CloneURL)
+	assert.Equal(t, server.URL+"/infra/repo-0.git", RedactURL(orgRepos[0].CloneURL))
+
+	_, err = client.UserRepositories("nobody")
+	assert.Error(t, err)
+}
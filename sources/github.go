@@ -0,0 +1,280 @@
+package sources
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// githubPageLimit is the number of repositories requested per page, the
+// maximum the GitHub GraphQL API allows.
+const githubPageLimit = 100
+
+// githubRepositoriesQuery lists a page of the repositories of an
+// organization with everything needed to decide whether to scan them.
+const githubRepositoriesQuery = `query($org: String!, $cursor: String) {
+  organization(login: $org) {
+    repositories(first: 100, after: $cursor, orderBy: {field: PUSHED_AT, direction: DESC}) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        nameWithOwner url sshUrl isArchived isFork pushedAt diskUsage visibility hasWikiEnabled
+        defaultBranchRef { name }
+        repositoryTopics(first: 20) { nodes { topic { name } } }
+      }
+    }
+  }
+}`
+
+// GitHubClient lists repositories on GitHub or a GitHub Enterprise Server.
+type GitHubClient struct {
+	// URL is the GraphQL API URL and APIURL the REST API URL.
+	URL    string
+	APIURL string
+	Token  string
+
+	client *http.Client
+}
+
+// GitHubRepository is a repository listed by a GitHubClient.
+type GitHubRepository struct {
+	Name       string    `json:"nameWithOwner"`
+	URL        string    `json:"url"`
+	SSHURL     string    `json:"sshUrl"`
+	IsArchived bool      `json:"isArchived"`
+	IsFork     bool      `json:"isFork"`
+	PushedAt   time.Time `json:"pushedAt"`
+	// DiskUsage is in kilobytes.
+	DiskUsage int64 `json:"diskUsage"`
+	// Visibility is PUBLIC, PRIVATE or INTERNAL.
+	Visibility       string `json:"visibility"`
+	HasWikiEnabled   bool   `json:"hasWikiEnabled"`
+	DefaultBranchRef *struct {
+		Name string `json:"name"`
+	} `json:"defaultBranchRef"`
+	RepositoryTopics struct {
+		Nodes []struct {
+			Topic struct {
+				Name string `json:"name"`
+			} `json:"topic"`
+		} `json:"nodes"`
+	} `json:"repositoryTopics"`
+}
+
+// DefaultBranch returns the name of the default branch of repo, empty for
+// empty repositories.
+func (repo GitHubRepository) DefaultBranch() string {
+	if repo.DefaultBranchRef == nil {
+		return ""
+	}
+	return repo.DefaultBranchRef.Name
+}
+
+// Topics returns the names of the topics of repo.
+func (repo GitHubRepository) Topics() []string {
+	var topics []string
+	for _, node := range repo.RepositoryTopics.Nodes {
+		topics = append(topics, node.Topic.Name)
+	}
+	return topics
+}
+
+// NewGitHubClient creates a GitHubClient for the REST API at apiURL,
+// https://api.github.com or https://<host>/api/v3 for GitHub Enterprise
+// Server. The GraphQL API requires a token.
+func NewGitHubClient(apiURL string, token string) *GitHubClient {
+	apiURL = strings.TrimSuffix(apiURL, "/")
+	graphqlURL := apiURL + "/graphql"
+	if strings.HasSuffix(apiURL, "/api/v3") {
+		graphqlURL = strings.TrimSuffix(apiURL, "/v3") + "/graphql"
+	}
+	return &GitHubClient{
+		URL:    graphqlURL,
+		APIURL: apiURL,
+		Token:  token,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// OrgRepositories returns the repositories of org, most recently pushed
+// first. A single query per hundred repositories returns everything needed
+// to filter them, unlike the REST API which needs a request per page and
+// repository detail.
+func (c *GitHubClient) OrgRepositories(org string) ([]GitHubRepository, error) {
+	var repos []GitHubRepository
+	var cursor *string
+	for {
+		var data struct {
+			Organization *struct {
+				Repositories struct {
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+					Nodes []GitHubRepository `json:"nodes"`
+				} `json:"repositories"`
+			} `json:"organization"`
+		}
+		variables := map[string]interface{}{"org": org, "cursor": cursor}
+		if err := c.query(githubRepositoriesQuery, variables, &data); err != nil {
+			return nil, fmt.Errorf("listing repositories of github org %s: %w", org, err)
+		}
+		if data.Organization == nil {
+			return nil, fmt.Errorf("github org %s not found", org)
+		}
+		page := data.Organization.Repositories
+		repos = append(repos, page.Nodes...)
+		if !page.PageInfo.HasNextPage {
+			return repos, nil
+		}
+		endCursor := page.PageInfo.EndCursor
+		cursor = &endCursor
+	}
+}
+
+// query runs a GraphQL query and decodes its data into v.
+func (c *GitHubClient) query(query string, variables map[string]interface{}, v interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "bearer "+c.Token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", c.URL, resp.Status)
+	}
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("%s", result.Errors[0].Message)
+	}
+	return json.Unmarshal(result.Data, v)
+}
+
+// CloneURL returns the URL repo is cloned from. Over https the token is
+// added so private repositories can be cloned, over ssh the keys of the
+// ssh agent are used.
+func (c *GitHubClient) CloneURL(repo GitHubRepository, ssh bool) string {
+	if ssh {
+		return repo.SSHURL
+	}
+	u, err := url.Parse(repo.URL + ".git")
+	if err != nil {
+		return repo.URL
+	}
+	if c.Token != "" {
+		u.User = url.UserPassword("x-access-token", c.Token)
+	}
+	return u.String()
+}
+
+// WikiCloneURL returns the URL the wiki of repo is cloned from, like
+// CloneURL. The wiki repository only exists once a page has been written.
+func (c *GitHubClient) WikiCloneURL(repo GitHubRepository, ssh bool) string {
+	if ssh {
+		return strings.TrimSuffix(repo.SSHURL, ".git") + ".wiki.git"
+	}
+	wiki := repo
+	wiki.URL += ".wiki"
+	return c.CloneURL(wiki, false)
+}
+
+// Discussion is text written on a hosting platform rather than committed, the
+// description of an issue or pull request or a comment on one. Tokens are
+// pasted into them all the time and they are outside the history of the
+// repository.
+type Discussion struct {
+	// URL links to the discussion and names it in findings.
+	URL    string
+	Author string
+	Date   time.Time
+	Body   string
+}
+
+// githubDiscussion is an issue, pull request or comment as the REST API
+// returns them.
+type githubDiscussion struct {
+	HTMLURL string `json:"html_url"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	User    struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Discussions returns the issues and pull requests of repo, in the
+// owner/name form, with their comments and review comments.
+func (c *GitHubClient) Discussions(repo string) ([]Discussion, error) {
+	var discussions []Discussion
+	// the issues of a repository include its pull requests and the issue
+	// comments the conversation on them, only the review comments on the
+	// diffs of pull requests are listed separately
+	for _, endpoint := range []string{"issues?state=all&", "issues/comments?", "pulls/comments?"} {
+		for page := 1; ; page++ {
+			var items []githubDiscussion
+			endpoint := fmt.Sprintf("%s/repos/%s/%sper_page=%d&page=%d", c.APIURL, repo, endpoint, githubPageLimit, page)
+			if err := c.get(endpoint, &items); err != nil {
+				return nil, fmt.Errorf("listing discussions of github repository %s: %w", repo, err)
+			}
+			for _, item := range items {
+				body := item.Body
+				if item.Title != "" {
+					body = item.Title + "\n" + body
+				}
+				discussions = append(discussions, Discussion{
+					URL:    item.HTMLURL,
+					Author: item.User.Login,
+					Date:   item.CreatedAt,
+					Body:   body,
+				})
+			}
+			if len(items) < githubPageLimit {
+				break
+			}
+		}
+	}
+	return discussions, nil
+}
+
+// get requests a REST API endpoint and decodes its response into v.
+func (c *GitHubClient) get(endpoint string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", endpoint, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,171 @@
+package sources
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// gitignorePattern is a single pattern from a .gitignore file.
+type gitignorePattern struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// gitignore contains the patterns of a .gitignore file. Patterns are matched
+// against paths relative to the directory containing the .gitignore file.
+type gitignore struct {
+	dir      string
+	patterns []gitignorePattern
+}
+
+// loadGitignore reads the .gitignore file in dir. A nil gitignore is returned
+// if the directory does not contain one.
+func loadGitignore(dir string) (*gitignore, error) {
+	f, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	g := &gitignore{dir: dir}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if p, ok := parseGitignorePattern(scanner.Text()); ok {
+			g.patterns = append(g.patterns, p)
+		}
+	}
+	return g, scanner.Err()
+}
+
+// parseGitignorePattern converts a line of a .gitignore file into a regular
+// expression following the rules in https://git-scm.com/docs/gitignore.
+func parseGitignorePattern(line string) (gitignorePattern, bool) {
+	var p gitignorePattern
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return p, false
+	}
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	}
+	line = strings.TrimPrefix(line, "\\")
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	// patterns containing a slash are relative to the .gitignore file,
+	// otherwise they match at any depth
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return p, false
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case strings.HasPrefix(line[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(line[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(line[i:], ']')
+			if end == -1 {
+				sb.WriteString(regexp.QuoteMeta(string(c)))
+				continue
+			}
+			class := line[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return p, false
+	}
+	p.re = re
+	return p, true
+}
+
+// matches returns true if the pattern matches the slash separated path or
+// one of its parent directories.
+func (p gitignorePattern) matches(path string) bool {
+	if !p.dirOnly && p.re.MatchString(path) {
+		return true
+	}
+	for dir := parentDir(path); dir != ""; dir = parentDir(dir) {
+		if p.re.MatchString(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func parentDir(path string) string {
+	i := strings.LastIndex(path, "/")
+	if i == -1 {
+		return ""
+	}
+	return path[:i]
+}
+
+// match returns whether path is ignored and whether any pattern matched at
+// all, since a negated pattern can un-ignore a path ignored by a parent
+// .gitignore.
+func (g *gitignore) match(path string, isDir bool) (ignored bool, matched bool) {
+	rel, err := filepath.Rel(g.dir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false, false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, p := range g.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.re.MatchString(rel) {
+			ignored = !p.negate
+			matched = true
+		}
+	}
+	return ignored, matched
+}
+
+// gitignores is the set of .gitignore files found while walking a directory.
+type gitignores []*gitignore
+
+// ignored returns true if the path is ignored. .gitignore files deeper in the
+// tree take precedence over those closer to the root.
+func (gs gitignores) ignored(path string, isDir bool) bool {
+	ignored := false
+	for _, g := range gs {
+		if i, matched := g.match(path, isDir); matched {
+			ignored = i
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,83 @@
+package sources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatih/semgroup"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitignorePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{pattern: "*.log", path: "debug.log", ignored: true},
+		{pattern: "*.log", path: "a/b/debug.log", ignored: true},
+		{pattern: "*.log", path: "debug.txt", ignored: false},
+		{pattern: "/build", path: "build", isDir: true, ignored: true},
+		{pattern: "/build", path: "a/build", isDir: true, ignored: false},
+		{pattern: "out/", path: "out", isDir: true, ignored: true},
+		{pattern: "out/", path: "out", isDir: false, ignored: false},
+		{pattern: "docs/**/*.pdf", path: "docs/a/b/c.pdf", ignored: true},
+		{pattern: "docs/**/*.pdf", path: "docs/c.pdf", ignored: true},
+		{pattern: "**/secrets", path: "a/secrets", isDir: true, ignored: true},
+		{pattern: "file[0-9].txt", path: "file1.txt", ignored: true},
+		{pattern: "file[!0-9].txt", path: "file1.txt", ignored: false},
+	}
+	for _, tt := range tests {
+		p, ok := parseGitignorePattern(tt.pattern)
+		require.True(t, ok, tt.pattern)
+		g := &gitignore{dir: "root", patterns: []gitignorePattern{p}}
+		ignored, _ := g.match(filepath.Join("root", filepath.FromSlash(tt.path)), tt.isDir)
+		assert.Equal(t, tt.ignored, ignored, "%s %s", tt.pattern, tt.path)
+	}
+}
+
+func TestDirectoryTargetsWithOptions(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".gitignore":              "*.log\n!keep.log\nbuild/\n",
+		"main.go":                 "package main",
+		"debug.log":               "ignored",
+		"keep.log":                "not ignored",
+		"build/out.txt":           "ignored",
+		"sub/.gitignore":          "local.txt\n",
+		"sub/local.txt":           "ignored",
+		"sub/other.txt":           "not ignored",
+		"node_modules/pkg/a.js":   "vendored",
+		"vendor/github.com/a.go":  "vendored",
+		"internal/vendor.go":      "not vendored",
+		"internal/dist/bundle.js": "vendored",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+
+	collect := func(opts DirectoryOptions) []string {
+		s := semgroup.NewGroup(context.Background(), 4)
+		paths, err := DirectoryTargetsWithOptions(dir, s, opts)
+		require.NoError(t, err)
+		var found []string
+		for p := range paths {
+			rel, _ := filepath.Rel(dir, p.Path)
+			found = append(found, filepath.ToSlash(rel))
+		}
+		require.NoError(t, s.Wait())
+		return found
+	}
+
+	assert.ElementsMatch(t, []string{
+		".gitignore", "main.go", "keep.log", "sub/.gitignore", "sub/other.txt", "internal/vendor.go",
+	}, collect(DirectoryOptions{RespectGitignore: true, SkipVendored: true}))
+
+	assert.Len(t, collect(DirectoryOptions{}), len(files))
+}
@@ -0,0 +1,173 @@
+// Package gittest builds small git repositories for tests so the behavior of
+// git scans, like deleted lines, root commits, merges and binary files, can
+// be covered without checking repositories into testdata.
+package gittest
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// epoch is the date of the first commit. Every commit is one minute after
+// the previous one so commit hashes are the same on every run.
+var epoch = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Repo is a git repository in a temporary directory.
+type Repo struct {
+	// Dir is the root of the working tree.
+	Dir string
+
+	t       testing.TB
+	commits int
+}
+
+// New creates an empty repository on branch main. The test is skipped if git
+// is not installed.
+func New(t testing.TB) *Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	r := &Repo{Dir: t.TempDir(), t: t}
+	r.Git("init", "--quiet")
+	r.Git("symbolic-ref", "HEAD", "refs/heads/main")
+	r.Git("config", "user.name", "gitleaks")
+	r.Git("config", "user.email", "gitleaks@example.com")
+	r.Git("config", "commit.gpgsign", "false")
+	return r
+}
+
+// Git runs git in the repository and returns its trimmed output. The test
+// fails if git exits with an error.
+func (r *Repo) Git(args ...string) string {
+	r.t.Helper()
+	date := epoch.Add(time.Duration(r.commits) * time.Minute).Format(time.RFC3339)
+	cmd := exec.Command("git", append([]string{"-C", r.Dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_DATE="+date,
+		"GIT_CONFIG_NOSYSTEM=1",
+		"HOME="+r.Dir,
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// WriteFile writes content to path, relative to the root of the repository,
+// creating parent directories as needed.
+func (r *Repo) WriteFile(path string, content string) {
+	r.t.Helper()
+	r.write(path, []byte(content))
+}
+
+// WriteBinary writes binary content to path.
+func (r *Repo) WriteBinary(path string, content []byte) {
+	r.t.Helper()
+	r.write(path, content)
+}
+
+func (r *Repo) write(path string, content []byte) {
+	r.t.Helper()
+	full := filepath.Join(r.Dir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(full, content, 0o644); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// Symlink creates a symbolic link at path pointing to target.
+func (r *Repo) Symlink(path string, target string) {
+	r.t.Helper()
+	full := filepath.Join(r.Dir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.Symlink(target, full); err != nil {
+		r.t.Skipf("symlinks are not supported: %v", err)
+	}
+}
+
+// Submodule adds a submodule gitlink at path pointing to commit without
+// cloning anything.
+func (r *Repo) Submodule(path string, commit string) {
+	r.t.Helper()
+	// an empty directory is an uninitialized submodule, without it the next
+	// commit would delete the gitlink
+	if err := os.MkdirAll(filepath.Join(r.Dir, filepath.FromSlash(path)), 0o755); err != nil {
+		r.t.Fatal(err)
+	}
+	r.Git("update-index", "--add", "--cacheinfo", "160000,"+commit+","+path)
+}
+
+// Remove deletes path from the working tree.
+func (r *Repo) Remove(path string) {
+	r.t.Helper()
+	if err := os.Remove(filepath.Join(r.Dir, filepath.FromSlash(path))); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// Commit stages every change and commits it, returning the commit hash.
+func (r *Repo) Commit(message string) string {
+	r.t.Helper()
+	r.Git("add", "--all")
+	r.Git("commit", "--quiet", "--allow-empty", "-m", message)
+	r.commits++
+	return r.Git("rev-parse", "HEAD")
+}
+
+// Branch creates a branch at HEAD and checks it out.
+func (r *Repo) Branch(name string) {
+	r.t.Helper()
+	r.Git("checkout", "--quiet", "-b", name)
+}
+
+// Checkout checks out an existing branch or commit.
+func (r *Repo) Checkout(ref string) {
+	r.t.Helper()
+	r.Git("checkout", "--quiet", ref)
+}
+
+// Merge merges branch into the current branch with a merge commit and
+// returns its hash.
+func (r *Repo) Merge(branch string, message string) string {
+	r.t.Helper()
+	r.Git("merge", "--quiet", "--no-ff", "-m", message, branch)
+	r.commits++
+	return r.Git("rev-parse", "HEAD")
+}
+
+// Orphan creates a branch without any history and checks it out. The working
+// tree is emptied so the first commit on it only holds what is written after.
+func (r *Repo) Orphan(name string) {
+	r.t.Helper()
+	r.Git("checkout", "--quiet", "--orphan", name)
+	r.Git("rm", "-r", "--quiet", "--cached", "--ignore-unmatch", ".")
+	r.Git("clean", "--quiet", "-d", "--force")
+}
+
+// Octopus merges branches into the current branch with a single merge commit
+// and returns its hash.
+func (r *Repo) Octopus(message string, branches ...string) string {
+	r.t.Helper()
+	r.Git(append([]string{"merge", "--quiet", "--no-ff", "-m", message}, branches...)...)
+	r.commits++
+	return r.Git("rev-parse", "HEAD")
+}
+
+// Worktree checks out branch in a new linked worktree at dir. Its .git is a
+// file pointing into the repository.
+func (r *Repo) Worktree(dir string, branch string) {
+	r.t.Helper()
+	r.Git("worktree", "add", "--quiet", dir, branch)
+}
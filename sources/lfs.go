@@ -0,0 +1,68 @@
+package sources
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// lfsOIDPattern matches the object id of a Git LFS pointer.
+var lfsOIDPattern = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// LFSPointer is a Git LFS pointer file, which is committed in place of a
+// large file kept on an LFS server.
+type LFSPointer struct {
+	OID  string
+	Size int64
+}
+
+// ParseLFSPointer returns the pointer in text, the content of a pointer file
+// or the lines a diff adds to one, false if text isn't one.
+func ParseLFSPointer(text string) (LFSPointer, bool) {
+	var pointer LFSPointer
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			return LFSPointer{}, false
+		}
+		switch {
+		case key == "version":
+		case key == "oid" && lfsOIDPattern.MatchString(value):
+			pointer.OID = value
+		case key == "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return LFSPointer{}, false
+			}
+			pointer.Size = size
+		case strings.HasPrefix(key, "ext-"):
+		default:
+			return LFSPointer{}, false
+		}
+	}
+	return pointer, pointer.OID != ""
+}
+
+// String returns the pointer file of p.
+func (p LFSPointer) String() string {
+	return fmt.Sprintf("version https://git-lfs.github.com/spec/v1\noid %s\nsize %d\n", p.OID, p.Size)
+}
+
+// LFSObject returns the content of the large file pointer stands in for in
+// the repository at source. It is taken from the repository's LFS storage,
+// or downloaded from its LFS server, by git-lfs, which must be installed.
+func LFSObject(source string, pointer LFSPointer) ([]byte, error) {
+	cmd := exec.Command("git", "-C", filepath.Clean(source), "lfs", "smudge")
+	cmd.Stdin = strings.NewReader(pointer.String())
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("fetching LFS object %s: %w: %s", pointer.OID, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,61 @@
+package sources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLFSPointer(t *testing.T) {
+	const oid = "sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393"
+	tests := []struct {
+		name    string
+		text    string
+		pointer LFSPointer
+		ok      bool
+	}{
+		{
+			name:    "pointer file",
+			text:    "version https://git-lfs.github.com/spec/v1\noid " + oid + "\nsize 12345\n",
+			pointer: LFSPointer{OID: oid, Size: 12345},
+			ok:      true,
+		},
+		{
+			// a diff of a changed pointer only adds the oid and size
+			name:    "changed lines",
+			text:    "oid " + oid + "\nsize 42\n",
+			pointer: LFSPointer{OID: oid, Size: 42},
+			ok:      true,
+		},
+		{
+			name:    "extension",
+			text:    "version https://git-lfs.github.com/spec/v1\next-0-foo sha256:abc\noid " + oid + "\nsize 1\n",
+			pointer: LFSPointer{OID: oid, Size: 1},
+			ok:      true,
+		},
+		{
+			name: "size only",
+			text: "size 42\n",
+		},
+		{
+			name: "other content",
+			text: "oid " + oid + "\nsize 42\npassword = hunter2\n",
+		},
+		{
+			name: "invalid oid",
+			text: "oid sha256:xyz\nsize 42\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pointer, ok := ParseLFSPointer(tt.text)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.pointer, pointer)
+			}
+		})
+	}
+
+	pointer, _ := ParseLFSPointer("oid " + oid + "\nsize 42\n")
+	assert.Equal(t, "version https://git-lfs.github.com/spec/v1\noid "+oid+"\nsize 42\n", pointer.String())
+}
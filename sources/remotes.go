@@ -0,0 +1,88 @@
+package sources
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// publicRemoteTimeout limits how long checking whether a remote can be read
+// anonymously takes.
+const publicRemoteTimeout = 30 * time.Second
+
+// Remote is a remote configured in a repository.
+type Remote struct {
+	Name string
+	URL  string
+}
+
+// Remotes returns the remotes of the repository at source with the URLs
+// they are fetched from.
+func Remotes(source string) ([]Remote, error) {
+	out, err := exec.Command("git", "-C", filepath.Clean(source), "remote", "-v").Output()
+	if err != nil {
+		return nil, err
+	}
+	var remotes []Remote
+	for _, line := range strings.Split(string(out), "\n") {
+		// origin	https://github.com/acme/api.git (fetch)
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[2] == "(fetch)" {
+			remotes = append(remotes, Remote{Name: fields[0], URL: fields[1]})
+		}
+	}
+	return remotes, nil
+}
+
+// PublicRemote reports whether the repository at a remote URL can be read
+// by anyone, by listing its branches without credentials. ssh URLs are
+// checked over https on the same host, local paths are never public.
+func PublicRemote(remote string) bool {
+	anonymous := anonymousURL(remote)
+	if anonymous == "" {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), publicRemoteTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", "-c", "credential.helper=", "-c", "core.askPass=", "ls-remote", "--heads", anonymous)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=")
+	return cmd.Run() == nil
+}
+
+// anonymousURL returns the https URL of a remote without credentials, or an
+// empty string for remotes that aren't hosted.
+func anonymousURL(remote string) string {
+	if scpLikeURLPattern.MatchString(remote) && !strings.Contains(remote, "://") {
+		// git@github.com:acme/api.git
+		host := remote[strings.Index(remote, "@")+1 : strings.Index(remote, ":")]
+		return "https://" + host + "/" + remote[strings.Index(remote, ":")+1:]
+	}
+	u, err := url.Parse(remote)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	switch u.Scheme {
+	case "https", "http":
+	case "ssh", "git":
+		u.Scheme = "https"
+		u.Host = u.Hostname()
+	default:
+		return ""
+	}
+	u.User = nil
+	return u.String()
+}
+
+// RemoteBranchesContaining returns the remote-tracking branches of the
+// repository at source that contain commit, ex: origin/main.
+func RemoteBranchesContaining(source string, commit string) ([]string, error) {
+	out, err := exec.Command("git", "-C", filepath.Clean(source), "branch", "-r", "--contains", commit, "--format=%(refname:short)").Output()
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(out)), nil
+}
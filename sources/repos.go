@@ -0,0 +1,173 @@
+package sources
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ListedRepository is a repository listed on a hosting platform. Size and
+// PushedAt are zero when the platform doesn't report them.
+type ListedRepository struct {
+	Name     string
+	CloneURL string
+	// Size is the size of the repository on the platform in bytes.
+	Size     int64
+	PushedAt time.Time
+
+	// Visibility (public, private or internal), Topics, DefaultBranch and
+	// Archived describe the repository as the platform does.
+	Visibility    string
+	Topics        []string
+	DefaultBranch string
+	Archived      bool
+
+	// Wiki marks the wiki of a repository, which can't be cloned until its
+	// first page is written.
+	Wiki bool
+	// Discussions lists the issues, pull requests and comments of the
+	// repository to scan along with its history, it is nil when they
+	// aren't scanned.
+	Discussions func() ([]Discussion, error)
+}
+
+// scpLikeURLPattern matches the scp-like syntax git accepts for ssh remotes,
+// e.g. git@github.com:owner/repo.git.
+var scpLikeURLPattern = regexp.MustCompile(`^[\w.-]+@[\w.-]+:[^/\\]`)
+
+// RemoteRepository returns true if repo is a URL git can clone rather than a
+// path on disk.
+func RemoteRepository(repo string) bool {
+	return strings.Contains(repo, "://") || scpLikeURLPattern.MatchString(repo)
+}
+
+// RemoteRepositoryName returns the name of the repository at a URL, the last
+// element of its path without the .git suffix.
+func RemoteRepositoryName(url string) string {
+	url = strings.TrimSuffix(strings.TrimRight(url, "/"), ".git")
+	if i := strings.LastIndexAny(url, "/:"); i != -1 {
+		url = url[i+1:]
+	}
+	return path.Clean(url)
+}
+
+// CloneRepository makes a bare clone of the repository at url in dir. A bare
+// clone has the full history without the cost of checking out files. With a
+// filter, e.g. blob:limit=1m or blob:none, a partial clone is made: the
+// history is cloned right away and the blobs it leaves out are only
+// downloaded when a scan needs them.
+func CloneRepository(url string, dir string, filter string) error {
+	args := []string{"clone", "--quiet", "--bare"}
+	if filter != "" {
+		args = append(args, "--filter="+filter)
+	}
+	cmd := exec.Command("git", append(args, url, dir)...)
+	log.Debug().Msgf("cloning %s into %s", RedactURL(url), dir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("unable to clone %s: %w: %s", RedactURL(url), err,
+			strings.ReplaceAll(strings.TrimSpace(string(out)), url, RedactURL(url)))
+	}
+	return nil
+}
+
+// FetchRepository updates the bare clone of url in dir, cloning it if dir
+// doesn't exist yet. Branches and tags are mirrored so deleted branches
+// don't linger in later scans. A partial clone is fetched from its origin,
+// which git requires to filter the fetch and download missing blobs later.
+func FetchRepository(url string, dir string, filter string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return CloneRepository(url, dir, filter)
+	}
+	args := []string{"-C", dir, "fetch", "--quiet", "--prune", "--force"}
+	remote := url
+	if filter != "" {
+		// the url may carry a new token
+		if out, err := exec.Command("git", "-C", dir, "remote", "set-url", "origin", url).CombinedOutput(); err != nil {
+			return fmt.Errorf("unable to fetch %s: %w: %s", RedactURL(url), err,
+				strings.ReplaceAll(strings.TrimSpace(string(out)), url, RedactURL(url)))
+		}
+		args = append(args, "--filter="+filter)
+		remote = "origin"
+	}
+	cmd := exec.Command("git", append(args, remote, "+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*")...)
+	log.Debug().Msgf("fetching %s into %s", RedactURL(url), dir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("unable to fetch %s: %w: %s", RedactURL(url), err,
+			strings.ReplaceAll(strings.TrimSpace(string(out)), url, RedactURL(url)))
+	}
+	return nil
+}
+
+// Retry is how failed clones and fetches are retried, network resets and ssh
+// hiccups are common when cloning many repositories.
+type Retry struct {
+	// Attempts is how often cloning is tried before giving up, waiting
+	// Backoff after the first failure and twice as long after every other.
+	Attempts int
+	Backoff  time.Duration
+
+	// Sleep waits between attempts, time.Sleep if it is nil.
+	Sleep func(time.Duration)
+}
+
+// Do calls clone for the repository at url until it succeeds or has been
+// called Attempts times, at least once. It returns the number of attempts
+// made and the error of the last one.
+func (r Retry) Do(url string, clone func() error) (int, error) {
+	sleep := r.Sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	backoff := r.Backoff
+	for attempt := 1; ; attempt++ {
+		err := clone()
+		if err == nil {
+			if attempt > 1 {
+				log.Info().Msgf("%s: cloned after %d attempts", RedactURL(url), attempt)
+			}
+			return attempt, nil
+		}
+		if attempt >= r.Attempts {
+			return attempt, err
+		}
+		log.Warn().Err(err).Msgf("%s: attempt %d of %d failed, retrying in %s", RedactURL(url), attempt, r.Attempts, backoff)
+		sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// RepositoryCacheName returns the directory name a clone of url is kept
+// under. The hash keeps repositories with the same name apart.
+func RepositoryCacheName(url string) string {
+	sum := sha256.Sum256([]byte(RedactURL(url)))
+	return fmt.Sprintf("%s-%x.git", RemoteRepositoryName(url), sum[:6])
+}
+
+// ReadRepositoryList reads a file with one repository path or URL per line.
+// Blank lines and lines starting with # are skipped.
+func ReadRepositoryList(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var repos []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		repos = append(repos, line)
+	}
+	return repos, scanner.Err()
+}
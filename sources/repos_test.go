@@ -0,0 +1,147 @@
+package sources
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zricethezav/gitleaks/v8/sources/gittest"
+)
+
+func TestRemoteRepository(t *testing.T) {
+	tests := []struct {
+		repo   string
+		remote bool
+		name   string
+	}{
+		{repo: "https://github.com/gitleaks/gitleaks.git", remote: true, name: "gitleaks"},
+		{repo: "https://gitlab.example.com/group/sub/project/", remote: true, name: "project"},
+		{repo: "git@github.com:gitleaks/gitleaks.git", remote: true, name: "gitleaks"},
+		{repo: "ssh://git@example.com:2222/repo", remote: true, name: "repo"},
+		{repo: "file:///srv/git/local.git", remote: true, name: "local"},
+		{repo: "../checkouts/project", remote: false},
+		{repo: "/home/user/repo", remote: false},
+		{repo: `C:\src\repo`, remote: false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.remote, RemoteRepository(tt.repo), tt.repo)
+		if tt.remote {
+			assert.Equal(t, tt.name, RemoteRepositoryName(tt.repo), tt.repo)
+		}
+	}
+}
+
+func TestReadRepositoryList(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "repos.txt")
+	content := "# fleet\nhttps://example.com/a.git\n\n  ../b  \n# https://example.com/skipped.git\n"
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o644))
+
+	repos, err := ReadRepositoryList(file)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com/a.git", "../b"}, repos)
+}
+
+func TestCloneRepository(t *testing.T) {
+	repo := gittest.New(t)
+	repo.WriteFile("main.go", "package main")
+	sha := repo.Commit("initial")
+
+	dir := filepath.Join(t.TempDir(), "clone")
+	require.NoError(t, CloneRepository("file://"+filepath.ToSlash(repo.Dir), dir, ""))
+	content, err := FileAtRef(dir, sha, "main.go")
+	require.NoError(t, err)
+	assert.Equal(t, "package main", content)
+	assert.Equal(t, "file://"+filepath.ToSlash(repo.Dir), RemoteURL(dir))
+
+	assert.Error(t, CloneRepository("file:///does/not/exist", filepath.Join(t.TempDir(), "missing"), ""))
+}
+
+func TestPartialClone(t *testing.T) {
+	remote := gittest.New(t)
+	remote.Git("config", "uploadpack.allowFilter", "true")
+	remote.WriteFile("small.txt", "small")
+	remote.WriteFile("large.txt", strings.Repeat("large ", 1000))
+	remote.Commit("first")
+
+	url := "file://" + filepath.ToSlash(remote.Dir)
+	dir := filepath.Join(t.TempDir(), "clone")
+	require.NoError(t, CloneRepository(url, dir, "blob:limit=1k"))
+	missing := func() string {
+		out, err := exec.Command("git", "-C", dir, "rev-list", "--objects", "--all", "--missing=print").Output()
+		require.NoError(t, err)
+		return string(out)
+	}
+	assert.Contains(t, missing(), "?")
+
+	remote.WriteFile("other.txt", strings.Repeat("other ", 1000))
+	remote.Commit("second")
+	require.NoError(t, FetchRepository(url, dir, "blob:limit=1k"))
+
+	// scanning downloads the missing blobs
+	gitCmd, err := NewGitLogCmd(dir, "--all")
+	require.NoError(t, err)
+	var files []string
+	for f := range gitCmd.DiffFilesCh() {
+		files = append(files, f.NewName)
+	}
+	require.NoError(t, gitCmd.Wait())
+	assert.ElementsMatch(t, []string{"small.txt", "large.txt", "other.txt"}, files)
+	assert.NotContains(t, missing(), "?")
+}
+
+func TestRetry(t *testing.T) {
+	var sleeps []time.Duration
+	retry := Retry{Attempts: 4, Backoff: time.Second, Sleep: func(d time.Duration) { sleeps = append(sleeps, d) }}
+	cloneFailing := func(failures int) (func() error, *int) {
+		calls := 0
+		return func() error {
+			calls++
+			if calls <= failures {
+				return errors.New("connection reset")
+			}
+			return nil
+		}, &calls
+	}
+
+	clone, calls := cloneFailing(0)
+	attempts, err := retry.Do("https://example.com/a.git", clone)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, 1, *calls)
+	assert.Empty(t, sleeps)
+
+	clone, calls = cloneFailing(2)
+	attempts, err = retry.Do("https://example.com/a.git", clone)
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, 3, *calls)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, sleeps)
+
+	// the backoff doubles after every attempt and there is no wait after the
+	// last one
+	sleeps = nil
+	clone, calls = cloneFailing(10)
+	attempts, err = retry.Do("https://example.com/a.git", clone)
+	require.Error(t, err)
+	assert.Equal(t, "connection reset", err.Error())
+	assert.Equal(t, 4, attempts)
+	assert.Equal(t, 4, *calls)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}, sleeps)
+
+	// cloning is attempted at least once
+	sleeps = nil
+	retry.Attempts = 0
+	clone, calls = cloneFailing(10)
+	attempts, err = retry.Do("https://example.com/a.git", clone)
+	require.Error(t, err)
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, 1, *calls)
+	assert.Empty(t, sleeps)
+}
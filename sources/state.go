@@ -0,0 +1,105 @@
+package sources
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RepositoryState is what is remembered about a repository between scans.
+type RepositoryState struct {
+	// Heads are the commits the refs of the repository pointed to when it
+	// was last scanned. Everything reachable from them has been scanned.
+	Heads    []string
+	LastScan time.Time
+}
+
+// ScanState records the state of every repository scanned by a long running
+// scan so each run only scans the commits added since the previous one.
+type ScanState struct {
+	Repositories map[string]RepositoryState
+}
+
+// LoadScanState reads the scan state saved at path. An empty state is
+// returned if there is no file at path yet.
+func LoadScanState(path string) (*ScanState, error) {
+	state := &ScanState{Repositories: make(map[string]RepositoryState)}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, err
+	}
+	if state.Repositories == nil {
+		state.Repositories = make(map[string]RepositoryState)
+	}
+	return state, nil
+}
+
+// Save writes the state to path. The file is replaced atomically so a crash
+// can't leave a truncated state behind.
+func (s *ScanState) Save(path string) error {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// NewCommitsLogOpts returns the git log options that select the commits of the
+// repository at source that aren't reachable from the heads of a previous
+// scan. Heads that no longer exist, e.g. because a rewritten branch was
+// garbage collected, are left out.
+func NewCommitsLogOpts(source string, heads []string) string {
+	logOpts := "--all"
+	var existing []string
+	for _, head := range heads {
+		err := exec.Command("git", "-C", filepath.Clean(source), "cat-file", "-e", head+"^{commit}").Run()
+		if err == nil {
+			existing = append(existing, head)
+		}
+	}
+	if len(existing) > 0 {
+		logOpts += " --not " + strings.Join(existing, " ")
+	}
+	return logOpts
+}
+
+// RepositoryHeads returns the sorted, distinct commits the refs of the
+// repository at source point to.
+func RepositoryHeads(source string) ([]string, error) {
+	out, err := exec.Command("git", "-C", filepath.Clean(source), "for-each-ref",
+		"--format=%(objectname)").Output()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var heads []string
+	for _, head := range strings.Fields(string(out)) {
+		if !seen[head] {
+			seen[head] = true
+			heads = append(heads, head)
+		}
+	}
+	sort.Strings(heads)
+	return heads, nil
+}
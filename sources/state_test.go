@@ -0,0 +1,62 @@
+package sources
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zricethezav/gitleaks/v8/sources/gittest"
+)
+
+func TestScanStateSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	state, err := LoadScanState(path)
+	require.NoError(t, err)
+	assert.Empty(t, state.Repositories)
+
+	lastScan := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	state.Repositories["https://example.com/a.git"] = RepositoryState{Heads: []string{"abc"}, LastScan: lastScan}
+	require.NoError(t, state.Save(path))
+
+	loaded, err := LoadScanState(path)
+	require.NoError(t, err)
+	assert.Equal(t, state, loaded)
+}
+
+func TestNewCommits(t *testing.T) {
+	remote := gittest.New(t)
+	remote.WriteFile("a.txt", "a")
+	first := remote.Commit("first")
+
+	cache := filepath.Join(t.TempDir(), RepositoryCacheName("file://"+remote.Dir))
+	require.NoError(t, FetchRepository("file://"+remote.Dir, cache, ""))
+	heads, err := RepositoryHeads(cache)
+	require.NoError(t, err)
+	assert.Equal(t, []string{first}, heads)
+	assert.Equal(t, "--all", NewCommitsLogOpts(cache, nil))
+
+	remote.WriteFile("b.txt", "b")
+	second := remote.Commit("second")
+	remote.Branch("feature")
+	remote.WriteFile("c.txt", "c")
+	third := remote.Commit("third")
+	require.NoError(t, FetchRepository("file://"+remote.Dir, cache, ""))
+
+	logOpts := NewCommitsLogOpts(cache, append(heads, "0000000000000000000000000000000000000000"))
+	assert.Equal(t, "--all --not "+first, logOpts)
+	gitCmd, err := NewGitLogCmd(cache, logOpts)
+	require.NoError(t, err)
+	var files []string
+	for f := range gitCmd.DiffFilesCh() {
+		files = append(files, f.NewName)
+	}
+	require.NoError(t, gitCmd.Wait())
+	assert.ElementsMatch(t, []string{"b.txt", "c.txt"}, files)
+
+	heads, err = RepositoryHeads(cache)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{second, third}, heads)
+}
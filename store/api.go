@@ -0,0 +1,138 @@
+package store
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// NewHandler serves the findings, scans and repositories of s as JSON:
+//
+//	GET /v1/findings?rule=&repo=&since=&until=&verified=&limit=
+//	GET /v1/scans?repo=&since=&until=&limit=
+//	GET /v1/repos
+//
+// since and until are RFC 3339 times or dates like 2024-05-06. Requests must
+// carry token as a bearer token, every request is refused when it's empty.
+// Findings are returned without their secret and matched lines.
+func NewHandler(s Store, token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/findings", func(w http.ResponseWriter, r *http.Request) {
+		filter, ok := parseFilter(w, r)
+		if !ok {
+			return
+		}
+		findings, err := s.Findings(filter)
+		for i := range findings {
+			findings[i].Secret = ""
+			findings[i].Match = ""
+			findings[i].Line = ""
+			findings[i].ContextBefore = nil
+			findings[i].ContextAfter = nil
+		}
+		writeResult(w, findings, err)
+	})
+	mux.HandleFunc("/v1/scans", func(w http.ResponseWriter, r *http.Request) {
+		filter, ok := parseFilter(w, r)
+		if !ok {
+			return
+		}
+		scans, err := s.Scans(filter)
+		writeResult(w, scans, err)
+	})
+	mux.HandleFunc("/v1/repos", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		repos, err := s.Repositories()
+		writeResult(w, repos, err)
+	})
+	return requireToken(mux, token)
+}
+
+// requireToken only passes requests authorized with the bearer token on to
+// next.
+func requireToken(next http.Handler, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		given := strings.TrimPrefix(auth, "Bearer ")
+		if given == auth || token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// parseFilter reads the filter from the query parameters of r, writing an
+// error response if they are invalid.
+func parseFilter(w http.ResponseWriter, r *http.Request) (Filter, bool) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return Filter{}, false
+	}
+	query := r.URL.Query()
+	filter := Filter{
+		RuleID:     query.Get("rule"),
+		Repository: query.Get("repo"),
+	}
+	var err error
+	if filter.Since, err = parseQueryTime(query.Get("since")); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid since: %s", err)})
+		return Filter{}, false
+	}
+	if filter.Until, err = parseQueryTime(query.Get("until")); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid until: %s", err)})
+		return Filter{}, false
+	}
+	if v := query.Get("verified"); v != "" {
+		verified, err := strconv.ParseBool(v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid verified, must be true or false"})
+			return Filter{}, false
+		}
+		filter.Verified = &verified
+	}
+	if v := query.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid limit, must be a positive number"})
+			return Filter{}, false
+		}
+	}
+	return filter, true
+}
+
+func parseQueryTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+func writeResult(w http.ResponseWriter, v interface{}, err error) {
+	if err != nil {
+		log.Error().Err(err).Msg("could not query store")
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "could not query store"})
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error().Err(err).Msg("could not write response")
+	}
+}
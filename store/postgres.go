@@ -0,0 +1,153 @@
+package store
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// postgresMigrations create the PostgreSQL schema, one entry per schema
+// version. Times are kept as text in the same layout as SQLite so the queries
+// are shared.
+var postgresMigrations = []string{
+	`CREATE TABLE scans (
+	id BIGSERIAL PRIMARY KEY,
+	repository TEXT NOT NULL,
+	start_time TEXT NOT NULL,
+	end_time TEXT NOT NULL,
+	config_hash TEXT NOT NULL,
+	findings INTEGER NOT NULL
+);
+CREATE INDEX scans_repository ON scans (repository, start_time);
+CREATE TABLE repositories (
+	name TEXT PRIMARY KEY,
+	remote_url TEXT NOT NULL,
+	heads TEXT NOT NULL,
+	last_scan TEXT NOT NULL
+);
+CREATE TABLE findings (
+	repository TEXT NOT NULL,
+	fingerprint TEXT NOT NULL,
+	rule_id TEXT NOT NULL,
+	file TEXT NOT NULL,
+	commit_sha TEXT NOT NULL,
+	verified INTEGER,
+	first_seen TEXT NOT NULL,
+	last_seen TEXT NOT NULL,
+	first_scan BIGINT NOT NULL,
+	last_scan BIGINT NOT NULL,
+	finding TEXT NOT NULL,
+	PRIMARY KEY (repository, fingerprint)
+);
+CREATE INDEX findings_first_seen ON findings (first_seen);
+CREATE INDEX findings_rule_id ON findings (rule_id);`,
+}
+
+// postgres runs scripts with the psql CLI, like sqlite does with sqlite3.
+type postgres struct {
+	// dsn is the connection URL without its password, which is passed to
+	// psql in PGPASSWORD so it doesn't show up in the process list.
+	dsn      string
+	password string
+}
+
+// OpenPostgres opens the PostgreSQL database at the postgres:// URL dsn and
+// creates or migrates its schema. It requires the psql CLI. Several scanners
+// can share one database.
+func OpenPostgres(dsn string) (Store, error) {
+	if _, err := exec.LookPath("psql"); err != nil {
+		return nil, fmt.Errorf("the psql CLI is required to use a PostgreSQL store: %w", err)
+	}
+	db, err := newPostgres(dsn)
+	if err != nil {
+		return nil, err
+	}
+	s := &sqlStore{db: db}
+	if err := s.migrate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func newPostgres(dsn string) (*postgres, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PostgreSQL URL: %w", err)
+	}
+	db := &postgres{}
+	if u.User != nil {
+		if password, ok := u.User.Password(); ok {
+			db.password = password
+			u.User = url.User(u.User.Username())
+		}
+	}
+	db.dsn = u.String()
+	return db, nil
+}
+
+func (db *postgres) exec(script string) error {
+	_, err := db.run(script)
+	return err
+}
+
+// query decodes the rows of the last statement of script, which is on its own
+// line, by having PostgreSQL aggregate them into a JSON array.
+func (db *postgres) query(script string, v interface{}) error {
+	out, err := db.run(postgresJSONScript(script))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bytes.TrimSpace(out), v)
+}
+
+// postgresJSONScript rewrites the last statement of script to return its rows
+// as a single JSON array. The statement goes in a WITH clause so it can also
+// be an INSERT ... RETURNING.
+func postgresJSONScript(script string) string {
+	script = strings.TrimRight(strings.TrimSpace(script), ";")
+	var statements, last string
+	if i := strings.LastIndex(script, "\n"); i != -1 {
+		statements, last = script[:i+1], script[i+1:]
+	} else {
+		last = script
+	}
+	return statements + "WITH result AS (" + last + ") SELECT coalesce(json_agg(result), '[]'::json) FROM result;"
+}
+
+// run executes script in a transaction and returns what psql printed.
+func (db *postgres) run(script string) ([]byte, error) {
+	cmd := exec.Command("psql", "--no-psqlrc", "--quiet", "--tuples-only", "--no-align",
+		"--single-transaction", "--set", "ON_ERROR_STOP=1", "--file", "-", "--dbname", db.dsn)
+	cmd.Stdin = strings.NewReader(script)
+	cmd.Env = os.Environ()
+	if db.password != "" {
+		cmd.Env = append(cmd.Env, "PGPASSWORD="+db.password)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	log.Trace().Msgf("executing psql script on %s", db.dsn)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("psql %s: %w: %s", db.dsn, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// quote encodes s as hex so no content can break out of the literal. Text in
+// PostgreSQL can't hold NUL bytes or invalid UTF-8, so those are dropped and
+// replaced.
+func (db *postgres) quote(s string) string {
+	s = strings.ToValidUTF8(strings.ReplaceAll(s, "\x00", ""), "\uFFFD")
+	return "convert_from(decode('" + hex.EncodeToString([]byte(s)) + "', 'hex'), 'UTF8')"
+}
+
+func (db *postgres) migrations() []string {
+	return postgresMigrations
+}
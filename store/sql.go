@@ -0,0 +1,296 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+// database runs SQL scripts against a database. The SQL used by sqlStore is
+// shared by every database, anything dialect specific goes through here.
+type database interface {
+	// exec runs the statements in script in a single transaction.
+	exec(script string) error
+
+	// query runs the statements in script in a single transaction and
+	// decodes the rows returned by the last one into v, a pointer to a
+	// slice of structs with json tags matching the column names.
+	query(script string, v interface{}) error
+
+	// quote returns s as a string literal.
+	quote(s string) string
+
+	// migrations are the statements that create the schema, one entry per
+	// schema version.
+	migrations() []string
+}
+
+// sqlStore implements Store on top of a database.
+type sqlStore struct {
+	db database
+}
+
+// fingerprintBatch limits the number of findings upserted by a single script.
+const fingerprintBatch = 500
+
+// migrate brings the schema up to the latest version. Every migration is
+// applied in its own transaction along with the bump of the version.
+func (s *sqlStore) migrate() error {
+	if err := s.db.exec("CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL);"); err != nil {
+		return err
+	}
+	var rows []struct {
+		Version int `json:"version"`
+	}
+	if err := s.db.query("SELECT coalesce(max(version), 0) AS version FROM schema_version;", &rows); err != nil {
+		return err
+	}
+	current := 0
+	if len(rows) > 0 {
+		current = rows[0].Version
+	}
+	migrations := s.db.migrations()
+	if current > len(migrations) {
+		return fmt.Errorf("store schema version %d is newer than this version of gitleaks supports (%d)", current, len(migrations))
+	}
+	for version := current + 1; version <= len(migrations); version++ {
+		script := migrations[version-1] + fmt.Sprintf("\nINSERT INTO schema_version (version) VALUES (%d);", version)
+		if err := s.db.exec(script); err != nil {
+			return fmt.Errorf("unable to migrate store to schema version %d: %w", version, err)
+		}
+	}
+	return nil
+}
+
+func (s *sqlStore) SaveScan(scan *Scan, findings []report.Finding) ([]report.Finding, error) {
+	var ids []struct {
+		ID int64 `json:"id"`
+	}
+	insertScan := fmt.Sprintf("INSERT INTO scans (repository, start_time, end_time, config_hash, findings) VALUES (%s, %s, %s, %s, %d) RETURNING id;",
+		s.db.quote(scan.Repository), s.db.quote(formatTime(scan.StartTime)), s.db.quote(formatTime(scan.EndTime)),
+		s.db.quote(scan.ConfigHash), len(findings))
+	if err := s.db.query(insertScan, &ids); err != nil {
+		return nil, err
+	}
+	if len(ids) != 1 {
+		return nil, fmt.Errorf("unable to record scan of %s", scan.Repository)
+	}
+	scan.ID = ids[0].ID
+	scan.Findings = len(findings)
+
+	// a finding is new if this scan is the first one to have seen it, which
+	// is decided by the database so concurrent scanners agree
+	seen := formatTime(scan.EndTime)
+	newFingerprints := make(map[string]bool)
+	for start := 0; start < len(findings); start += fingerprintBatch {
+		end := start + fingerprintBatch
+		if end > len(findings) {
+			end = len(findings)
+		}
+		var sb strings.Builder
+		for _, f := range findings[start:end] {
+			data, err := json.Marshal(withoutSecret(f))
+			if err != nil {
+				return nil, err
+			}
+			verified := "NULL"
+			if f.Verified != nil {
+				verified = "0"
+				if *f.Verified {
+					verified = "1"
+				}
+			}
+			fmt.Fprintf(&sb, "INSERT INTO findings (repository, fingerprint, rule_id, file, commit_sha, verified, first_seen, last_seen, first_scan, last_scan, finding) "+
+				"VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %d, %d, %s) "+
+				"ON CONFLICT (repository, fingerprint) DO UPDATE SET last_seen = excluded.last_seen, last_scan = excluded.last_scan, verified = excluded.verified, finding = excluded.finding;\n",
+				s.db.quote(scan.Repository), s.db.quote(f.Fingerprint), s.db.quote(f.RuleID), s.db.quote(f.File),
+				s.db.quote(f.Commit), verified, s.db.quote(seen), s.db.quote(seen), scan.ID, scan.ID, s.db.quote(string(data)))
+		}
+		fmt.Fprintf(&sb, "SELECT fingerprint FROM findings WHERE repository = %s AND first_scan = %d;", s.db.quote(scan.Repository), scan.ID)
+		var rows []struct {
+			Fingerprint string `json:"fingerprint"`
+		}
+		if err := s.db.query(sb.String(), &rows); err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			newFingerprints[row.Fingerprint] = true
+		}
+	}
+
+	var newFindings []report.Finding
+	for _, f := range findings {
+		if newFingerprints[f.Fingerprint] {
+			newFindings = append(newFindings, f)
+		}
+	}
+	return newFindings, nil
+}
+
+// withoutSecret returns f without its secret and the lines holding it, so the
+// store doesn't become a collection of every credential it has seen. Secrets
+// replaced by their HMAC with Pseudonymize are kept to correlate findings.
+func withoutSecret(f report.Finding) report.Finding {
+	if f.SecretHash != "" && f.Secret == "hmac:"+f.SecretHash {
+		return f
+	}
+	f.Secret = ""
+	f.Match = ""
+	f.Line = ""
+	f.ContextBefore = nil
+	f.ContextAfter = nil
+	return f
+}
+
+func (s *sqlStore) Findings(filter Filter) ([]Finding, error) {
+	var where []string
+	if filter.RuleID != "" {
+		where = append(where, "rule_id = "+s.db.quote(filter.RuleID))
+	}
+	if filter.Repository != "" {
+		where = append(where, "repository = "+s.db.quote(filter.Repository))
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, "first_seen >= "+s.db.quote(formatTime(filter.Since)))
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, "first_seen < "+s.db.quote(formatTime(filter.Until)))
+	}
+	if filter.Verified != nil {
+		if *filter.Verified {
+			where = append(where, "verified = 1")
+		} else {
+			where = append(where, "(verified IS NULL OR verified = 0)")
+		}
+	}
+	query := "SELECT first_seen, last_seen, first_scan, last_scan, finding FROM findings" +
+		whereClause(where) + " ORDER BY first_seen DESC, fingerprint" + limitClause(filter.Limit) + ";"
+
+	var rows []struct {
+		FirstSeen string `json:"first_seen"`
+		LastSeen  string `json:"last_seen"`
+		FirstScan int64  `json:"first_scan"`
+		LastScan  int64  `json:"last_scan"`
+		Finding   string `json:"finding"`
+	}
+	if err := s.db.query(query, &rows); err != nil {
+		return nil, err
+	}
+	findings := make([]Finding, 0, len(rows))
+	for _, row := range rows {
+		f := Finding{
+			FirstSeen: parseTime(row.FirstSeen),
+			LastSeen:  parseTime(row.LastSeen),
+			FirstScan: row.FirstScan,
+			LastScan:  row.LastScan,
+		}
+		if err := json.Unmarshal([]byte(row.Finding), &f.Finding); err != nil {
+			return nil, err
+		}
+		findings = append(findings, f)
+	}
+	return findings, nil
+}
+
+func (s *sqlStore) Scans(filter Filter) ([]Scan, error) {
+	var where []string
+	if filter.Repository != "" {
+		where = append(where, "repository = "+s.db.quote(filter.Repository))
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, "start_time >= "+s.db.quote(formatTime(filter.Since)))
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, "start_time < "+s.db.quote(formatTime(filter.Until)))
+	}
+	query := "SELECT id, repository, start_time, end_time, config_hash, findings FROM scans" +
+		whereClause(where) + " ORDER BY start_time DESC, id DESC" + limitClause(filter.Limit) + ";"
+
+	var rows []struct {
+		ID         int64  `json:"id"`
+		Repository string `json:"repository"`
+		StartTime  string `json:"start_time"`
+		EndTime    string `json:"end_time"`
+		ConfigHash string `json:"config_hash"`
+		Findings   int    `json:"findings"`
+	}
+	if err := s.db.query(query, &rows); err != nil {
+		return nil, err
+	}
+	scans := make([]Scan, 0, len(rows))
+	for _, row := range rows {
+		scans = append(scans, Scan{
+			ID:         row.ID,
+			Repository: row.Repository,
+			StartTime:  parseTime(row.StartTime),
+			EndTime:    parseTime(row.EndTime),
+			ConfigHash: row.ConfigHash,
+			Findings:   row.Findings,
+		})
+	}
+	return scans, nil
+}
+
+func (s *sqlStore) Repositories() ([]Repository, error) {
+	return s.repositories("")
+}
+
+func (s *sqlStore) Repository(name string) (Repository, bool, error) {
+	repos, err := s.repositories(name)
+	if err != nil || len(repos) == 0 {
+		return Repository{Name: name}, false, err
+	}
+	return repos[0], true, nil
+}
+
+// repositories returns the repository called name, or all of them if name is
+// empty.
+func (s *sqlStore) repositories(name string) ([]Repository, error) {
+	query := "SELECT name, remote_url, heads, last_scan FROM repositories"
+	if name != "" {
+		query += " WHERE name = " + s.db.quote(name)
+	}
+	query += " ORDER BY name;"
+
+	var rows []struct {
+		Name      string `json:"name"`
+		RemoteURL string `json:"remote_url"`
+		Heads     string `json:"heads"`
+		LastScan  string `json:"last_scan"`
+	}
+	if err := s.db.query(query, &rows); err != nil {
+		return nil, err
+	}
+	repos := make([]Repository, 0, len(rows))
+	for _, row := range rows {
+		repos = append(repos, Repository{
+			Name:      row.Name,
+			RemoteURL: row.RemoteURL,
+			Heads:     strings.Fields(row.Heads),
+			LastScan:  parseTime(row.LastScan),
+		})
+	}
+	return repos, nil
+}
+
+func (s *sqlStore) SaveRepository(repo Repository) error {
+	return s.db.exec(fmt.Sprintf("INSERT INTO repositories (name, remote_url, heads, last_scan) VALUES (%s, %s, %s, %s) "+
+		"ON CONFLICT (name) DO UPDATE SET remote_url = excluded.remote_url, heads = excluded.heads, last_scan = excluded.last_scan;",
+		s.db.quote(repo.Name), s.db.quote(repo.RemoteURL), s.db.quote(strings.Join(repo.Heads, " ")), s.db.quote(formatTime(repo.LastScan))))
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+func limitClause(limit int) string {
+	if limit <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" LIMIT %d", limit)
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// sqliteBusyTimeout is how long, in milliseconds, to wait for another
+// process writing to the database.
+const sqliteBusyTimeout = 10000
+
+// sqliteMigrations create the SQLite schema, one entry per schema version.
+var sqliteMigrations = []string{
+	`CREATE TABLE scans (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	repository TEXT NOT NULL,
+	start_time TEXT NOT NULL,
+	end_time TEXT NOT NULL,
+	config_hash TEXT NOT NULL,
+	findings INTEGER NOT NULL
+);
+CREATE INDEX scans_repository ON scans (repository, start_time);
+CREATE TABLE repositories (
+	name TEXT PRIMARY KEY,
+	remote_url TEXT NOT NULL,
+	heads TEXT NOT NULL,
+	last_scan TEXT NOT NULL
+);
+CREATE TABLE findings (
+	repository TEXT NOT NULL,
+	fingerprint TEXT NOT NULL,
+	rule_id TEXT NOT NULL,
+	file TEXT NOT NULL,
+	commit_sha TEXT NOT NULL,
+	verified INTEGER,
+	first_seen TEXT NOT NULL,
+	last_seen TEXT NOT NULL,
+	first_scan INTEGER NOT NULL,
+	last_scan INTEGER NOT NULL,
+	finding TEXT NOT NULL,
+	PRIMARY KEY (repository, fingerprint)
+);
+CREATE INDEX findings_first_seen ON findings (first_seen);
+CREATE INDEX findings_rule_id ON findings (rule_id);`,
+}
+
+// sqlite runs scripts with the sqlite3 CLI, so no cgo or driver is needed to
+// build gitleaks.
+type sqlite struct {
+	path string
+}
+
+// OpenSQLite opens, and creates if needed, the SQLite database at path.
+// It requires the sqlite3 CLI, version 3.35 or later.
+func OpenSQLite(path string) (Store, error) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		return nil, fmt.Errorf("the sqlite3 CLI is required to use a SQLite store: %w", err)
+	}
+	s := &sqlStore{db: &sqlite{path: path}}
+	if err := s.migrate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (db *sqlite) exec(script string) error {
+	_, err := db.run(script)
+	return err
+}
+
+func (db *sqlite) query(script string, v interface{}) error {
+	out, err := db.run(script)
+	if err != nil {
+		return err
+	}
+	// sqlite3 prints nothing at all when there are no rows
+	if len(bytes.TrimSpace(out)) == 0 {
+		out = []byte("[]")
+	}
+	return json.Unmarshal(out, v)
+}
+
+// run executes script in a transaction and returns the rows as JSON.
+func (db *sqlite) run(script string) ([]byte, error) {
+	cmd := exec.Command("sqlite3", "-bail", "-json", db.path)
+	cmd.Stdin = strings.NewReader(fmt.Sprintf(".timeout %d\nBEGIN IMMEDIATE;\n%s\nCOMMIT;\n", sqliteBusyTimeout, script))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	log.Trace().Msgf("executing sqlite3 script on %s", db.path)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("sqlite3 %s: %w: %s", db.path, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// quote encodes s as hex so no content, including quotes and NUL bytes, can
+// break out of the literal.
+func (db *sqlite) quote(s string) string {
+	return "CAST(X'" + hex.EncodeToString([]byte(s)) + "' AS TEXT)"
+}
+
+func (db *sqlite) migrations() []string {
+	return sqliteMigrations
+}
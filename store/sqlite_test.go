@@ -0,0 +1,52 @@
+package store
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestSQLite(t *testing.T) Store {
+	t.Helper()
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 is not installed")
+	}
+	s, err := Open("sqlite:" + filepath.Join(t.TempDir(), "gitleaks.db"))
+	require.NoError(t, err)
+	return s
+}
+
+func TestSQLiteMigrate(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 is not installed")
+	}
+	path := filepath.Join(t.TempDir(), "gitleaks.db")
+	_, err := OpenSQLite(path)
+	require.NoError(t, err)
+	// opening an up to date database again doesn't migrate anything
+	_, err = OpenSQLite(path)
+	require.NoError(t, err)
+
+	db := &sqlite{path: path}
+	var rows []struct {
+		Version int `json:"version"`
+	}
+	require.NoError(t, db.query("SELECT version FROM schema_version;", &rows))
+	require.Len(t, rows, len(sqliteMigrations))
+
+	require.NoError(t, db.exec("INSERT INTO schema_version (version) VALUES (99);"))
+	_, err = OpenSQLite(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "schema version 99")
+}
+
+func TestSQLiteFindings(t *testing.T) {
+	testFindings(t, openTestSQLite(t))
+}
+
+func TestSQLiteRepositories(t *testing.T) {
+	testRepositories(t, openTestSQLite(t))
+}
@@ -0,0 +1,112 @@
+// Package store persists scans, findings and repository metadata so results
+// can be queried across scans, e.g. the secrets first seen this week.
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+// Store is a findings database. Findings are identified by their repository
+// and fingerprint, so a secret found again in a later scan updates the
+// existing finding instead of adding a new one.
+type Store interface {
+	// SaveScan records a scan of a repository and its findings, setting the
+	// ID of scan. It returns the findings that had never been seen in the
+	// repository before.
+	SaveScan(scan *Scan, findings []report.Finding) ([]report.Finding, error)
+
+	// Findings returns the findings matching the filter, most recently
+	// first seen first.
+	Findings(filter Filter) ([]Finding, error)
+
+	// Scans returns the scans matching the filter, most recent first.
+	Scans(filter Filter) ([]Scan, error)
+
+	// Repositories returns every repository that has been scanned.
+	Repositories() ([]Repository, error)
+
+	// Repository returns the repository called name and whether it has been
+	// scanned before.
+	Repository(name string) (Repository, bool, error)
+
+	// SaveRepository records the state of a repository after a scan.
+	SaveRepository(repo Repository) error
+}
+
+// Scan is a single scan of a repository.
+type Scan struct {
+	ID         int64
+	Repository string
+	StartTime  time.Time
+	EndTime    time.Time
+	ConfigHash string
+	Findings   int
+}
+
+// Finding is a finding along with when it was first and last found.
+type Finding struct {
+	report.Finding
+	FirstSeen time.Time
+	LastSeen  time.Time
+	FirstScan int64
+	LastScan  int64
+}
+
+// Repository is what is known about a scanned repository.
+type Repository struct {
+	Name      string
+	RemoteURL string
+	// Heads are the commits the refs of the repository pointed to when it
+	// was last scanned.
+	Heads    []string
+	LastScan time.Time
+}
+
+// Filter narrows down the findings and scans that are returned. Zero values
+// don't filter.
+type Filter struct {
+	RuleID     string
+	Repository string
+	// Since and Until select findings first seen, or scans started, in
+	// the time range [Since, Until).
+	Since time.Time
+	Until time.Time
+	// Verified selects findings by their verification status.
+	Verified *bool
+	Limit    int
+}
+
+// Open opens the store at dsn. `sqlite:path` and paths are SQLite databases,
+// `postgres://` URLs are PostgreSQL databases. The database schema is created
+// or migrated to the latest version.
+func Open(dsn string) (Store, error) {
+	switch {
+	case strings.HasPrefix(dsn, "postgres://"), strings.HasPrefix(dsn, "postgresql://"):
+		return OpenPostgres(dsn)
+	case strings.HasPrefix(dsn, "sqlite://"):
+		return OpenSQLite(strings.TrimPrefix(dsn, "sqlite://"))
+	case strings.HasPrefix(dsn, "sqlite:"):
+		return OpenSQLite(strings.TrimPrefix(dsn, "sqlite:"))
+	case strings.Contains(dsn, "://"):
+		return nil, fmt.Errorf("unsupported store %s", dsn)
+	default:
+		return OpenSQLite(dsn)
+	}
+}
+
+// timeLayout is how times are stored. UTC timestamps in this layout sort
+// the same as strings and as times.
+const timeLayout = "2006-01-02T15:04:05Z"
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+func parseTime(s string) time.Time {
+	t, _ := time.Parse(timeLayout, s)
+	return t
+}
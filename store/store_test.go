@@ -0,0 +1,126 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zricethezav/gitleaks/v8/report"
+)
+
+// testFindings checks that findings are deduplicated and filtered the same
+// way by every backend.
+func testFindings(t *testing.T, s Store) {
+	verified := true
+	monday := time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC)
+	friday := time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+
+	first := []report.Finding{
+		{RuleID: "aws-access-key", File: "main.go", Fingerprint: "a:main.go:aws-access-key:1", Secret: "it's a \x00 secret", Match: "key = it's a \x00 secret", ContextBefore: []string{"# it's a \x00 secret"}},
+		{RuleID: "github-pat", File: "ci.yml", Fingerprint: "a:ci.yml:github-pat:3"},
+	}
+	scan := &Scan{Repository: "api", StartTime: monday, EndTime: monday, ConfigHash: "abc"}
+	newFindings, err := s.SaveScan(scan, first)
+	require.NoError(t, err)
+	assert.Equal(t, first, newFindings)
+	assert.NotZero(t, scan.ID)
+
+	second := []report.Finding{
+		{RuleID: "aws-access-key", File: "main.go", Fingerprint: "a:main.go:aws-access-key:1", Secret: "it's a \x00 secret", Verified: &verified},
+		{RuleID: "aws-access-key", File: "deploy.sh", Fingerprint: "b:deploy.sh:aws-access-key:7"},
+	}
+	rescan := &Scan{Repository: "api", StartTime: friday, EndTime: friday, ConfigHash: "abc"}
+	newFindings, err = s.SaveScan(rescan, second)
+	require.NoError(t, err)
+	assert.Equal(t, second[1:], newFindings)
+
+	// the same fingerprint in another repository is a different finding
+	newFindings, err = s.SaveScan(&Scan{Repository: "web", StartTime: friday, EndTime: friday}, second[:1])
+	require.NoError(t, err)
+	assert.Len(t, newFindings, 1)
+
+	all, err := s.Findings(Filter{Repository: "api"})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, "b:deploy.sh:aws-access-key:7", all[0].Fingerprint)
+	for _, f := range all {
+		// the store must not collect the secrets it has seen
+		assert.Empty(t, f.Secret)
+		assert.Empty(t, f.Match)
+		assert.Empty(t, f.ContextBefore)
+		if f.Fingerprint == "a:main.go:aws-access-key:1" {
+			assert.Equal(t, monday, f.FirstSeen)
+			assert.Equal(t, friday, f.LastSeen)
+			assert.Equal(t, scan.ID, f.FirstScan)
+			assert.Equal(t, rescan.ID, f.LastScan)
+			require.NotNil(t, f.Verified)
+		}
+	}
+
+	thisWeek, err := s.Findings(Filter{Repository: "api", Since: friday.Add(-24 * time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, thisWeek, 1)
+	assert.Equal(t, "deploy.sh", thisWeek[0].File)
+
+	byRule, err := s.Findings(Filter{RuleID: "aws-access-key", Verified: &verified})
+	require.NoError(t, err)
+	assert.Len(t, byRule, 2)
+
+	limited, err := s.Findings(Filter{Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, limited, 1)
+
+	scans, err := s.Scans(Filter{Repository: "api"})
+	require.NoError(t, err)
+	require.Len(t, scans, 2)
+	assert.Equal(t, rescan.ID, scans[0].ID)
+	assert.Equal(t, 2, scans[0].Findings)
+}
+
+func testRepositories(t *testing.T, s Store) {
+	_, ok, err := s.Repository("api")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	lastScan := time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, s.SaveRepository(Repository{Name: "api", RemoteURL: "https://example.com/api.git", Heads: []string{"a", "b"}, LastScan: lastScan}))
+	require.NoError(t, s.SaveRepository(Repository{Name: "api", RemoteURL: "https://example.com/api.git", Heads: []string{"c"}, LastScan: lastScan}))
+	require.NoError(t, s.SaveRepository(Repository{Name: "web", LastScan: lastScan}))
+
+	repo, ok, err := s.Repository("api")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"c"}, repo.Heads)
+
+	repos, err := s.Repositories()
+	require.NoError(t, err)
+	require.Len(t, repos, 2)
+	assert.Equal(t, "web", repos[1].Name)
+	assert.Empty(t, repos[1].Heads)
+}
+
+func TestWithoutSecret(t *testing.T) {
+	f := report.Finding{
+		RuleID:        "generic-api-key",
+		Secret:        "hunter2",
+		Match:         "password = hunter2",
+		Line:          "  password = hunter2",
+		ContextBefore: []string{"# hunter2"},
+		ContextAfter:  []string{"# hunter2"},
+		Fingerprint:   "a:config.py:generic-api-key:2",
+	}
+	stored := withoutSecret(f)
+	assert.Empty(t, stored.Secret)
+	assert.Empty(t, stored.Match)
+	assert.Empty(t, stored.Line)
+	assert.Empty(t, stored.ContextBefore)
+	assert.Empty(t, stored.ContextAfter)
+	assert.Equal(t, f.Fingerprint, stored.Fingerprint)
+	assert.Equal(t, "hunter2", f.Secret)
+
+	// pseudonyms can't be reversed without the key
+	f.Pseudonymize([]byte("k3y"))
+	assert.Equal(t, f, withoutSecret(f))
+}